@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages the script cache",
+	Long: `Manages the cache of the files runtimes need to run inline scripts,
+such as script files for go, dotnet and pwsh.
+
+The cache lives in the 'run' directory of $XDG_CACHE_HOME, or of the
+cache directory of the platform, and is only readable by the current
+user. Entries that were not used for 30 days are removed, as are the
+least recently used entries once the cache grows beyond 512 MiB.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "Lists the entries of the script cache",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := cache.Default()
+		entries, err := c.List()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(entries) == 0 {
+			fmt.Fprintf(out, "%s is empty\n", c.Dir)
+			return nil
+		}
+
+		var total int64
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, entry := range entries {
+			total += entry.Size
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Name, formatSize(entry.Size), formatAge(time.Since(entry.ModTime)))
+		}
+		w.Flush()
+
+		noun := "entries"
+		if len(entries) == 1 {
+			noun = "entry"
+		}
+
+		fmt.Fprintf(out, "\n%d %s, %s in %s\n", len(entries), noun, formatSize(total), c.Dir)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Removes every entry of the script cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := cache.Default()
+		if err := c.Clear(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "cleared %s\n", c.Dir)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheLsCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
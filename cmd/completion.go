@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/output"
+	"github.com/hyprxlabs/run/internal/runner"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// The completion functions only parse the nearest runfile. They never
+// load dotenv files, expand variables or run anything.
+
+// completeTasks suggests the task names of the runfile, with and
+// without the runfile name as prefix, for the first argument. The
+// remaining arguments belong to the task and complete as files.
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return completeTask(cmd, args, toComplete)
+}
+
+// completeTask suggests a single task name.
+func completeTask(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	rf, err := loadRunfile()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return taskCompletions(rf, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func taskCompletions(rf *schema.Runfile, toComplete string) []cobra.Completion {
+	completions := []cobra.Completion{}
+	add := func(name string, task schema.Task) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(name, value(task.Desc)))
+		}
+	}
+
+	for _, task := range tasks(rf) {
+		add(task.Id, task)
+	}
+
+	if rf.Name != "" {
+		for _, task := range tasks(rf) {
+			add(rf.Name+":"+task.Id, task)
+		}
+	}
+
+	return completions
+}
+
+// completeInputs suggests 'key=' for the inputs declared by the task
+// given as first argument, or by every task when none is given yet,
+// and 'key=choice' once the key is typed and the input declares a
+// selection.
+func completeInputs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	rf, err := loadRunfile()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return inputCompletions(rf, args, toComplete)
+}
+
+func inputCompletions(rf *schema.Runfile, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	list := tasks(rf)
+	if len(args) > 0 {
+		list = []schema.Task{}
+		if task, err := runner.New(rf).Task(args[0]); err == nil {
+			list = append(list, *task)
+		}
+	}
+
+	completions := []cobra.Completion{}
+	seen := map[string]bool{}
+	key, prefix, hasValue := strings.Cut(toComplete, "=")
+	for _, task := range list {
+		for _, input := range task.Inputs {
+			if hasValue {
+				if !strings.EqualFold(input.Id, key) {
+					continue
+				}
+
+				for _, choice := range input.Selection {
+					candidate := key + "=" + choice
+					if strings.HasPrefix(choice, prefix) && !seen[candidate] {
+						seen[candidate] = true
+						completions = append(completions, candidate)
+					}
+				}
+				continue
+			}
+
+			candidate := input.Id + "="
+			if strings.HasPrefix(candidate, toComplete) && !seen[candidate] {
+				seen[candidate] = true
+				completions = append(completions, cobra.CompletionWithDesc(candidate, value(input.Desc)))
+			}
+		}
+	}
+
+	if hasValue {
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeHosts suggests the hosts and groups of the runfile.
+func completeHosts(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	rf, err := loadRunfile()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return hostCompletions(rf, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func hostCompletions(rf *schema.Runfile, toComplete string) []cobra.Completion {
+	names := []string{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name != "" && !seen[name] && strings.HasPrefix(name, toComplete) {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	groups := []string{}
+	for name, entry := range rf.Hosts.Iter() {
+		add(name)
+		groups = append(groups, entry.Groups...)
+	}
+
+	sort.Strings(groups)
+	for _, group := range groups {
+		add(group)
+	}
+
+	return names
+}
+
+// completeDotEnv suggests dotenv files, e.g. .env, .env.local or
+// prod.env, in the directory being completed.
+func completeDotEnv(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	dir, base := filepath.Split(toComplete)
+	search := dir
+	if search == "" {
+		search = "."
+	}
+
+	entries, err := os.ReadDir(search)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	completions := []cobra.Completion{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		if entry.IsDir() {
+			if !strings.HasPrefix(name, ".") || strings.HasPrefix(base, ".") {
+				completions = append(completions, dir+name+string(filepath.Separator))
+			}
+			continue
+		}
+
+		if isDotEnvFile(name) {
+			completions = append(completions, dir+name)
+		}
+	}
+
+	if len(completions) == 1 && strings.HasSuffix(completions[0], string(filepath.Separator)) {
+		return completions, cobra.ShellCompDirectiveNoSpace
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func isDotEnvFile(name string) bool {
+	return name == ".env" ||
+		strings.HasPrefix(name, ".env.") ||
+		strings.HasSuffix(name, ".env")
+}
+
+// registerCompletions hooks the completion functions into the
+// commands. It must run after the flags are defined.
+func registerCompletions() {
+	rootCmd.ValidArgsFunction = completeTasks
+	helpCmd.ValidArgsFunction = completeTask
+	statusCmd.ValidArgsFunction = completeTask
+
+	rootCmd.RegisterFlagCompletionFunc("input", completeInputs)
+	rootCmd.RegisterFlagCompletionFunc("host", completeHosts)
+	rootCmd.RegisterFlagCompletionFunc("dotenv", completeDotEnv)
+	rootCmd.RegisterFlagCompletionFunc("output-mode", cobra.FixedCompletions(output.Modes(), cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("file", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return []cobra.Completion{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+const completionRunfile = `
+name: demo
+tasks:
+  build:
+    desc: Builds the project
+    run: echo build
+  deploy:
+    desc: Deploys the project
+    run: echo deploy
+    inputs:
+      - id: env
+        desc: Where to deploy
+        selection: [dev, prod, preview]
+      - region
+  test:
+    run: echo test
+    inputs:
+      - id: env
+        selection: [dev]
+`
+
+// useRunfile writes the runfile to a temporary directory and points
+// --file at it for the duration of the test.
+func useRunfile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "runfile.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := runfilePath
+	runfilePath = path
+	t.Cleanup(func() { runfilePath = previous })
+	return path
+}
+
+func TestCompleteTasks(t *testing.T) {
+	useRunfile(t, completionRunfile)
+
+	tests := []struct {
+		name       string
+		args       []string
+		toComplete string
+		want       []cobra.Completion
+		directive  cobra.ShellCompDirective
+	}{
+		{
+			name: "all",
+			want: []cobra.Completion{
+				"build\tBuilds the project",
+				"deploy\tDeploys the project",
+				"test\t",
+				"demo:build\tBuilds the project",
+				"demo:deploy\tDeploys the project",
+				"demo:test\t",
+			},
+			directive: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "prefix",
+			toComplete: "de",
+			want:       []cobra.Completion{"deploy\tDeploys the project", "demo:build\tBuilds the project", "demo:deploy\tDeploys the project", "demo:test\t"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "project prefix",
+			toComplete: "demo:b",
+			want:       []cobra.Completion{"demo:build\tBuilds the project"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:      "task arguments complete as files",
+			args:      []string{"build"},
+			directive: cobra.ShellCompDirectiveDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := rootCmd.ValidArgsFunction(rootCmd, tt.args, tt.toComplete)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.directive, directive)
+		})
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	useRunfile(t, completionRunfile)
+
+	for _, cmd := range []*cobra.Command{helpCmd, statusCmd} {
+		got, directive := cmd.ValidArgsFunction(cmd, nil, "te")
+		assert.Equal(t, []cobra.Completion{"test\t"}, got)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+		got, _ = cmd.ValidArgsFunction(cmd, []string{"test"}, "")
+		assert.Empty(t, got)
+	}
+}
+
+func TestCompleteTasks_NoRunfile(t *testing.T) {
+	useRunfile(t, completionRunfile)
+	runfilePath = filepath.Join(t.TempDir(), "missing.yaml")
+
+	got, directive := rootCmd.ValidArgsFunction(rootCmd, nil, "")
+	assert.Empty(t, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestCompleteInputs(t *testing.T) {
+	useRunfile(t, completionRunfile)
+
+	complete, ok := rootCmd.GetFlagCompletionFunc("input")
+	assert.True(t, ok)
+
+	tests := []struct {
+		name       string
+		args       []string
+		toComplete string
+		want       []cobra.Completion
+		directive  cobra.ShellCompDirective
+	}{
+		{
+			name:      "keys of every task",
+			want:      []cobra.Completion{"env=\tWhere to deploy", "region=\t"},
+			directive: cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			name:      "keys of the task",
+			args:      []string{"demo:test"},
+			want:      []cobra.Completion{"env=\t"},
+			directive: cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			name:       "choices of the task",
+			args:       []string{"deploy"},
+			toComplete: "env=p",
+			want:       []cobra.Completion{"env=prod", "env=preview"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "choices of every task",
+			toComplete: "env=",
+			want:       []cobra.Completion{"env=dev", "env=prod", "env=preview"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:      "unknown task",
+			args:      []string{"missing"},
+			want:      []cobra.Completion{},
+			directive: cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := complete(rootCmd, tt.args, tt.toComplete)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.directive, directive)
+		})
+	}
+}
+
+func TestCompleteHosts(t *testing.T) {
+	useRunfile(t, `
+hosts:
+  web1.example.com: {groups: [web, prod]}
+  web2.example.com: {groups: [web]}
+  db.example.com: {groups: [prod]}
+tasks:
+  build:
+    run: echo build
+`)
+
+	complete, ok := rootCmd.GetFlagCompletionFunc("host")
+	assert.True(t, ok)
+
+	got, directive := complete(rootCmd, nil, "")
+	assert.ElementsMatch(t, []cobra.Completion{"web1.example.com", "web2.example.com", "db.example.com", "prod", "web"}, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, _ = complete(rootCmd, nil, "we")
+	assert.ElementsMatch(t, []cobra.Completion{"web1.example.com", "web2.example.com", "web"}, got)
+}
+
+func TestCompleteDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".env", ".env.local", "prod.env", "README.md", "config/.env", ".git/HEAD"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+	t.Chdir(dir)
+
+	complete, ok := rootCmd.GetFlagCompletionFunc("dotenv")
+	assert.True(t, ok)
+
+	sep := string(filepath.Separator)
+	tests := []struct {
+		name       string
+		toComplete string
+		want       []cobra.Completion
+		directive  cobra.ShellCompDirective
+	}{
+		{
+			name:      "dotenv files and visible directories",
+			want:      []cobra.Completion{".env", ".env.local", "config" + sep, "prod.env"},
+			directive: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "hidden directories by prefix",
+			toComplete: ".",
+			want:       []cobra.Completion{".env", ".env.local", ".git" + sep},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:       "a single directory",
+			toComplete: "co",
+			want:       []cobra.Completion{"config" + sep},
+			directive:  cobra.ShellCompDirectiveNoSpace,
+		},
+		{
+			name:       "inside a directory",
+			toComplete: "config" + sep,
+			want:       []cobra.Completion{"config" + sep + ".env"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := complete(rootCmd, nil, tt.toComplete)
+			assert.ElementsMatch(t, tt.want, got)
+			assert.Equal(t, tt.directive, directive)
+		})
+	}
+}
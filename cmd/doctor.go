@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyprxlabs/run/internal/doctor"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON    bool
+	doctorNoHosts bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks the executables, runfile and hosts run uses",
+	Long: `Checks the environment run works in and reports what it finds:
+
+  - the path and version of each executable runtimes use, such as
+    bash, pwsh, node, deno, bun, python, ruby, go and dotnet, and the
+    variable or search path it was found by, see 'run which'
+  - the problems 'run validate' reports for the runfile, such as
+    dotenv files that are missing
+  - tasks whose runtime is not found
+  - hosts of the runfile that ssh cannot reach
+  - bash resolving to the WSL launcher while the bash of git exists
+
+Use --json for output to attach to bug reports. Exits with an error
+when a task cannot run as it is.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rf, err := loadRunfile()
+		if err != nil && !errors.Is(err, errNoRunfile) {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		report := doctor.Check(ctx, &doctor.Options{Runfile: rf, Hosts: !doctorNoHosts})
+
+		out := cmd.OutOrStdout()
+		if doctorJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			writeReport(out, report)
+		}
+
+		if n := report.Errors(); n > 0 {
+			return fmt.Errorf("found %d problem(s)", n)
+		}
+
+		return nil
+	},
+}
+
+// writeReport prints the report section by section.
+func writeReport(out io.Writer, report *doctor.Report) {
+	fmt.Fprintf(out, "os: %s\n", describeOS(report.OS))
+	if report.Runfile != "" {
+		fmt.Fprintf(out, "runfile: %s\n", report.Runfile)
+	} else {
+		fmt.Fprintln(out, "runfile: none found")
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "executables:")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, exe := range report.Executables {
+		if exe.Path == "" {
+			fmt.Fprintf(w, "  %s\tnot found\t\t\n", exe.Name)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s %s\n", exe.Name, exe.Path, exe.Version, exe.Source, exe.Candidate)
+	}
+	w.Flush()
+
+	if len(report.Hosts) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "hosts:")
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, host := range report.Hosts {
+			status := "reachable"
+			if !host.Reachable {
+				status = "not reachable"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", host.Host, status)
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintln(out)
+	if len(report.Problems) == 0 {
+		fmt.Fprintln(out, "no problems found")
+		return
+	}
+
+	fmt.Fprintln(out, "problems:")
+	for _, problem := range report.Problems {
+		fmt.Fprintf(out, "  %s: %s\n", problem.Severity, problem.Message)
+	}
+}
+
+// describeOS returns the facts about the os on one line, e.g.
+// 'linux amd64 debian 12 bookworm'.
+func describeOS(o *schema.OS) string {
+	facts := []string{}
+	for _, fact := range []string{o.Platform, o.Arch, o.Variant, o.Version, o.Codename} {
+		if fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+
+	return strings.Join(facts, " ")
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "print the report as json")
+	doctorCmd.Flags().BoolVar(&doctorNoHosts, "no-hosts", false, "do not check that the hosts of the runfile are reachable")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// helpCmd shows the help of a command or, when no command matches,
+// the help of the task with that name.
+var helpCmd = &cobra.Command{
+	Use:   "help [command|task]",
+	Short: "Help about any command or task",
+	Long: `Shows the help of a command or of a task in the runfile.
+
+The help of a task is its 'help' field, or its 'desc' when it has no
+help, followed by the tasks it needs and its inputs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := cmd.Root()
+		if len(args) == 0 {
+			return root.Help()
+		}
+
+		c, _, err := root.Find(args)
+		if err == nil && c != root {
+			return c.Help()
+		}
+
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+
+		task, err := r.Task(args[0])
+		if err != nil {
+			return fmt.Errorf("unknown help topic '%s'", args[0])
+		}
+
+		writeTaskHelp(cmd.OutOrStdout(), task)
+		return nil
+	},
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/hyprxlabs/run/internal/osinfo"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	infoJSON  bool
+	infoHosts bool
+)
+
+type hostInfo struct {
+	Host  string     `json:"host"`
+	OS    *schema.OS `json:"os,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+type machineInfo struct {
+	OS    *schema.OS `json:"os"`
+	Hosts []hostInfo `json:"hosts,omitempty"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Shows the facts about the operating system",
+	Long: `Shows the facts run detects about the operating system: the
+platform, architecture, distribution, its family, codename and version,
+and the kernel version. Tasks see them as RUN_OS_PLATFORM, RUN_OS_ARCH,
+RUN_OS_VARIANT, RUN_OS_FAMILY, RUN_OS_CODENAME, RUN_OS_VERSION and
+RUN_OS_BUILD_VERSION.
+
+With --hosts the facts of the hosts of the runfile that do not declare
+their os are detected over ssh as well.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		local, err := osinfo.Local()
+		if err != nil {
+			return err
+		}
+
+		info := machineInfo{OS: local}
+		if infoHosts {
+			rf, err := loadRunfile()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			errs := osinfo.DetectHosts(ctx, &rf.Hosts)
+			for _, key := range rf.Hosts.Keys() {
+				host, _ := rf.Hosts.Get(key)
+				entry := hostInfo{Host: key, OS: host.OS}
+				if err, ok := errs[key]; ok {
+					entry.Error = err.Error()
+				}
+				info.Hosts = append(info.Hosts, entry)
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		if infoJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+
+		writeOS(out, "", local)
+		for _, host := range info.Hosts {
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "%s:\n", host.Host)
+			if host.Error != "" {
+				fmt.Fprintf(out, "  error: %s\n", host.Error)
+				continue
+			}
+			writeOS(out, "  ", host.OS)
+		}
+
+		return nil
+	},
+}
+
+// writeOS prints the facts that are known, one per line.
+func writeOS(out io.Writer, indent string, o *schema.OS) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, fact := range []struct{ name, value string }{
+		{"platform", o.Platform},
+		{"arch", o.Arch},
+		{"variant", o.Variant},
+		{"family", o.Family},
+		{"codename", o.Codename},
+		{"version", o.Version},
+		{"build version", o.BuildVersion},
+	} {
+		if fact.value != "" {
+			fmt.Fprintf(w, "%s%s:\t%s\n", indent, fact.name, fact.value)
+		}
+	}
+	w.Flush()
+}
+
+func init() {
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "print the facts as json")
+	infoCmd.Flags().BoolVar(&infoHosts, "hosts", false, "detect the facts of the hosts of the runfile over ssh")
+	rootCmd.AddCommand(infoCmd)
+}
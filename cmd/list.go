@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+type taskInfo struct {
+	Id        string      `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	Namespace string      `json:"namespace,omitempty"`
+	Desc      string      `json:"desc,omitempty"`
+	Help      string      `json:"help,omitempty"`
+	Uses      string      `json:"uses,omitempty"`
+	Needs     []string    `json:"needs"`
+	Hosts     []string    `json:"hosts"`
+	Inputs    []inputInfo `json:"inputs"`
+}
+
+type inputInfo struct {
+	Id        string   `json:"id"`
+	Desc      string   `json:"desc,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Default   string   `json:"default,omitempty"`
+	Required  bool     `json:"required"`
+	Selection []string `json:"selection,omitempty"`
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// tasks returns the tasks of the runfile in the order they are
+// declared.
+func tasks(rf *schema.Runfile) []schema.Task {
+	list := []schema.Task{}
+	for _, id := range rf.Tasks.Keys() {
+		task, ok := rf.Tasks.Get(id)
+		if ok {
+			list = append(list, task)
+		}
+	}
+
+	return list
+}
+
+func writeTasksJSON(out io.Writer, rf *schema.Runfile) error {
+	infos := []taskInfo{}
+	for _, task := range tasks(rf) {
+		info := taskInfo{
+			Id:        task.Id,
+			Name:      value(task.Name),
+			Namespace: task.Namespace(),
+			Desc:      value(task.Desc),
+			Help:      value(task.Help),
+			Uses:      value(task.Uses),
+			Needs:     orEmpty(task.Needs),
+			Hosts:     orEmpty(task.Hosts),
+			Inputs:    []inputInfo{},
+		}
+
+		for _, input := range task.Inputs {
+			def, _ := input.DefaultString()
+			info.Inputs = append(info.Inputs, inputInfo{
+				Id:        input.Id,
+				Desc:      value(input.Desc),
+				Type:      value(input.Type),
+				Default:   def,
+				Required:  input.IsRequired(),
+				Selection: input.Selection,
+			})
+		}
+
+		infos = append(infos, info)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(infos)
+}
+
+// writeTasks prints the tasks grouped by namespace. Tasks without a
+// namespace are listed first.
+func writeTasks(out io.Writer, rf *schema.Runfile) error {
+	groups := map[string][]schema.Task{}
+	namespaces := []string{}
+	for _, task := range tasks(rf) {
+		ns := task.Namespace()
+		if _, ok := groups[ns]; !ok && ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+		groups[ns] = append(groups[ns], task)
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "no tasks found in", rf.Path)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	write := func(indent string, list []schema.Task) {
+		for _, task := range list {
+			columns := []string{indent + task.Id, value(task.Desc)}
+			details := []string{}
+			if len(task.Needs) > 0 {
+				details = append(details, "needs: "+strings.Join(task.Needs, ", "))
+			}
+			if len(task.Hosts) > 0 {
+				details = append(details, "hosts: "+strings.Join(task.Hosts, ", "))
+			}
+			if len(details) > 0 {
+				columns = append(columns, strings.Join(details, "; "))
+			}
+			fmt.Fprintln(w, strings.Join(columns, "\t"))
+		}
+	}
+
+	fmt.Fprintln(w, "tasks:")
+	write("  ", groups[""])
+	for _, ns := range namespaces {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, ns+":")
+		write("  ", groups[ns])
+	}
+
+	return w.Flush()
+}
+
+// writeTaskHelp prints the help of the task, falling back to its
+// description, followed by its needs and inputs.
+func writeTaskHelp(out io.Writer, task *schema.Task) {
+	fmt.Fprintf(out, "run %s [args...]\n", task.Id)
+
+	text := value(task.Help)
+	if text == "" {
+		text = value(task.Desc)
+	}
+
+	if text != "" {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, strings.TrimRight(text, "\n"))
+	}
+
+	if len(task.Needs) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Needs:", strings.Join(task.Needs, ", "))
+	}
+
+	if len(task.Hosts) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Hosts:", strings.Join(task.Hosts, ", "))
+	}
+
+	if len(task.Inputs) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Inputs:")
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, input := range task.Inputs {
+			details := []string{}
+			if input.IsRequired() {
+				details = append(details, "required")
+			}
+			if def, ok := input.DefaultString(); ok {
+				details = append(details, "default: "+def)
+			}
+			if len(input.Selection) > 0 {
+				details = append(details, "one of: "+strings.Join(input.Selection, ", "))
+			}
+			fmt.Fprintf(w, "  --input %s=<value>\t%s\t%s\n", input.Id, value(input.Desc), strings.Join(details, "; "))
+		}
+		w.Flush()
+	}
+}
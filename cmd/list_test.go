@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const listRunfile = `
+name: demo
+hosts:
+  web1.example.com: {groups: [web]}
+tasks:
+  build:
+    desc: Builds the project
+    run: go build ./...
+  test:
+    desc: Runs the tests
+    needs: [build]
+    run: go test ./...
+  db:migrate:
+    desc: Applies the migrations
+    help: |
+      Applies the pending migrations to the database.
+
+      Use target to pick the database.
+    run: migrate up
+    inputs:
+      - id: target
+        desc: Database to migrate
+        required: true
+        selection: [dev, prod]
+      - id: steps
+        default: 1
+  db:seed:
+    run: seed
+  deploy:
+    desc: Deploys the project
+    needs: [build, test]
+    hosts: [web]
+    run: ./deploy.sh
+`
+
+// golden compares the output with testdata/<name>, or writes it
+// there with -update.
+func golden(t *testing.T, name string, out []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(t, os.MkdirAll("testdata", 0o755))
+		assert.NoError(t, os.WriteFile(path, out, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(out))
+}
+
+func readListRunfile(t *testing.T) *schema.Runfile {
+	t.Helper()
+
+	useRunfile(t, listRunfile)
+	rf, err := loadRunfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rf
+}
+
+func TestWriteTasks(t *testing.T) {
+	rf := readListRunfile(t)
+
+	var out bytes.Buffer
+	assert.NoError(t, writeTasks(&out, rf))
+	golden(t, "list.golden", out.Bytes())
+}
+
+func TestWriteTasks_Empty(t *testing.T) {
+	rf := &schema.Runfile{Path: "runfile.yaml"}
+
+	var out bytes.Buffer
+	assert.NoError(t, writeTasks(&out, rf))
+	assert.Equal(t, "no tasks found in runfile.yaml\n", out.String())
+}
+
+func TestWriteTasksJSON(t *testing.T) {
+	rf := readListRunfile(t)
+
+	var out bytes.Buffer
+	assert.NoError(t, writeTasksJSON(&out, rf))
+	golden(t, "list.json.golden", out.Bytes())
+}
+
+func TestWriteTaskHelp(t *testing.T) {
+	rf := readListRunfile(t)
+
+	for _, id := range []string{"db:migrate", "deploy", "db:seed"} {
+		t.Run(id, func(t *testing.T) {
+			task, ok := rf.Tasks.Get(id)
+			assert.True(t, ok)
+
+			var out bytes.Buffer
+			writeTaskHelp(&out, &task)
+			golden(t, "help-"+strings.ReplaceAll(id, ":", "-")+".golden", out.Bytes())
+		})
+	}
+}
@@ -5,26 +5,112 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"syscall"
 
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/output"
+	"github.com/hyprxlabs/run/internal/picker"
+	"github.com/hyprxlabs/run/internal/runner"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/hyprxlabs/run/internal/version"
 	"github.com/spf13/cobra"
 )
 
-
+var (
+	runfilePath string
+	force       bool
+	watchFlag   bool
+	listFlag    bool
+	jsonFlag    bool
+	inputFlags  []string
+	hostFlags   []string
+	dotenvFlags []string
+	outputMode  string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "run",
-	Short: "A brief description of your application",
-	Long: `A longer description that spans multiple lines and likely contains
-examples and usage of using your application. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+	Use:   "run [task] [args...]",
+	Short: "Runs tasks, commands, and shells",
+	Long: `Runs tasks defined in the nearest runfile.
+
+Tasks run after the tasks they need. Any arguments after the task
+name are passed to the task.
+
+Tasks that declare sources are skipped when none of their sources,
+their definition or their environment changed since the last
+successful run and all of their generated files exist. Use --force
+to run them anyway and 'run status <task>' to see why a task would run.
+
+With --watch the task keeps running: whenever the sources of the task
+or its needs change, the affected tasks are restarted. Tasks without
+sources watch their working directory. Files ignored by .gitignore
+never trigger a restart.
+
+Use --list to show the tasks of the runfile and 'run help <task>' to
+show the help of a task. Without a task name, run opens a fuzzy picker
+over the tasks when attached to a terminal and asks for the inputs of
+the chosen task.
+
+--output-mode controls how the output of tasks is written:
+interleaved writes it unchanged, prefixed puts the task name in
+front of every line, grouped writes the output of each task as one
+block when the task finishes and quiet discards it. Prefixes are
+colored unless the output is not a terminal or NO_COLOR is set.`,
+	Version:       version.VERSION,
+	Args:          cobra.ArbitraryArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if listFlag || jsonFlag {
+			rf, err := loadRunfile()
+			if err != nil {
+				return err
+			}
+
+			if jsonFlag {
+				return writeTasksJSON(cmd.OutOrStdout(), rf)
+			}
+
+			return writeTasks(cmd.OutOrStdout(), rf)
+		}
+
+		interactive := picker.IsTerminal(os.Stdin) && picker.IsTerminal(os.Stdout)
+		if len(args) == 0 && !interactive {
+			return cmd.Help()
+		}
+
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			name, err := pickTask(r)
+			if err != nil {
+				if errors.Is(err, picker.ErrCancelled) {
+					return nil
+				}
+				return err
+			}
+
+			args = []string{name}
+		}
+
+		ctx, stop := exec.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer stop()
+
+		if watchFlag {
+			return r.Watch(ctx, nil, args[0], args[1:]...)
+		}
+
+		return r.Run(ctx, args[0], args[1:]...)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -32,20 +118,157 @@ to quickly create a Cobra application.`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+
+		// exit with the code of a failed task, so callers can tell
+		// failures apart.
+		if code, ok := exec.ExitCode(err); ok && code > 0 {
+			os.Exit(code)
+		}
 		os.Exit(1)
 	}
 }
 
 func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+	// flags after the task name belong to the task.
+	rootCmd.Flags().SetInterspersed(false)
+
+	rootCmd.PersistentFlags().StringVarP(&runfilePath, "file", "f", "", "path to the runfile (default is the nearest runfile)")
+	rootCmd.PersistentFlags().BoolVar(&force, "force", false, "run tasks even when they are up to date")
+	rootCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "rerun the task when its sources change")
+	rootCmd.Flags().BoolVarP(&listFlag, "list", "l", false, "list the tasks of the runfile")
+	rootCmd.Flags().BoolVar(&jsonFlag, "json", false, "list the tasks of the runfile as json")
+	rootCmd.Flags().StringArrayVarP(&inputFlags, "input", "i", nil, "set a task input as key=value")
+	rootCmd.Flags().StringArrayVarP(&hostFlags, "host", "H", nil, "run the task on these hosts or groups instead of its own")
+	rootCmd.Flags().StringArrayVarP(&dotenvFlags, "dotenv", "e", nil, "load a dotenv file for every task")
+	rootCmd.Flags().StringVar(&outputMode, "output-mode", "interleaved", "how task output is written: "+strings.Join(output.Modes(), ", "))
+
+	rootCmd.SetHelpCommand(helpCmd)
+	registerCompletions()
+}
+
+// errNoRunfile is returned by loadRunfile when --file is not given
+// and no runfile is found.
+var errNoRunfile = errors.New("no runfile found")
+
+// loadRunfile reads the runfile given by --file or the nearest
+// runfile in the current directory or its parents, and registers the
+// executables it declares.
+func loadRunfile() (*schema.Runfile, error) {
+	path := runfilePath
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		found, ok := schema.FindRunfile(cwd)
+		if !ok {
+			return nil, fmt.Errorf("%w in %s or its parents", errNoRunfile, cwd)
+		}
+
+		path = found
+	}
+
+	rf, err := schema.ReadRunfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	runner.RegisterExecutables(rf, exec.Registry)
+	return rf, nil
+}
+
+func newRunner() (*runner.Runner, error) {
+	rf, err := loadRunfile()
+	if err != nil {
+		return nil, err
+	}
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.run.yaml)")
+	r := runner.New(rf)
+	r.Force = force
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	inputs, err := parseInputs(inputFlags)
+	if err != nil {
+		return nil, err
+	}
+	r.Inputs = inputs
+	r.Hosts = hostFlags
+	r.DotEnv = dotenvFlags
+
+	mode, err := output.ParseMode(outputMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != output.Interleaved {
+		r.Output = output.New(mode, os.Stdout, os.Stderr)
+	}
+
+	return r, nil
 }
 
+// parseInputs reads key=value pairs given with --input.
+func parseInputs(values []string) (*schema.Inputs, error) {
+	inputs := &schema.Inputs{}
+	for _, kv := range values {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid input '%s', expected key=value", kv)
+		}
 
+		inputs.Set(key, value)
+	}
+
+	return inputs, nil
+}
+
+// pickTask lets the user choose a task and asks for the inputs of
+// the task that have neither a value nor a default.
+func pickTask(r *runner.Runner) (string, error) {
+	list := tasks(r.Runfile)
+	if len(list) == 0 {
+		return "", errors.New("no tasks found in " + r.Runfile.Path)
+	}
+
+	items := make([]picker.Item, 0, len(list))
+	for _, task := range list {
+		items = append(items, picker.Item{Label: task.Id, Detail: value(task.Desc)})
+	}
+
+	index, err := picker.PickTerminal(os.Stdin, os.Stdout, "task> ", items)
+	if err != nil {
+		return "", err
+	}
+
+	task := list[index]
+	for _, input := range r.MissingInputs(&task) {
+		question := input.Id
+		if input.Desc != nil {
+			question = fmt.Sprintf("%s (%s)", input.Id, *input.Desc)
+		}
+
+		var answer string
+		if len(input.Selection) > 0 {
+			choices := make([]picker.Item, 0, len(input.Selection))
+			for _, choice := range input.Selection {
+				choices = append(choices, picker.Item{Label: choice})
+			}
+
+			i, err := picker.PickTerminal(os.Stdin, os.Stdout, question+"> ", choices)
+			if err != nil {
+				return "", err
+			}
+			answer = input.Selection[i]
+		} else {
+			answer, err = picker.Prompt(os.Stdin, os.Stdout, question, "")
+			if err != nil {
+				return "", err
+			}
+		}
+
+		r.Inputs.Set(input.Id, answer)
+	}
+
+	return task.Id, nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON schema of the runfile format",
+	Long: `Prints the JSON schema of the runfile format, including every
+field alias. Point your editor at it to get completion and validation
+while writing runfiles, e.g. for the YAML language server:
+
+  # yaml-language-server: $schema=./runfile.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := schema.JSONSchema()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
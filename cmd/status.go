@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status <task>",
+	Short: "Explains why a task would run or be skipped",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+
+		status, err := r.Status(args[0])
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if status.UpToDate {
+			fmt.Fprintf(out, "task '%s' is up to date and would be skipped\n", status.Task)
+			return nil
+		}
+
+		fmt.Fprintf(out, "task '%s' would run:\n", status.Task)
+		for _, reason := range status.Reasons {
+			fmt.Fprintf(out, "  - %s\n", reason)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [runfile]",
+	Short: "Checks a runfile for problems",
+	Long: `Checks a runfile for problems without running anything.
+
+Besides the structure of the file, validate reports needs that name
+unknown tasks, dependency cycles, hosts and groups that are not
+declared, invalid timeouts and dotenv files that cannot be read or
+that set a key twice or use invalid names.
+Defaults to the runfile given by --file or the nearest runfile.`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return []cobra.Completion{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := runfilePath
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		if path == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			found, ok := schema.FindRunfile(cwd)
+			if !ok {
+				return fmt.Errorf("no runfile found in %s or its parents", cwd)
+			}
+			path = found
+		}
+
+		problems, err := schema.Validate(path)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		for _, problem := range problems {
+			fmt.Fprintf(out, "%s: %v\n", path, problem)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problem(s) in %s", len(problems), path)
+		}
+
+		fmt.Fprintf(out, "%s is valid\n", path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
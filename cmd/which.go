@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which <name>",
+	Short: "Shows where an executable is found and why",
+	Long: `Shows the path run uses for an executable, such as bash or
+python, and every candidate it considered, in order: the variable
+RUN_<NAME>_EXE, the path and search paths from the 'executables'
+section of the runfile, and the built-in search paths of the
+architecture and the platform. Globs in search paths are tried from
+the highest version to the lowest.
+
+XTASK_<NAME>_EXE, the name of the variable before RUN_<NAME>_EXE, is
+still read after it when set, but is deprecated and will be removed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := loadRunfile(); err != nil && !errors.Is(err, errNoRunfile) {
+			return err
+		}
+
+		name := args[0]
+		candidates, found := exec.Registry.Explain(name, nil)
+
+		out := cmd.OutOrStdout()
+		if found != "" {
+			fmt.Fprintf(out, "%s: %s\n", name, found)
+		} else {
+			fmt.Fprintf(out, "%s: not found\n", name)
+		}
+
+		if len(candidates) > 0 {
+			fmt.Fprintln(out)
+		}
+
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		picked := false
+		deprecated := []string{}
+		for _, c := range candidates {
+			source := c.Source
+			if c.Deprecated {
+				source += " (deprecated)"
+				deprecated = append(deprecated, c.Source)
+			}
+
+			marker := " "
+			result := c.Reason
+			if c.Resolved != "" {
+				result = "found " + c.Resolved
+				if !picked {
+					marker = "*"
+					picked = true
+				}
+			}
+			fmt.Fprintf(w, "%s %s\t%s\t%s\n", marker, source, c.Path, result)
+		}
+		w.Flush()
+
+		if len(deprecated) > 0 {
+			exe, _ := exec.Registry.Get(name)
+			fmt.Fprintln(out)
+			for _, variable := range deprecated {
+				fmt.Fprintf(out, "%s is deprecated and will be removed, set %s instead\n", variable, exe.Variable)
+			}
+		}
+
+		if found == "" {
+			return errors.New("executable not found: " + name)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
@@ -0,0 +1,318 @@
+// Package cache stores the files runtimes need to run inline scripts,
+// such as generated script files, in the cache directory of the
+// current user. Entries are named by the hash of their content, so
+// the same script is written once and shared by every run.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/env"
+)
+
+const (
+	// DefaultMaxSize is the size the cache is trimmed to.
+	DefaultMaxSize int64 = 512 * 1024 * 1024
+
+	// DefaultMaxAge is the time after which unused entries are
+	// removed.
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// Cache is a directory of entries, files or directories, named by
+// the hash of their content.
+type Cache struct {
+	Dir string
+
+	// MaxSize is the total size of the entries Evict keeps. Zero
+	// means no limit.
+	MaxSize int64
+
+	// MaxAge is the time since their last use after which Evict
+	// removes entries. Zero means no limit.
+	MaxAge time.Duration
+
+	evict sync.Once
+}
+
+// Entry is a file or directory in the cache.
+type Entry struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+var (
+	defaultCache *Cache
+	defaultOnce  sync.Once
+)
+
+// Default returns the cache in the 'run' directory of the cache
+// directory of the current user, see Root.
+func Default() *Cache {
+	defaultOnce.Do(func() {
+		defaultCache = &Cache{
+			Dir:     filepath.Join(Root(), "scripts"),
+			MaxSize: DefaultMaxSize,
+			MaxAge:  DefaultMaxAge,
+		}
+	})
+
+	return defaultCache
+}
+
+// Root returns the cache directory of run: the 'run' directory in
+// the directory named by env.HOME_CACHE, the cache directory of the
+// platform or '.cache' in the home directory.
+func Root() string {
+	dir := env.Get(env.HOME_CACHE)
+	if dir == "" {
+		dir, _ = os.UserCacheDir()
+	}
+
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".cache")
+	}
+
+	return filepath.Join(dir, "run")
+}
+
+// Key returns the hash of the parts, which names their entry.
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		// keeps {"ab", "c"} apart from {"a", "bc"}.
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Write stores data in a file named by its hash and the extension,
+// e.g. '.go', and returns the path of the file. The file is written
+// to a temporary file first and renamed into place, so concurrent
+// writers never leave a partial file behind. Only the current user
+// can read the file.
+func (c *Cache) Write(ext string, data []byte) (string, error) {
+	path := c.Path(Key(data) + ext)
+	if c.Touch(path) {
+		return path, nil
+	}
+
+	if err := c.mkdir(); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(c.Dir, ".tmp-*"+ext)
+	if err != nil {
+		return "", err
+	}
+
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	c.evictOnce()
+	return path, nil
+}
+
+// MakeDir returns the path of the directory entry with the given
+// name, which should be derived from the content, see Key. When the
+// entry does not exist, fill is called with a temporary directory
+// that is renamed into place once fill succeeds, so a directory that
+// failed to fill or is still being filled is never used.
+func (c *Cache) MakeDir(name string, fill func(dir string) error) (string, error) {
+	path := c.Path(name)
+	if c.Touch(path) {
+		return path, nil
+	}
+
+	if err := c.mkdir(); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.MkdirTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+
+	if err := fill(tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.RemoveAll(tmp)
+		// another process filled the entry first.
+		if c.Touch(path) {
+			return path, nil
+		}
+		return "", err
+	}
+
+	c.evictOnce()
+	return path, nil
+}
+
+// Path returns the path of the entry with the given name.
+func (c *Cache) Path(name string) string {
+	return filepath.Join(c.Dir, name)
+}
+
+// Touch marks the entry at path as used, which keeps it from being
+// evicted for its age. It reports whether the entry exists.
+func (c *Cache) Touch(path string) bool {
+	now := time.Now()
+	return os.Chtimes(path, now, now) == nil
+}
+
+// List returns the entries of the cache, the most recently used
+// first.
+func (c *Cache) List() ([]Entry, error) {
+	items, err := os.ReadDir(c.Dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		// entries that are still being written.
+		if strings.HasPrefix(item.Name(), ".tmp-") {
+			continue
+		}
+
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+
+		entry := Entry{
+			Name:    item.Name(),
+			Path:    c.Path(item.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			IsDir:   info.IsDir(),
+		}
+
+		if entry.IsDir {
+			entry.Size = dirSize(entry.Path)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+
+	return entries, nil
+}
+
+// Evict removes the entries that were not used for MaxAge, then the
+// least recently used entries until the cache fits in MaxSize. It
+// returns the removed entries.
+func (c *Cache) Evict() ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []Entry{}
+	var total int64
+	now := time.Now()
+	for _, entry := range entries {
+		tooOld := c.MaxAge > 0 && now.Sub(entry.ModTime) > c.MaxAge
+		tooBig := c.MaxSize > 0 && total+entry.Size > c.MaxSize
+		if !tooOld && !tooBig {
+			total += entry.Size
+			continue
+		}
+
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+
+	return removed, nil
+}
+
+// Clear removes every entry of the cache.
+func (c *Cache) Clear() error {
+	items, err := os.ReadDir(c.Dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, item := range items {
+		if err := os.RemoveAll(c.Path(item.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evictOnce evicts old entries the first time the cache grows.
+// Failures are ignored, the cache only gets larger than it should.
+func (c *Cache) evictOnce() {
+	c.evict.Do(func() {
+		c.Evict()
+	})
+}
+
+func (c *Cache) mkdir() error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	// a directory created by an older version or by hand may be
+	// readable by others.
+	return os.Chmod(c.Dir, 0700)
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size
+}
@@ -0,0 +1,153 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoot(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("LocalAppData", dir)
+
+	assert.Equal(t, filepath.Join(dir, "run"), cache.Root())
+}
+
+func TestWrite(t *testing.T) {
+	c := &cache.Cache{Dir: filepath.Join(t.TempDir(), "scripts")}
+
+	path, err := c.Write(".go", []byte("package main"))
+	assert.NoError(t, err)
+	assert.Equal(t, c.Path(cache.Key([]byte("package main"))+".go"), path)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "package main", string(data))
+
+	again, err := c.Write(".go", []byte("package main"))
+	assert.NoError(t, err)
+	assert.Equal(t, path, again)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		info, err = os.Stat(c.Dir)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	}
+}
+
+func TestWrite_Concurrent(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir()}
+	script := []byte(strings.Repeat("echo hello\n", 10000))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path, err := c.Write(".sh", script)
+			assert.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, len(script), len(data))
+		}()
+	}
+	wg.Wait()
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestKey(t *testing.T) {
+	assert.NotEqual(t, cache.Key([]byte("ab"), []byte("c")), cache.Key([]byte("a"), []byte("bc")))
+	assert.Len(t, cache.Key([]byte("a")), 64)
+}
+
+func TestEvict(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir(), MaxAge: time.Hour, MaxSize: 10}
+
+	old, _ := c.Write(".txt", []byte("old"))
+	lru, _ := c.Write(".txt", []byte("least"))
+	mru, _ := c.Write(".txt", []byte("most"))
+	dir := c.Path("module")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("go"), 0600))
+
+	now := time.Now()
+	os.Chtimes(old, now, now.Add(-2*time.Hour))
+	os.Chtimes(lru, now, now.Add(-time.Minute))
+	os.Chtimes(mru, now, now)
+	os.Chtimes(dir, now, now)
+
+	removed, err := c.Evict()
+	assert.NoError(t, err)
+	names := []string{}
+	for _, entry := range removed {
+		names = append(names, entry.Path)
+	}
+	assert.ElementsMatch(t, []string{old, lru}, names)
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	for _, entry := range entries {
+		if entry.IsDir {
+			assert.Equal(t, int64(2), entry.Size)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	c := &cache.Cache{Dir: filepath.Join(t.TempDir(), "missing")}
+	assert.NoError(t, c.Clear())
+
+	c.Write(".txt", []byte("a"))
+	c.Write(".txt", []byte("b"))
+	assert.NoError(t, c.Clear())
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMakeDir(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir()}
+
+	calls := 0
+	fill := func(dir string) error {
+		calls++
+		return os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0600)
+	}
+
+	path, err := c.MakeDir("module", fill)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Path("module"), path)
+	assert.FileExists(t, filepath.Join(path, "main.go"))
+
+	again, err := c.MakeDir("module", fill)
+	assert.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, 1, calls)
+
+	_, err = c.MakeDir("broken", func(dir string) error {
+		return os.ErrInvalid
+	})
+	assert.ErrorIs(t, err, os.ErrInvalid)
+
+	entries, err := c.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,324 @@
+// Package doctor checks the environment run works in: the executables
+// runtimes use, the runfile and the hosts it declares.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/osinfo"
+	"github.com/hyprxlabs/run/internal/runner"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// Severity tells problems that break tasks from the ones that might.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// DefaultTimeout limits each version and ssh check.
+const DefaultTimeout = 10 * time.Second
+
+// Options configures what Check checks.
+type Options struct {
+	// Runfile is the runfile to validate, nil when there is none.
+	Runfile *schema.Runfile
+
+	// Registry holds the executables to resolve, defaults to
+	// exec.Registry.
+	Registry *exec.ExecutableRegistry
+
+	// Hosts checks that the hosts of the runfile are reachable over
+	// ssh.
+	Hosts bool
+
+	// Timeout limits each version and ssh check, defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Report is what Check found.
+type Report struct {
+	OS          *schema.OS   `json:"os"`
+	Runfile     string       `json:"runfile,omitempty"`
+	Executables []Executable `json:"executables"`
+	Hosts       []Host       `json:"hosts"`
+	Problems    []Problem    `json:"problems"`
+}
+
+// Executable is a registered executable and where it was found.
+type Executable struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// Source is the variable or search path that matched, e.g.
+	// RUN_BASH_EXE or linux, and Candidate the path it gave.
+	Source    string `json:"source,omitempty"`
+	Candidate string `json:"candidate,omitempty"`
+}
+
+// Host is a host of the runfile and whether ssh reaches it.
+type Host struct {
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Problem is something that keeps tasks from running, or might.
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Errors returns the number of problems with the Error severity.
+func (r *Report) Errors() int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == Error {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *Report) add(severity Severity, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check resolves the registered executables, validates the runfile
+// and checks that its hosts are reachable.
+func Check(ctx context.Context, options *Options) *Report {
+	if options == nil {
+		options = &Options{}
+	}
+
+	registry := options.Registry
+	if registry == nil {
+		registry = exec.Registry
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	report := &Report{Executables: []Executable{}, Hosts: []Host{}, Problems: []Problem{}}
+	report.OS, _ = osinfo.Local()
+	if report.OS == nil {
+		report.OS = &schema.OS{Platform: runtime.GOOS, Arch: runtime.GOARCH}
+	}
+
+	executables(ctx, report, registry, timeout)
+
+	rf := options.Runfile
+	if rf == nil {
+		return report
+	}
+
+	report.Runfile = rf.Path
+	problems, err := schema.Validate(rf.Path)
+	if err != nil {
+		report.add(Error, "%v", err)
+	}
+	for _, problem := range problems {
+		report.add(Error, "%s: %v", rf.Path, problem)
+	}
+
+	uses(report, rf)
+
+	if options.Hosts {
+		hosts(ctx, report, rf, timeout)
+	}
+
+	return report
+}
+
+// executables resolves the registered executables and reads their
+// versions concurrently.
+func executables(ctx context.Context, report *Report, registry *exec.ExecutableRegistry, timeout time.Duration) {
+	names := registry.Names()
+	report.Executables = make([]Executable, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		candidates, found := registry.Explain(name, nil)
+		entry := Executable{Name: name, Path: found}
+		for _, c := range candidates {
+			if c.Resolved == found && found != "" {
+				entry.Source = c.Source
+				entry.Candidate = c.Path
+				break
+			}
+		}
+
+		for _, c := range candidates {
+			if c.Deprecated {
+				exe, _ := registry.Get(name)
+				report.add(Warning, "%s is deprecated and will be removed, set %s instead", c.Source, exe.Variable)
+			}
+		}
+
+		if name == "bash" {
+			if git, ok := ShadowedBash(candidates, found); ok {
+				report.add(Warning, "bash resolves to the WSL launcher %s, which shadows the bash of git at %s, set %s to use it", found, git, exec.Variable("bash"))
+			}
+		}
+
+		report.Executables[i] = entry
+		if found == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Executables[i].Version = Version(ctx, name, found, timeout)
+		}()
+	}
+	wg.Wait()
+}
+
+// uses reports tasks whose runtime cannot be found.
+func uses(report *Report, rf *schema.Runfile) {
+	found := map[string]string{}
+	for _, e := range report.Executables {
+		found[e.Name] = e.Path
+	}
+
+	r := &runner.Runner{Runfile: rf, Platform: report.OS.Platform}
+	for _, id := range rf.Tasks.Keys() {
+		task, err := r.Task(id)
+		if err != nil || !task.Supports(r.Platform) {
+			continue
+		}
+
+		name := strings.ToLower(r.Uses(task))
+		if _, ok := rf.Runtimes.Get(name); ok {
+			continue
+		}
+
+		switch name {
+		case "golang":
+			name = "go"
+		case "csharp":
+			name = "dotnet"
+		}
+
+		if path, ok := found[name]; ok && path == "" {
+			report.add(Error, "task '%s' uses %s, which is not found, set %s or add it to the executables of the runfile", task.Id, name, exec.Variable(name))
+		}
+	}
+}
+
+// hosts checks that ssh reaches each host of the runfile.
+func hosts(ctx context.Context, report *Report, rf *schema.Runfile, timeout time.Duration) {
+	keys := rf.Hosts.Keys()
+	report.Hosts = make([]Host, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		host, _ := rf.Hosts.Get(key)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Hosts[i] = Reach(ctx, host, timeout)
+		}()
+	}
+	wg.Wait()
+
+	for _, host := range report.Hosts {
+		if !host.Reachable {
+			report.add(Warning, "host '%s' is not reachable: %s", host.Host, host.Error)
+		}
+	}
+}
+
+// Reach runs 'true' on the host over ssh.
+func Reach(ctx context.Context, host *schema.HostEntry, timeout time.Duration) Host {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	seconds := fmt.Sprintf("ConnectTimeout=%d", max(1, int(timeout.Seconds())))
+	args := append([]string{"-o", seconds}, osinfo.SSHArgs(host, "true")...)
+
+	cmd := exec.NewContext(ctx, "ssh", args...)
+	cmd.DisableLogger()
+	out, err := cmd.Output()
+	if err != nil {
+		message := err.Error()
+		if stderr := strings.TrimSpace(string(out.Stderr)); stderr != "" {
+			message = stderr
+		}
+		return Host{Host: host.Host, Error: message}
+	}
+
+	return Host{Host: host.Host, Reachable: true}
+}
+
+// Version returns the first line the executable prints for its
+// version, or an empty string when it prints none in time.
+func Version(ctx context.Context, name, path string, timeout time.Duration) string {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := []string{"--version"}
+	switch name {
+	case "go", "golang":
+		args = []string{"version"}
+	case "powershell":
+		args = []string{"-NoProfile", "-Command", "$PSVersionTable.PSVersion.ToString()"}
+	}
+
+	cmd := exec.NewContext(ctx, path, args...)
+	cmd.DisableLogger()
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	text := string(out.Stdout)
+	if strings.TrimSpace(text) == "" {
+		// python 2 prints its version to stderr.
+		text = string(out.Stderr)
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// ShadowedBash reports whether bash resolves to the launcher of WSL
+// while the bash of git is one of the later candidates, and returns
+// the bash of git.
+func ShadowedBash(candidates []exec.Candidate, found string) (string, bool) {
+	if !isWSLBash(found) {
+		return "", false
+	}
+
+	for _, c := range candidates {
+		if c.Resolved != "" && !isWSLBash(c.Resolved) && strings.Contains(strings.ToLower(c.Resolved), "git") {
+			return c.Resolved, true
+		}
+	}
+
+	return "", false
+}
+
+func isWSLBash(path string) bool {
+	path = strings.ReplaceAll(strings.ToLower(path), `\`, "/")
+	return strings.HasSuffix(path, "/system32/bash.exe") || strings.HasSuffix(path, "/windowsapps/bash.exe")
+}
@@ -0,0 +1,97 @@
+package doctor_test
+
+import (
+	"context"
+	"os"
+	ose "os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/doctor"
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("search paths of linux")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tasks:
+  env:
+    run: echo
+    dotenv: [missing.env]
+  tool:
+    uses: missing-tool
+    run: echo
+`), 0o644))
+
+	rf, err := schema.ReadRunfile(path)
+	require.NoError(t, err)
+
+	registry := exec.NewRegistry()
+	registry.Register("sh", &exec.Executable{Name: "sh", Linux: []string{"/bin/sh", "sh"}})
+	registry.Register("missing-tool", &exec.Executable{Name: "missing-tool"})
+
+	report := doctor.Check(context.Background(), &doctor.Options{Runfile: rf, Registry: registry})
+	require.Len(t, report.Executables, 2)
+	assert.Equal(t, doctor.Executable{Name: "missing-tool"}, report.Executables[0])
+	assert.Equal(t, "sh", report.Executables[1].Name)
+	assert.NotEmpty(t, report.Executables[1].Path)
+	assert.Equal(t, "linux", report.Executables[1].Source)
+
+	require.Len(t, report.Problems, 2)
+	assert.Contains(t, report.Problems[0].Message, "dotenv file 'missing.env' of task 'env' cannot be read")
+	assert.Equal(t, doctor.Problem{
+		Severity: doctor.Error,
+		Message:  "task 'tool' uses missing-tool, which is not found, set RUN_MISSING_TOOL_EXE or add it to the executables of the runfile",
+	}, report.Problems[1])
+	assert.Equal(t, 2, report.Errors())
+	assert.Empty(t, report.Hosts)
+}
+
+func TestCheck_DeprecatedVariable(t *testing.T) {
+	sh, err := ose.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	t.Setenv("XTASK_SH_EXE", sh)
+
+	registry := exec.NewRegistry()
+	registry.Register("sh", &exec.Executable{Name: "sh"})
+
+	report := doctor.Check(context.Background(), &doctor.Options{Registry: registry})
+	require.Len(t, report.Executables, 1)
+	assert.Equal(t, "XTASK_SH_EXE", report.Executables[0].Source)
+	assert.Contains(t, report.Problems, doctor.Problem{
+		Severity: doctor.Warning,
+		Message:  "XTASK_SH_EXE is deprecated and will be removed, set RUN_SH_EXE instead",
+	})
+}
+
+func TestShadowedBash(t *testing.T) {
+	wsl := `C:\Windows\System32\bash.exe`
+	git := `C:\Program Files\Git\bin\bash.exe`
+	candidates := []exec.Candidate{
+		{Source: "RUN_BASH_EXE", Reason: "not set"},
+		{Source: "windows", Path: git, Reason: "not found"},
+		{Source: "windows", Path: wsl, Resolved: wsl},
+		{Source: "windows", Path: "bash", Resolved: `C:\Program Files\Git\usr\bin\bash.exe`},
+	}
+
+	found, ok := doctor.ShadowedBash(candidates, wsl)
+	assert.True(t, ok)
+	assert.Equal(t, `C:\Program Files\Git\usr\bin\bash.exe`, found)
+
+	_, ok = doctor.ShadowedBash(candidates[:3], wsl)
+	assert.False(t, ok)
+
+	_, ok = doctor.ShadowedBash(candidates, git)
+	assert.False(t, ok)
+}
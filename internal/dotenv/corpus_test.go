@@ -0,0 +1,129 @@
+package dotenv_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/dotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse_Corpus parses the files in testdata/corpus and compares
+// the variables with the json file of the same name, which holds what
+// docker compose reads from the file.
+func TestParse_Corpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.env"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".env")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+
+			golden, err := os.ReadFile(strings.TrimSuffix(file, ".env") + ".json")
+			assert.NoError(t, err)
+
+			expected := map[string]string{}
+			assert.NoError(t, json.Unmarshal(golden, &expected))
+
+			doc, err := dotenv.ParseWithOptions(string(data), &dotenv.ParseOptions{Strict: true})
+			assert.NoError(t, err)
+			assert.Equal(t, expected, doc.ToMap())
+
+			// the document reads the same after writing it out.
+			again, err := dotenv.Parse(doc.String())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, again.ToMap())
+		})
+	}
+}
+
+func TestParse_Export(t *testing.T) {
+	doc, err := dotenv.Parse("export A=1\nB=2\nexport C=\"three\"\n")
+	assert.NoError(t, err)
+
+	exported := map[string]bool{}
+	for _, node := range doc.ToArray() {
+		if node.Type == dotenv.VARIABLE_TOKEN {
+			exported[*node.Key] = node.Export
+		}
+	}
+	assert.Equal(t, map[string]bool{"A": true, "B": false, "C": true}, exported)
+	assert.Equal(t, "export A=1\nB=2\nexport C=\"three\"", doc.String())
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		strict  bool
+		message string
+		line    int
+		column  int
+		detail  string
+	}{
+		{
+			name:    "unterminated quote",
+			input:   "A=1\nB=\"open\nC=3\n",
+			message: "invalid syntax: unterminated quoted value",
+			line:    2,
+			column:  3,
+		},
+		{
+			name:    "text after quote",
+			input:   "A=\"one\" two\n",
+			message: "invalid syntax: unexpected character 't' after quoted value",
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "key with blanks",
+			input:   "A B=1\n",
+			message: "invalid syntax: key terminated by whitespace. fix key",
+			line:    1,
+			column:  3,
+		},
+		{
+			name:    "duplicate key",
+			input:   "A=1\n# comment\n  A=2\n",
+			strict:  true,
+			message: "duplicate key 'A'",
+			detail:  "first set on line 1",
+			line:    3,
+			column:  3,
+		},
+		{
+			name:    "invalid name",
+			input:   "A=1\n1B=2\n",
+			strict:  true,
+			message: "invalid name '1B'",
+			line:    2,
+			column:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := dotenv.ParseWithOptions(test.input, &dotenv.ParseOptions{Strict: test.strict})
+
+			var parseErr *dotenv.ParseError
+			if assert.True(t, errors.As(err, &parseErr), "expected a ParseError, got %v", err) {
+				assert.Equal(t, test.message, parseErr.Message)
+				assert.Equal(t, test.line, parseErr.Line)
+				assert.Equal(t, test.column, parseErr.Column)
+				assert.Equal(t, test.detail, parseErr.Detail)
+			}
+		})
+	}
+
+	// duplicates and unusual names are fine unless strict.
+	doc, err := dotenv.Parse("A=1\nA=2\n1B=3\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "2", "1B": "3"}, doc.ToMap())
+}
@@ -1,5 +1,7 @@
 package dotenv
 
+import "strings"
+
 const (
 	NEWLINE_TOKEN  = 0
 	COMMENT_TOKEN  = 1
@@ -13,6 +15,9 @@ type Node struct {
 	Key    *string
 	Inline bool
 	Quote  *rune
+
+	// Export is set on variables written with an 'export' prefix.
+	Export bool
 }
 
 type EnvDoc struct {
@@ -106,6 +111,13 @@ func (doc *EnvDoc) AddQuotedVariable(key, value string, quote rune) {
 	})
 }
 
+// setExport marks the variable added last as exported.
+func (doc *EnvDoc) setExport(export bool) {
+	if n := len(doc.tokens); n > 0 && doc.tokens[n-1].Type == VARIABLE_TOKEN {
+		doc.tokens[n-1].Export = export
+	}
+}
+
 func (doc *EnvDoc) Add(token Node) {
 	if token.Type == NEWLINE_TOKEN || token.Type == COMMENT_TOKEN || token.Type == VARIABLE_TOKEN {
 		doc.tokens = append(doc.tokens, token)
@@ -233,10 +245,14 @@ func (doc *EnvDoc) String() string {
 				continue
 			}
 
+			if token.Export {
+				result += "export "
+			}
+
 			result += *token.Key + "="
 			if token.Quote != nil {
 				result += string(*token.Quote)
-				result += token.Value
+				result += escapeQuoted(token.Value, *token.Quote)
 				result += string(*token.Quote)
 			} else {
 				result += token.Value
@@ -255,3 +271,24 @@ func (doc *EnvDoc) String() string {
 
 	return result
 }
+
+// escapeQuoted escapes the characters the lexer reads as escapes in
+// a value quoted with quote, so the value survives a round-trip.
+// Newlines are kept, the lexer reads multi-line quoted values.
+func escapeQuoted(value string, quote rune) string {
+	var sb strings.Builder
+	for _, c := range value {
+		switch {
+		case c == quote:
+			sb.WriteRune('\\')
+		case c == '\\' && quote != '\'':
+			sb.WriteRune('\\')
+		case c == '\r' && quote != '\'':
+			sb.WriteString("\\r")
+			continue
+		}
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+}
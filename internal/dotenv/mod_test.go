@@ -183,12 +183,12 @@ func TestEnvDoc_GetValue_NotFound(t *testing.T) {
 
 func TestParseError_ErrorString(t *testing.T) {
 	err := &dotenv.ParseError{
-		Message: "Test error",
+		Message: "test error",
 		Line:    5,
 		Column:  10,
 	}
 
-	expected := "Test error at line 5, column 10"
+	expected := "test error on line 5, column 10"
 	assert.Equal(t, expected, err.Error())
 	assert.Equal(t, expected, err.String())
 }
@@ -1,8 +1,8 @@
 package dotenv
 
 import (
-	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"unicode"
 )
@@ -31,6 +31,10 @@ type Token struct {
 	Quote    int
 	Start    *Mark
 	End      *Mark
+
+	// Export is set on names with an 'export' prefix, which lets
+	// shells source the file.
+	Export bool
 }
 
 type parseState struct {
@@ -43,6 +47,7 @@ type parseState struct {
 	Start         *Mark
 	KeyTerminated bool
 	Kind          int
+	Export        bool
 }
 
 func (state *parseState) SetKind(kind int) {
@@ -54,10 +59,15 @@ func (state *parseState) SetKind(kind int) {
 	state.Start = nil
 }
 
+// ParseError is a problem at a position of a dotenv document.
 type ParseError struct {
 	Message string
 	Line    int
 	Column  int
+
+	// Detail follows the position, e.g. where a duplicate key was
+	// first set.
+	Detail string
 }
 
 func (t *Token) Value() string {
@@ -68,11 +78,16 @@ func (t *Token) Value() string {
 }
 
 func (e *ParseError) Error() string {
-	return e.Message + " at line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column)
+	msg := e.Message + " on line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column)
+	if e.Detail != "" {
+		msg += ", " + e.Detail
+	}
+
+	return msg
 }
 
 func (e *ParseError) String() string {
-	return e.Message + " at line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column)
+	return e.Error()
 }
 
 func Lex(input string) ([]*Token, error) {
@@ -233,7 +248,11 @@ func Lex(input string) ([]*Token, error) {
 				}
 
 				if !comment && !unicode.IsSpace(c) {
-					return nil, errors.New("Invalid syntax: unexpected character '" + string(c) + "' after quoted value ended and before newline")
+					return nil, &ParseError{
+						Message: "invalid syntax: unexpected character '" + string(c) + "' after quoted value",
+						Line:    state.Line,
+						Column:  state.Column,
+					}
 				}
 
 				state.Column++
@@ -335,7 +354,7 @@ func Lex(input string) ([]*Token, error) {
 				}
 
 				fail := &ParseError{
-					Message: "Invalid syntax: unexpected character '" + string(c) + "'",
+					Message: "invalid syntax: unexpected character '" + string(c) + "'",
 					Line:    state.Line,
 					Column:  state.Column,
 				}
@@ -362,9 +381,17 @@ func Lex(input string) ([]*Token, error) {
 
 					if unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' {
 
+						if state.KeyTerminated && !state.Export && string(state.Buffer) == "export" {
+							state.Export = true
+							state.KeyTerminated = false
+							state.Start = &Mark{Line: state.Line, Column: state.Column}
+							state.Buffer = append(state.Buffer[:0], c)
+							continue
+						}
+
 						if state.KeyTerminated {
 							e := &ParseError{
-								Message: "Invalid syntax: key terminated by whitespace. fix key",
+								Message: "invalid syntax: key terminated by whitespace. fix key",
 								Line:    state.Line,
 								Column:  state.Column,
 							}
@@ -385,7 +412,7 @@ func Lex(input string) ([]*Token, error) {
 					}
 
 					fail := &ParseError{
-						Message: "Invalid syntax: unexpected character in name '" + string(c) + "'",
+						Message: "invalid syntax: unexpected character in name '" + string(c) + "'",
 						Line:    state.Line,
 						Column:  state.Column,
 					}
@@ -441,13 +468,24 @@ func Lex(input string) ([]*Token, error) {
 								continue
 							}
 
+							// '#' right after the '=' is part of the value,
+							// while after whitespace it starts a comment and
+							// leaves the value empty, as further below.
+							if c == '#' && unicode.IsSpace(runes[i-1]) {
+								captureToken(state, TOKEN_VALUE)
+								state.SetKind(TOKEN_COMMENT)
+								continue
+							}
+
 							// println("Appending character to buffer:", string(c))
 							state.Buffer = append(state.Buffer, c)
 							continue
 						}
 					}
 
-					if c == '#' {
+					// like docker compose, '#' only starts a comment after
+					// whitespace, so values such as urls keep fragments.
+					if c == '#' && unicode.IsSpace(state.Buffer[len(state.Buffer)-1]) {
 						// println("Found comment in value, capturing token")
 						captureToken(state, TOKEN_VALUE)
 
@@ -467,6 +505,14 @@ func Lex(input string) ([]*Token, error) {
 		}
 	}
 
+	if state.Quote != quote_none {
+		return nil, &ParseError{
+			Message: "invalid syntax: unterminated quoted value",
+			Line:    state.Start.Line,
+			Column:  state.Start.Column,
+		}
+	}
+
 	if len(state.Buffer) > 0 {
 		switch state.Kind {
 		case TOKEN_NAME:
@@ -517,7 +563,7 @@ func handleQuotedChar(state *parseState, runes []rune, i int, c rune, p rune) (i
 						return 9, nil // skip the next 9 characters
 					} else {
 						return 0, &ParseError{
-							Message: "Invalid unicode escape sequence",
+							Message: "invalid unicode escape sequence",
 							Line:    state.Line,
 							Column:  state.Column,
 						}
@@ -540,7 +586,7 @@ func handleQuotedChar(state *parseState, runes []rune, i int, c rune, p rune) (i
 						return 5, nil
 					} else {
 						return 0, &ParseError{
-							Message: "Invalid unicode escape sequence",
+							Message: "invalid unicode escape sequence",
 							Line:    state.Line,
 							Column:  state.Column,
 						}
@@ -581,6 +627,11 @@ func captureToken(state *parseState, kind int) *Token {
 			Column: state.Column - 1,
 		},
 	}
+
+	if kind == TOKEN_NAME {
+		token.Export = state.Export
+		state.Export = false
+	}
 	/*
 		tokenName := ""
 		switch kind {
@@ -612,7 +663,26 @@ func captureToken(state *parseState, kind int) *Token {
 	return token
 }
 
+// ParseOptions configures ParseWithOptions.
+type ParseOptions struct {
+	// Strict rejects keys that are set more than once and keys that
+	// are not valid shell variable names.
+	Strict bool
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func Parse(input string) (*EnvDoc, error) {
+	return ParseWithOptions(input, nil)
+}
+
+// ParseWithOptions parses the dotenv document. Errors are reported as
+// a ParseError with the position of the problem.
+func ParseWithOptions(input string, options *ParseOptions) (*EnvDoc, error) {
+	if options == nil {
+		options = &ParseOptions{}
+	}
+
 	tokens, err := Lex(input)
 	if err != nil {
 		return nil, err
@@ -623,6 +693,8 @@ func Parse(input string) (*EnvDoc, error) {
 	}
 
 	var key *string
+	export := false
+	seen := map[string]*Mark{}
 
 	for _, token := range tokens {
 		switch token.Type {
@@ -637,22 +709,43 @@ func Parse(input string) (*EnvDoc, error) {
 			// println("#", string(token.RawValue))
 
 		case TOKEN_NAME:
+			v := string(token.RawValue)
+			if options.Strict {
+				if !validName.MatchString(v) {
+					return nil, &ParseError{
+						Message: "invalid name '" + v + "'",
+						Line:    token.Start.Line,
+						Column:  token.Start.Column,
+					}
+				}
+
+				if first, ok := seen[v]; ok {
+					return nil, &ParseError{
+						Message: fmt.Sprintf("duplicate key '%s'", v),
+						Line:    token.Start.Line,
+						Column:  token.Start.Column,
+						Detail:  fmt.Sprintf("first set on line %d", first.Line),
+					}
+				}
+				seen[v] = token.Start
+			}
 
 			if key == nil {
-				v := string(token.RawValue)
 				key = &v
+				export = token.Export
 				// println("TOKEN_NAME:", *key)
 				continue
 			}
 			// println("TOKEN_NAME:", *key)
 			doc.AddVariable(*key, "")
-			v2 := string(token.RawValue)
-			key = &v2
+			doc.setExport(export)
+			key = &v
+			export = token.Export
 		case TOKEN_VALUE:
 			// println("TOKEN_VALUE:", string(token.RawValue))
 			if key == nil {
 				return nil, &ParseError{
-					Message: "Invalid syntax: value without a key",
+					Message: "invalid syntax: value without a key",
 					Line:    token.Start.Line,
 					Column:  token.Start.Column,
 				}
@@ -660,6 +753,7 @@ func Parse(input string) (*EnvDoc, error) {
 
 			if token.Quote == quote_none {
 				doc.AddVariable(*key, string(token.RawValue))
+				doc.setExport(export)
 				key = nil
 				continue
 			}
@@ -677,12 +771,14 @@ func Parse(input string) (*EnvDoc, error) {
 			}
 
 			doc.AddQuotedVariable(*key, string(token.RawValue), r)
+			doc.setExport(export)
 			key = nil
 		}
 	}
 
 	if key != nil {
 		doc.AddVariable(*key, "")
+		doc.setExport(export)
 		key = nil
 	}
 
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Variables holds the variables an expansion reads and assigns, such
+// as a *schema.Environment.
+type Variables interface {
+	Get(key string) (string, bool)
+	Set(key, value string)
+	Iter() iter.Seq2[string, string]
+}
+
+// Context expands values against a set of variables and positional
+// arguments instead of the process environment and os.Args, so
+// contexts bound to different variables can expand in parallel.
+type Context struct {
+	ctx  context.Context
+	vars Variables
+	args []string
+
+	// Timeout limits each command substitution, DefaultCommandTimeout
+	// when zero.
+	Timeout time.Duration
+}
+
+// NewContext returns a context bound to the variables and the
+// positional arguments, $1 being the first. Command substitutions
+// are canceled when ctx is done and run with only the variables as
+// their environment.
+func NewContext(ctx context.Context, vars Variables, args ...string) *Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Context{ctx: ctx, vars: vars, args: args}
+}
+
+// Options returns the expand options that bind an expansion to the
+// context.
+func (c *Context) Options() []ExpandOption {
+	return []ExpandOption{
+		WithLookup(c.vars.Get),
+		WithSet(func(key, value string) error {
+			c.vars.Set(key, value)
+			return nil
+		}),
+		WithEnviron(c.environ),
+		WithArgs(c.args...),
+		WithContext(c.ctx),
+		WithTimeout(c.Timeout),
+	}
+}
+
+// Expand expands the input against the context. The options are
+// applied after the ones of the context, so WithSet, for one, can
+// replace how assignments are made.
+func (c *Context) Expand(input string, options ...ExpandOption) (string, error) {
+	return Expand(input, append(c.Options(), options...)...)
+}
+
+func (c *Context) environ() []string {
+	environ := []string{}
+	for k, v := range c.vars.Iter() {
+		environ = append(environ, k+"="+v)
+	}
+
+	return environ
+}
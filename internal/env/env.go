@@ -11,6 +11,12 @@ func Get(key string) string {
 	return os.Getenv(key)
 }
 
+// Lookup retrieves the value of the environment variable named by
+// key and reports whether it is present.
+func Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
 // Set the value of the environment variable named by key to value.
 // It returns an error if the variable cannot be set.
 func Set(key, value string) error {
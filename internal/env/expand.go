@@ -2,13 +2,13 @@ package env
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"runtime"
-	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/hyprxlabs/run/internal/cmdargs"
@@ -24,161 +24,145 @@ const (
 	windowsVariable     = 5
 )
 
+// DefaultCommandTimeout limits each command substitution when the
+// options set no timeout.
+const DefaultCommandTimeout = 30 * time.Second
+
 type ExpandOptions struct {
 	// If true, windows style environment variables will be expanded
-	Get                  func(string) string
-	Set                  func(string, string) error
-	Env                  map[string]string
+	Get func(string) string
+	// Lookup reports whether a variable is set, which the non-colon
+	// forms such as ${VAR-default} need to tell unset and empty
+	// apart. When nil, variables with an empty value count as unset.
+	Lookup func(string) (string, bool)
+
+	// Set assigns variables for ${VAR:=word}. When nil, assignments
+	// only last for the expansion.
+	Set func(string, string) error
+
+	// Environ returns the environment of command substitutions, to
+	// which Env is added. When both are empty, commands run with an
+	// empty environment.
+	Environ func() []string
+	Env     map[string]string
+
+	// Args are the positional parameters $1, $2 and so on, read when
+	// ExpandUnixArgs is set. $0 is never set.
+	Args []string
+
+	// Context cancels command substitutions, and Timeout limits each
+	// of them, DefaultCommandTimeout when zero.
+	Context context.Context
+	Timeout time.Duration
+
 	ExpandUnixArgs       bool
 	ExpandWindowsVars    bool
 	CommandSubstitution  bool
 	EnableShellExpansion bool
 	UseShell             string
 	ShellArgs            []string
+
+	// Command runs the expression of a command substitution and
+	// returns its output. When nil, the expression is run with the
+	// shell or, without shell expansion, as a single command.
+	Command func(expression string) (string, error)
 }
 
 type ExpandOption func(*ExpandOptions)
 
+// WithGet sets the function that reads variables. Variables with an
+// empty value count as unset unless WithLookup is given as well.
 func WithGet(f func(string) string) ExpandOption {
 	return func(o *ExpandOptions) {
 		o.Get = f
+		o.Lookup = nil
 	}
 }
 
-func WithSet(f func(string, string) error) ExpandOption {
+// WithLookup sets the function that reads variables and reports
+// whether they are set. It replaces the function set by WithGet.
+func WithLookup(f func(string) (string, bool)) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.Set = f
+		o.Lookup = f
+		o.Get = func(key string) string {
+			value, _ := f(key)
+			return value
+		}
 	}
 }
 
-func WithExpandUnixArgs(expand bool) ExpandOption {
+func WithSet(f func(string, string) error) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.ExpandUnixArgs = expand
+		o.Set = f
 	}
 }
 
-func WithExpandWindowsVars(expand bool) ExpandOption {
+// WithEnviron sets the function that returns the environment of
+// command substitutions.
+func WithEnviron(f func() []string) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.ExpandWindowsVars = expand
+		o.Environ = f
 	}
 }
 
-func WithCommandSubstitution(enable bool) ExpandOption {
+// WithArgs sets the positional parameters and enables their
+// expansion.
+func WithArgs(args ...string) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.CommandSubstitution = enable
+		o.Args = args
+		o.ExpandUnixArgs = true
 	}
 }
 
-func WithEnableShellExpansion(enable bool) ExpandOption {
+// WithContext sets the context that cancels command substitutions.
+func WithContext(ctx context.Context) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.EnableShellExpansion = enable
+		o.Context = ctx
 	}
 }
 
-func WithShell(shell string) ExpandOption {
+// WithTimeout limits how long each command substitution runs.
+func WithTimeout(timeout time.Duration) ExpandOption {
 	return func(o *ExpandOptions) {
-		o.UseShell = shell
+		o.Timeout = timeout
 	}
 }
 
-func interpolateVar(token string, o *ExpandOptions) (string, error) {
-	key := token
-	defaultValue := ""
-	message := ""
-	if strings.Contains(token, ":-") {
-
-		parts := split(token, ":-")
-		key = parts[0]
-		if len(parts) > 1 {
-			defaultValue = parts[1]
-		}
-	} else if strings.Contains(token, ":=") {
-		parts := split(token, ":=")
-		key = parts[0]
-		if len(parts) > 1 {
-			defaultValue = parts[1]
-		}
-		if !isValidBashVariable([]rune(key)) {
-			return "", errors.New("invalid bash variable syntax: invalid variable name")
-		}
-
-		if len(key) > 0 {
-			v := o.Get(key)
-			if len(v) == 0 {
-				o.Set(key, defaultValue)
-			}
-		}
-	} else if strings.Contains(token, ":?") {
-		parts := split(token, ":?")
-		key = parts[0]
-		if len(parts) > 1 {
-			message = parts[1]
-		}
-	} else if strings.Contains(token, ":") {
-		parts := split(token, ":")
-		key = parts[0]
-		if len(parts) > 1 {
-			defaultValue = parts[1]
-		}
-	}
-
-	if len(key) == 0 {
-		return "", errors.New("invalid bash variable syntax: empty variable name")
+func WithExpandUnixArgs(expand bool) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.ExpandUnixArgs = expand
 	}
+}
 
-	if o.ExpandUnixArgs {
-		i, err := strconv.Atoi(key)
-		if err == nil {
-			if len(os.Args) > i {
-				return os.Args[i], nil
-			}
-			return "", nil
-		}
+func WithExpandWindowsVars(expand bool) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.ExpandWindowsVars = expand
 	}
+}
 
-	if !isValidBashVariable([]rune(key)) {
-		return "", errors.New("invalid bash variable syntax: invalid variable name")
+func WithCommandSubstitution(enable bool) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.CommandSubstitution = enable
 	}
+}
 
-	value := o.Get(key)
-	if len(value) == 0 {
-		if len(defaultValue) > 0 {
-			if strings.Contains(defaultValue, "$") {
-				next, err := ExpandWithOptions(defaultValue, o)
-				if err != nil {
-					return "", err
-				}
-				return next, nil
-			}
-
-			return defaultValue, nil
-		}
-
-		if len(message) > 0 {
-			return "", errors.New(message)
-		}
-
-		return "", nil
+func WithEnableShellExpansion(enable bool) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.EnableShellExpansion = enable
 	}
-
-	return value, nil
 }
 
-func split(s string, value string) []string {
-	index := strings.Index(s, value)
-	if index == -1 {
-		return []string{s}
+// WithCommand sets the function that runs command substitutions.
+func WithCommand(f func(expression string) (string, error)) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.Command = f
 	}
+}
 
-	first := s[:index]
-	second := s[index+(len(value)):]
-	if first == "" {
-		return []string{second}
-	}
-	if second == "" {
-		return []string{first}
+func WithShell(shell string) ExpandOption {
+	return func(o *ExpandOptions) {
+		o.UseShell = shell
 	}
-	return []string{first, second}
 }
 
 func isLetterOrDigit(r rune) bool {
@@ -199,16 +183,53 @@ func isValidBashVariable(input []rune) bool {
 	return true
 }
 
+// ExpandWithOptions expands the variables, and command substitutions
+// when enabled, of the input. It never reads or changes the process
+// environment on its own: variables come from Get or Lookup, and
+// assignments go to Set or, when Set is nil, last for the expansion.
 func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
-	if options.Get == nil {
-		options.Get = Get
+	// the options are copied so that callers can share them between
+	// expansions that run in parallel.
+	o := *options
+	if o.Lookup == nil {
+		get := o.Get
+		o.Lookup = func(key string) (string, bool) {
+			if get == nil {
+				return "", false
+			}
+			value := get(key)
+			return value, value != ""
+		}
 	}
 
-	if options.Set == nil {
-		options.Set = Set
+	if o.Set == nil {
+		assigned := map[string]string{}
+		lookup := o.Lookup
+		o.Lookup = func(key string) (string, bool) {
+			if value, ok := assigned[key]; ok {
+				return value, true
+			}
+			return lookup(key)
+		}
+		o.Get = nil
+		o.Set = func(key, value string) error {
+			assigned[key] = value
+			return nil
+		}
 	}
 
-	o := options
+	if o.Get == nil {
+		lookup := o.Lookup
+		o.Get = func(key string) string {
+			value, _ := lookup(key)
+			return value
+		}
+	}
+
+	return expand(input, &o)
+}
+
+func expand(input string, o *ExpandOptions) (string, error) {
 	kind := none
 	min := rune(0)
 	remaining := len(input)
@@ -325,9 +346,20 @@ func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
 				return "", errors.New("invalid command substitution: empty expression")
 			}
 
+			if o.Command != nil {
+				out, err := o.Command(expression)
+				if err != nil {
+					return "", err
+				}
+
+				output.WriteString(out)
+				kind = none
+				continue
+			}
+
 			if !o.EnableShellExpansion {
 				commandArgs, err := cmdargs.SplitAndExpand(expression, func(s string) (string, error) {
-					return ExpandWithOptions(s, o)
+					return expand(s, o)
 				})
 				if err != nil {
 					return "", fmt.Errorf("command substitution failed to parse: %w", err)
@@ -340,34 +372,12 @@ func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
 				exe := commandArgs.Get(0)
 				commandArgs.RemoveAt(0)
 
-				cmd := exec.Command(exe, commandArgs.ToArray()...)
-				if len(o.Env) > 0 {
-					envVars := os.Environ()
-					for k, v := range o.Env {
-						envVars = append(envVars, fmt.Sprintf("%s=%s", k, v))
-					}
-					cmd.Env = envVars
-				}
-
-				var outb, errb bytes.Buffer
-				cmd.Stdout = &outb
-				cmd.Stderr = &errb
-				err = cmd.Start()
+				out, err := command(o, exe, commandArgs.ToArray()...)
 				if err != nil {
 					return "", err
 				}
 
-				err = cmd.Wait()
-				if err != nil {
-					return "", err
-				}
-
-				ec := cmd.ProcessState.ExitCode()
-				if ec != 0 {
-					return "", errors.New("command substitution failed with exit code " + fmt.Sprintf("%d", ec) + ": " + errb.String())
-				}
-
-				output.WriteString(strings.TrimSpace(outb.String()))
+				output.WriteString(out)
 				kind = none
 				continue
 			}
@@ -417,32 +427,12 @@ func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
 				}
 			}
 
-			shellArgs = append(shellArgs, expression)
-
-			cmd := exec.Command(o.UseShell, shellArgs...)
-			if len(o.Env) > 0 {
-				envVars := os.Environ()
-				for k, v := range o.Env {
-					envVars = append(envVars, fmt.Sprintf("%s=%s", k, v))
-				}
-				cmd.Env = envVars
-			}
-			var outb, errb bytes.Buffer
-			cmd.Stdout = &outb
-			cmd.Stderr = &errb
-			err := cmd.Start()
+			shellArgs = append(shellArgs[:len(shellArgs):len(shellArgs)], expression)
+			out, err := command(o, o.UseShell, shellArgs...)
 			if err != nil {
 				return "", err
 			}
-			err = cmd.Wait()
-			if err != nil {
-				return "", err
-			}
-			ec := cmd.ProcessState.ExitCode()
-			if ec != 0 {
-				return "", errors.New("command substitution failed with exit code " + fmt.Sprintf("%d", ec) + ": " + errb.String())
-			}
-			output.WriteString(strings.TrimSpace(outb.String()))
+			output.WriteString(out)
 			kind = none
 			continue
 		}
@@ -465,13 +455,8 @@ func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
 			}
 
 			if o.ExpandUnixArgs {
-				i, err := strconv.Atoi(key[1:])
-				if err == nil {
-					if len(os.Args) > i {
-						output.WriteString(os.Args[i])
-					} else {
-						output.WriteString("")
-					}
+				if value, _, ok := positional(key, o); ok {
+					output.WriteString(value)
 
 					if shouldAppend {
 						output.WriteRune(c)
@@ -515,10 +500,73 @@ func ExpandWithOptions(input string, options *ExpandOptions) (string, error) {
 	return out, nil
 }
 
+// command runs the command of a command substitution with the
+// environment of the options and returns its trimmed output. It is
+// stopped when the context of the options is done or the timeout
+// passes.
+func command(o *ExpandOptions, name string, args ...string) (string, error) {
+	parent := o.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = o.environ()
+	// children that keep the output open must not outlive the
+	// timeout.
+	cmd.WaitDelay = time.Second
+
+	var outb, errb bytes.Buffer
+	cmd.Stdout = &outb
+	cmd.Stderr = &errb
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return "", fmt.Errorf("command substitution canceled: %w", parent.Err())
+		}
+
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("command substitution timed out after %s: %w", timeout, ctx.Err())
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", errors.New("command substitution failed with exit code " + fmt.Sprintf("%d", exitErr.ExitCode()) + ": " + errb.String())
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(outb.String()), nil
+}
+
+// environ returns the environment of command substitutions.
+func (o *ExpandOptions) environ() []string {
+	environ := []string{}
+	if o.Environ != nil {
+		environ = append(environ, o.Environ()...)
+	}
+
+	for k, v := range o.Env {
+		environ = append(environ, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	return environ
+}
+
+// Expand expands the input with the options. Without WithGet or
+// WithLookup no variables are set; use WithLookup(Lookup) to expand
+// against the process environment, or a Context to expand against a
+// task's environment and arguments.
 func Expand(input string, options ...ExpandOption) (string, error) {
 	ops := &ExpandOptions{
-		Get:                  Get,
-		Set:                  Set,
 		ExpandUnixArgs:       true,
 		ExpandWindowsVars:    false,
 		CommandSubstitution:  false,
@@ -1,12 +1,15 @@
 package env_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/hyprxlabs/run/internal/env"
+	"github.com/hyprxlabs/run/internal/schema"
 )
 
 func TestExpand_BasicVariable(t *testing.T) {
@@ -204,14 +207,247 @@ func TestExpand_BashInterpolationWithMessage(t *testing.T) {
 }
 
 func TestExpand_ExpandUnixArgs(t *testing.T) {
-	origArgs := os.Args
-	defer func() { os.Args = origArgs }()
-	os.Args = []string{"cmd", "first", "second"}
-	out, err := env.Expand("Arg1: ${1}", env.WithExpandUnixArgs(true))
+	out, err := env.Expand("Arg1: ${1}, Arg2: $2, Arg3: ${3:-none}", env.WithArgs("first", "second"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if out != "Arg1: first" {
-		t.Errorf("expected 'Arg1: first', got '%s'", out)
+	if out != "Arg1: first, Arg2: second, Arg3: none" {
+		t.Errorf("expected 'Arg1: first, Arg2: second, Arg3: none', got '%s'", out)
+	}
+}
+
+func TestExpand_NoProcessEnv(t *testing.T) {
+	t.Setenv("RUN_TEST_PROCESS", "process")
+	os.Unsetenv("RUN_TEST_ASSIGNED")
+
+	out, err := env.Expand("${RUN_TEST_PROCESS:-unset} ${RUN_TEST_ASSIGNED:=assigned} $RUN_TEST_ASSIGNED")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "unset assigned assigned" {
+		t.Errorf("expected 'unset assigned assigned', got '%s'", out)
+	}
+	if _, ok := os.LookupEnv("RUN_TEST_ASSIGNED"); ok {
+		t.Error("expected RUN_TEST_ASSIGNED not to be set in the process environment")
+	}
+}
+
+func TestContext_Expand(t *testing.T) {
+	t.Setenv("RUN_TEST_PROCESS", "process")
+
+	vars := schema.NewEnv()
+	vars.Set("NAME", "task")
+	c := env.NewContext(context.Background(), vars, "first")
+
+	out, err := c.Expand("${NAME} ${1} ${RUN_TEST_PROCESS:-unset} ${ASSIGNED:=value}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "task first unset value" {
+		t.Errorf("expected 'task first unset value', got '%s'", out)
+	}
+	if value, _ := vars.Get("ASSIGNED"); value != "value" {
+		t.Errorf("expected ASSIGNED to be 'value', got '%s'", value)
+	}
+}
+
+func TestContext_CommandSubstitution(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found on path, skipping command substitution test")
+	}
+
+	t.Setenv("RUN_TEST_PROCESS", "process")
+
+	vars := schema.NewEnv()
+	vars.Set("NAME", "task")
+	c := env.NewContext(context.Background(), vars)
+
+	out, err := c.Expand("$(echo $NAME ${RUN_TEST_PROCESS:-unset})", env.WithCommandSubstitution(true), env.WithEnableShellExpansion(true), env.WithShell("sh"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "task unset" {
+		t.Errorf("expected 'task unset', got '%s'", out)
+	}
+}
+
+func TestContext_CommandSubstitution_Timeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found on path, skipping command substitution test")
+	}
+
+	c := env.NewContext(context.Background(), schema.NewEnv())
+	c.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := c.Expand("$(sleep 5)", env.WithCommandSubstitution(true), env.WithEnableShellExpansion(true), env.WithShell("sh"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got '%v'", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the substitution to stop at the timeout, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c = env.NewContext(ctx, schema.NewEnv())
+	_, err = c.Expand("$(sleep 5)", env.WithCommandSubstitution(true), env.WithEnableShellExpansion(true), env.WithShell("sh"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled error, got '%v'", err)
+	}
+}
+
+func TestExpand_ParameterExpansion(t *testing.T) {
+	vars := map[string]string{
+		"PATHNAME": "/usr/local/lib/file.tar.gz",
+		"NAME":     "hello world",
+		"MIXED":    "hELLO",
+		"EMPTY":    "",
+		"NUM":      "0123456789",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${#NAME}", "11"},
+		{"${#EMPTY}", "0"},
+		{"${#UNSET}", "0"},
+		{"${PATHNAME#*/}", "usr/local/lib/file.tar.gz"},
+		{"${PATHNAME##*/}", "file.tar.gz"},
+		{"${PATHNAME%.*}", "/usr/local/lib/file.tar"},
+		{"${PATHNAME%%.*}", "/usr/local/lib/file"},
+		{"${PATHNAME#nomatch}", "/usr/local/lib/file.tar.gz"},
+		{"${PATHNAME##*[/.]}", "gz"},
+		{"${NAME/o/0}", "hell0 world"},
+		{"${NAME//o/0}", "hell0 w0rld"},
+		{"${NAME//o}", "hell wrld"},
+		{"${NAME/#hello/bye}", "bye world"},
+		{"${NAME/%world/there}", "hello there"},
+		{"${NAME/#world/there}", "hello world"},
+		{"${NAME// /_}", "hello_world"},
+		{"${NAME//[lo]/x}", "hexxx wxrxd"},
+		{"${NUM:3}", "3456789"},
+		{"${NUM:3:2}", "34"},
+		{"${NUM: -3}", "789"},
+		{"${NUM: -3:2}", "78"},
+		{"${NUM:2:-2}", "234567"},
+		{"${NUM:20}", ""},
+		{"${NUM:0:0}", ""},
+		{"${NAME^^}", "HELLO WORLD"},
+		{"${NAME^}", "Hello world"},
+		{"${MIXED,,}", "hello"},
+		{"${MIXED,}", "hELLO"},
+		{"${NAME^^[lo]}", "heLLO wOrLd"},
+		{"${NAME:+set}", "set"},
+		{"${EMPTY:+set}", ""},
+		{"${UNSET:+set}", ""},
+		{"${NAME+set}", "set"},
+		{"${EMPTY+set}", "set"},
+		{"${UNSET+set}", ""},
+		{"${EMPTY:-default}", "default"},
+		{"${EMPTY-default}", ""},
+		{"${UNSET-default}", "default"},
+		{"${UNSET--x}", "-x"},
+		{"${EMPTY:default}", "default"},
+		{"${NAME:-${EMPTY:-nested}}", "hello world"},
+		{"${UNSET:-${NAME%% *}}", "hello"},
+		{"${NAME/world/${MIXED,,}}", "hello hello"},
+		{"${NUM::2}", "01"},
+		{"${NUM:(-2)}", "89"},
+		{"${NUM:(-4):(2)}", "67"},
+		{"${NAME/#/X}", "Xhello world"},
+		{"${NAME/%/X}", "hello worldX"},
+		{"${NAME//}", "hello world"},
+		{"${NAME/o/\\/}", "hell/ world"},
+		{"${NAME/ /\\\\}", "hello\\world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			out, err := env.Expand(tt.input, env.WithLookup(lookup), env.WithExpandUnixArgs(false))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("expected '%s', got '%s'", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestExpand_ParameterExpansion_Assign(t *testing.T) {
+	vars := map[string]string{"EMPTY": ""}
+	lookup := func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+	set := func(key, value string) error {
+		vars[key] = value
+		return nil
+	}
+
+	tests := []struct {
+		input string
+		want  string
+		key   string
+	}{
+		{"${EMPTY=value}", "", "EMPTY"},
+		{"${EMPTY:=value}", "value", "EMPTY"},
+		{"${UNSET=other}", "other", "UNSET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			out, err := env.Expand(tt.input, env.WithLookup(lookup), env.WithSet(set))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("expected '%s', got '%s'", tt.want, out)
+			}
+			if vars[tt.key] != tt.want {
+				t.Errorf("expected %s to be '%s', got '%s'", tt.key, tt.want, vars[tt.key])
+			}
+		})
+	}
+}
+
+func TestExpand_ParameterExpansion_Errors(t *testing.T) {
+	vars := map[string]string{"EMPTY": "", "NUM": "12345"}
+	lookup := func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"${UNSET?}", "UNSET: parameter not set"},
+		{"${EMPTY:?}", "EMPTY: parameter null or not set"},
+		{"${UNSET:?custom message}", "custom message"},
+		{"${NUM:4:-3}", "NUM: substring expression < 0"},
+		{"${NUM@}", "invalid bash variable syntax: bad substitution '${NUM@}'"},
+		{"${NUM:}", "invalid bash variable syntax: bad substitution '${NUM:}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := env.Expand(tt.input, env.WithLookup(lookup))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("expected error '%s', got '%v'", tt.want, err)
+			}
+		})
+	}
+
+	// ${EMPTY?} only fails for unset variables.
+	out, err := env.Expand("${EMPTY?}", env.WithLookup(lookup))
+	if err != nil || out != "" {
+		t.Errorf("expected empty value, got '%s' (%v)", out, err)
 	}
 }
@@ -0,0 +1,585 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// interpolateVar expands the contents of a ${...} parameter
+// expansion. It supports the bash forms:
+//
+//	${VAR}          value
+//	${#VAR}         length of the value
+//	${VAR:-word}    word when VAR is unset or empty (${VAR-word}: unset)
+//	${VAR:=word}    same as :- and assigns word to VAR
+//	${VAR:?msg}     error when VAR is unset or empty
+//	${VAR:+word}    word when VAR is set and not empty (${VAR+word}: set)
+//	${VAR#pat}      remove the shortest prefix matching pat (## longest)
+//	${VAR%pat}      remove the shortest suffix matching pat (%% longest)
+//	${VAR/pat/rep}  replace the first match (// all, /# prefix, /% suffix)
+//	${VAR:off:len}  substring, negative offsets count from the end
+//	${VAR^^}        uppercase (^ first character, ,, lowercase, , first)
+//
+// ${VAR:word}, where word is not a number, is kept as a shorthand
+// for ${VAR:-word}.
+func interpolateVar(token string, o *ExpandOptions) (string, error) {
+	if len(token) > 1 && token[0] == '#' {
+		value, _, err := lookupParam(token[1:], o)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.Itoa(len([]rune(value))), nil
+	}
+
+	key, op := splitParam(token)
+	colon := strings.HasPrefix(op, ":")
+	if len(key) == 0 {
+		return "", errors.New("invalid bash variable syntax: empty variable name")
+	}
+
+	value, set, err := lookupParam(key, o)
+	if err != nil {
+		return "", err
+	}
+
+	word := func(s string) (string, error) {
+		if strings.ContainsRune(s, '$') {
+			return expand(s, o)
+		}
+		return s, nil
+	}
+
+	switch {
+	case op == "":
+		return value, nil
+
+	case strings.HasPrefix(op, ":-"), strings.HasPrefix(op, "-"):
+		if !set || (colon && value == "") {
+			return word(opWord(op))
+		}
+		return value, nil
+
+	case strings.HasPrefix(op, ":="), strings.HasPrefix(op, "="):
+		if set && !(colon && value == "") {
+			return value, nil
+		}
+
+		if !isValidBashVariable([]rune(key)) {
+			return "", fmt.Errorf("invalid bash variable syntax: cannot assign to '%s'", key)
+		}
+
+		next, err := word(opWord(op))
+		if err != nil {
+			return "", err
+		}
+
+		if err := o.Set(key, next); err != nil {
+			return "", err
+		}
+		return next, nil
+
+	case strings.HasPrefix(op, ":?"), strings.HasPrefix(op, "?"):
+		if set && !(colon && value == "") {
+			return value, nil
+		}
+
+		message, err := word(opWord(op))
+		if err != nil {
+			return "", err
+		}
+
+		if message == "" {
+			if colon {
+				return "", fmt.Errorf("%s: parameter null or not set", key)
+			}
+			return "", fmt.Errorf("%s: parameter not set", key)
+		}
+		return "", errors.New(message)
+
+	case strings.HasPrefix(op, ":+"), strings.HasPrefix(op, "+"):
+		if !set || (colon && value == "") {
+			return "", nil
+		}
+		return word(opWord(op))
+
+	case strings.HasPrefix(op, "#"), strings.HasPrefix(op, "%"):
+		longest := len(op) > 1 && op[1] == op[0]
+		raw := op[1:]
+		if longest {
+			raw = op[2:]
+		}
+
+		pattern, err := word(raw)
+		if err != nil {
+			return "", err
+		}
+
+		if op[0] == '#' {
+			return trimPrefix(value, pattern, longest), nil
+		}
+		return trimSuffix(value, pattern, longest), nil
+
+	case strings.HasPrefix(op, "/"):
+		return replaceParam(value, op[1:], word)
+
+	case strings.HasPrefix(op, "^"), strings.HasPrefix(op, ","):
+		return caseParam(value, op, word)
+
+	case strings.HasPrefix(op, ":"):
+		if op == ":" {
+			break
+		}
+
+		if offset, length, ok := parseSubstring(op[1:]); ok {
+			return substring(key, value, offset, length)
+		}
+
+		// ${VAR:word} is a shorthand for ${VAR:-word}.
+		if value == "" {
+			return word(op[1:])
+		}
+		return value, nil
+	}
+
+	return "", fmt.Errorf("invalid bash variable syntax: bad substitution '${%s}'", token)
+}
+
+// splitParam splits the contents of ${...} into the parameter name
+// and the operator with its words.
+func splitParam(token string) (string, string) {
+	runes := []rune(token)
+	if len(runes) > 0 && unicode.IsDigit(runes[0]) {
+		// positional parameters consist of digits only, but names
+		// such as 1FOO are kept whole so they are rejected later.
+		i := 0
+		for i < len(runes) && unicode.IsDigit(runes[i]) {
+			i++
+		}
+
+		if i == len(runes) || !(isLetterOrDigit(runes[i]) || runes[i] == '_') {
+			return string(runes[:i]), string(runes[i:])
+		}
+	}
+
+	i := 0
+	for i < len(runes) && (isLetterOrDigit(runes[i]) || runes[i] == '_') {
+		i++
+	}
+
+	return string(runes[:i]), string(runes[i:])
+}
+
+// lookupParam returns the value of the variable or positional
+// parameter and whether it is set.
+func lookupParam(key string, o *ExpandOptions) (string, bool, error) {
+	if o.ExpandUnixArgs {
+		if value, set, ok := positional(key, o); ok {
+			return value, set, nil
+		}
+	}
+
+	if !isValidBashVariable([]rune(key)) {
+		return "", false, errors.New("invalid bash variable syntax: invalid variable name")
+	}
+
+	value, ok := o.Lookup(key)
+	return value, ok, nil
+}
+
+// positional returns the positional parameter the key names and
+// whether it is set, with ok false when the key is not a number.
+func positional(key string, o *ExpandOptions) (value string, set bool, ok bool) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 {
+		return "", false, false
+	}
+
+	if i == 0 || i > len(o.Args) {
+		return "", false, true
+	}
+
+	return o.Args[i-1], true, true
+}
+
+// trimPrefix removes the shortest, or longest, prefix of value that
+// matches the pattern.
+func trimPrefix(value string, pattern string, longest bool) string {
+	runes := []rune(value)
+	if longest {
+		for i := len(runes); i >= 0; i-- {
+			if matchPattern(pattern, string(runes[:i])) {
+				return string(runes[i:])
+			}
+		}
+		return value
+	}
+
+	for i := 0; i <= len(runes); i++ {
+		if matchPattern(pattern, string(runes[:i])) {
+			return string(runes[i:])
+		}
+	}
+	return value
+}
+
+// trimSuffix removes the shortest, or longest, suffix of value that
+// matches the pattern.
+func trimSuffix(value string, pattern string, longest bool) string {
+	runes := []rune(value)
+	if longest {
+		for i := 0; i <= len(runes); i++ {
+			if matchPattern(pattern, string(runes[i:])) {
+				return string(runes[:i])
+			}
+		}
+		return value
+	}
+
+	for i := len(runes); i >= 0; i-- {
+		if matchPattern(pattern, string(runes[i:])) {
+			return string(runes[:i])
+		}
+	}
+	return value
+}
+
+// replaceParam handles pat/rep, /pat/rep, #pat/rep and %pat/rep,
+// the part of ${VAR/pat/rep} after the first slash.
+func replaceParam(value string, spec string, word func(string) (string, error)) (string, error) {
+	mode := byte(0)
+	if len(spec) > 0 && (spec[0] == '/' || spec[0] == '#' || spec[0] == '%') {
+		mode = spec[0]
+		spec = spec[1:]
+	}
+
+	rawPattern, rawReplacement, _ := cutUnescaped(spec, '/')
+	pattern, err := word(rawPattern)
+	if err != nil {
+		return "", err
+	}
+
+	replacement, err := word(unescape(rawReplacement))
+	if err != nil {
+		return "", err
+	}
+
+	// an empty pattern matches nothing, except at the start or the
+	// end of the value with the # and % anchors.
+	if pattern == "" && mode != '#' && mode != '%' {
+		return value, nil
+	}
+
+	runes := []rune(value)
+	switch mode {
+	case '#':
+		for j := len(runes); j >= 0; j-- {
+			if matchPattern(pattern, string(runes[:j])) {
+				return replacement + string(runes[j:]), nil
+			}
+		}
+		return value, nil
+	case '%':
+		for i := 0; i <= len(runes); i++ {
+			if matchPattern(pattern, string(runes[i:])) {
+				return string(runes[:i]) + replacement, nil
+			}
+		}
+		return value, nil
+	}
+
+	var sb strings.Builder
+	i := 0
+	replaced := false
+	for i < len(runes) {
+		if replaced && mode != '/' {
+			break
+		}
+
+		// the longest match starting at i.
+		end := -1
+		for j := len(runes); j > i; j-- {
+			if matchPattern(pattern, string(runes[i:j])) {
+				end = j
+				break
+			}
+		}
+
+		if end < 0 {
+			sb.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		sb.WriteString(replacement)
+		i = end
+		replaced = true
+	}
+
+	sb.WriteString(string(runes[i:]))
+	return sb.String(), nil
+}
+
+// caseParam handles ^^, ^, ,, and , with an optional pattern that
+// limits which characters change.
+func caseParam(value string, op string, word func(string) (string, error)) (string, error) {
+	upper := op[0] == '^'
+	all := len(op) > 1 && op[1] == op[0]
+	rest := op[1:]
+	if all {
+		rest = op[2:]
+	}
+
+	pattern, err := word(rest)
+	if err != nil {
+		return "", err
+	}
+
+	if pattern == "" {
+		pattern = "?"
+	}
+
+	runes := []rune(value)
+	for i, r := range runes {
+		if !all && i > 0 {
+			break
+		}
+
+		if !matchPattern(pattern, string(r)) {
+			continue
+		}
+
+		if upper {
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	return string(runes), nil
+}
+
+// unescape removes the backslash before the characters of a word,
+// such as \/ in the replacement of ${VAR/pat/rep}. \$ is kept for
+// the expansion of the word to unescape.
+func unescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] != '$' {
+			i++
+		}
+		sb.WriteRune(runes[i])
+	}
+
+	return sb.String()
+}
+
+// parseSubstring parses the offset and optional length of
+// ${VAR:offset:length}. The length is nil when missing. An empty
+// offset is 0, and either may be in parentheses, e.g. ${VAR:(-2)}.
+func parseSubstring(spec string) (int, *int, bool) {
+	offsetPart, lengthPart, hasLength := strings.Cut(spec, ":")
+	offset, ok := parseIndex(offsetPart, hasLength)
+	if !ok {
+		return 0, nil, false
+	}
+
+	if !hasLength {
+		return offset, nil, true
+	}
+
+	length, ok := parseIndex(lengthPart, true)
+	if !ok {
+		return 0, nil, false
+	}
+
+	return offset, &length, true
+}
+
+// parseIndex parses an offset or length of a substring, which may be
+// empty when allowEmpty is set.
+func parseIndex(s string, allowEmpty bool) (int, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	if s == "" {
+		return 0, allowEmpty
+	}
+
+	i, err := strconv.Atoi(s)
+	return i, err == nil
+}
+
+func substring(key string, value string, offset int, length *int) (string, error) {
+	runes := []rune(value)
+	n := len(runes)
+
+	if offset < 0 {
+		offset += n
+		if offset < 0 {
+			return "", nil
+		}
+	}
+
+	if offset > n {
+		return "", nil
+	}
+
+	end := n
+	if length != nil {
+		if *length < 0 {
+			// negative lengths count back from the end of the value.
+			end = n + *length
+			if end < offset {
+				return "", fmt.Errorf("%s: substring expression < 0", key)
+			}
+		} else if offset+*length < end {
+			end = offset + *length
+		}
+	}
+
+	return string(runes[offset:end]), nil
+}
+
+// opWord returns the word of the -, =, ? and + operators, with or
+// without the leading colon.
+func opWord(op string) string {
+	if strings.HasPrefix(op, ":") {
+		return op[2:]
+	}
+	return op[1:]
+}
+
+// cutUnescaped splits s around the first sep that is neither
+// escaped with a backslash nor inside a nested ${...}.
+func cutUnescaped(s string, sep rune) (string, string, bool) {
+	runes := []rune(s)
+	depth := 0
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				return string(runes[:i]), string(runes[i+1:]), true
+			}
+		}
+	}
+
+	return s, "", false
+}
+
+// matchPattern reports whether the whole of s matches the shell
+// pattern. Unlike path.Match, '*' also matches '/'.
+func matchPattern(pattern string, s string) bool {
+	return matchRunes([]rune(pattern), []rune(s))
+}
+
+func matchRunes(p []rune, s []rune) bool {
+	for len(p) > 0 {
+		switch p[0] {
+		case '*':
+			for len(p) > 0 && p[0] == '*' {
+				p = p[1:]
+			}
+			if len(p) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchRunes(p, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			p, s = p[1:], s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			matched, rest, ok := matchClass(p, s[0])
+			if !ok {
+				// an unterminated class matches a literal '['.
+				if s[0] != '[' {
+					return false
+				}
+				p, s = p[1:], s[1:]
+				continue
+			}
+			if !matched {
+				return false
+			}
+			p, s = rest, s[1:]
+		case '\\':
+			if len(p) > 1 {
+				p = p[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || p[0] != s[0] {
+				return false
+			}
+			p, s = p[1:], s[1:]
+		}
+	}
+
+	return len(s) == 0
+}
+
+// matchClass matches r against the bracket expression at the start
+// of p and returns the pattern after it.
+func matchClass(p []rune, r rune) (bool, []rune, bool) {
+	i := 1
+	negate := false
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		negate = true
+		i++
+	}
+
+	matched := false
+	first := true
+	for i < len(p) {
+		if p[i] == ']' && !first {
+			return matched != negate, p[i+1:], true
+		}
+		first = false
+
+		lo := p[i]
+		if lo == '\\' && i+1 < len(p) {
+			i++
+			lo = p[i]
+		}
+		i++
+
+		hi := lo
+		if i+1 < len(p) && p[i] == '-' && p[i+1] != ']' {
+			hi = p[i+1]
+			i += 2
+		}
+
+		if lo <= r && r <= hi {
+			matched = true
+		}
+	}
+
+	return false, nil, false
+}
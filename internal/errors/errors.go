@@ -99,6 +99,12 @@ func (e *Error) Cause() error {
 	return stderrors.New(e.Message)
 }
 
+// Unwrap returns the cause of the error, which lets errors.Is and
+// errors.As look through it.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
 func (e *Error) Details() string {
 	if e.details != "" {
 		return e.details
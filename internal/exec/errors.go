@@ -0,0 +1,83 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+)
+
+// StartError is returned when a command cannot be started, e.g.
+// because the executable does not exist or is not executable. It
+// tells spawn failures apart from commands that ran and failed.
+type StartError struct {
+	Path string
+	Err  error
+}
+
+func (e *StartError) Error() string {
+	return fmt.Sprintf("failed to start %s: %v", e.Path, e.Err)
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// NotFound reports whether the executable does not exist.
+func (e *StartError) NotFound() bool {
+	return errors.Is(e.Err, exec.ErrNotFound) || errors.Is(e.Err, fs.ErrNotExist)
+}
+
+// ExitCode returns the code a shell uses for the failure: 127 when
+// the executable was not found and 126 when it cannot be run.
+func (e *StartError) ExitCode() int {
+	if e.NotFound() {
+		return 127
+	}
+
+	return 126
+}
+
+// ExitError reports a command that ran and exited with a non-zero
+// code or was killed by a signal. The result holds the exit status
+// and the output that was captured.
+type ExitError struct {
+	Result *Result
+}
+
+func (e *ExitError) Error() string {
+	if e.Result.Signal != "" {
+		return fmt.Sprintf("command %s was killed by %s", e.Result.FileName, e.Result.Signal)
+	}
+
+	return fmt.Sprintf("command %s failed with code %d", e.Result.FileName, e.Result.Code)
+}
+
+// ExitCode returns the exit code of the command, which is 128 plus
+// the signal number for a command killed by a signal.
+func (e *ExitError) ExitCode() int {
+	return e.Result.Code
+}
+
+// ExitCode returns the exit code carried by err or any error it
+// wraps: the code of an ExitError, a StartError or an exec.ExitError
+// from the standard library.
+func ExitCode(err error) (int, bool) {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+
+	var startErr *StartError
+	if errors.As(err, &startErr) {
+		return startErr.ExitCode(), true
+	}
+
+	var stdErr *exec.ExitError
+	if errors.As(err, &stdErr) {
+		code, _ := exitStatus(stdErr.ProcessState)
+		return code, true
+	}
+
+	return 0, false
+}
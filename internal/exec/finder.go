@@ -3,6 +3,7 @@ package exec
 import (
 	"errors"
 	"runtime"
+	"sort"
 	"unicode"
 
 	"github.com/hyprxlabs/run/internal/env"
@@ -12,30 +13,124 @@ type Executable struct {
 	Name     string
 	Path     string
 	Variable string
-	Windows  []string
-	Linux    []string
-	Darwin   []string
+
+	// Deprecated is the variable that overrode the path before
+	// Variable, XTASK_<NAME>_EXE. It is still read after Variable.
+	Deprecated string
+
+	Windows []string
+	Linux   []string
+	Darwin  []string
+
+	// Arch holds the search paths for an architecture, keyed by
+	// platform and architecture, e.g. linux/arm64, or only by
+	// architecture, e.g. arm64. They are searched before the paths
+	// of the platform.
+	Arch map[string][]string
+}
+
+// Candidate is a path Find considers for an executable.
+type Candidate struct {
+	// Source is where the candidate comes from: the variable that
+	// overrides the executable, 'path', or the search paths of a
+	// platform or architecture, e.g. linux/arm64.
+	Source string
+
+	// Path is the candidate after expanding variables and globs.
+	Path string
+
+	// Resolved is the executable the candidate resolves to, empty
+	// when it does not resolve.
+	Resolved string
+
+	// Reason explains why the candidate did not resolve.
+	Reason string
+
+	// Deprecated is set for the candidate of a deprecated variable.
+	Deprecated bool
 }
 
 type ExecutableRegistry struct {
 	data map[string]Executable
 }
 
-var Registry = &ExecutableRegistry{data: make(map[string]Executable)}
+var Registry = NewRegistry()
 
-func (r *ExecutableRegistry) Register(name string, exe *Executable) {
-	r.data[name] = *exe
+// NewRegistry returns an empty registry, for executables that should
+// not be registered for the whole process.
+func NewRegistry() *ExecutableRegistry {
+	return &ExecutableRegistry{data: make(map[string]Executable)}
+}
 
+// Variable returns the name of the variable that overrides the path
+// of the executable registered under name, RUN_<NAME>_EXE.
+func Variable(name string) string {
+	return "RUN_" + string(underscore([]rune(name), &underscoreOptions{Screaming: true})) + "_EXE"
+}
+
+// DeprecatedVariable returns the name of the variable that
+// overrode the path of the executable before Variable,
+// XTASK_<NAME>_EXE.
+func DeprecatedVariable(name string) string {
+	return "XTASK_" + string(underscore([]rune(name), &underscoreOptions{Screaming: true})) + "_EXE"
+}
+
+func (r *ExecutableRegistry) Register(name string, exe *Executable) {
 	if exe.Variable == "" {
-		sb := underscore([]rune(name), &underscoreOptions{Screaming: true})
-		exe.Variable = string(sb)
+		exe.Variable = Variable(name)
+	}
+
+	if exe.Deprecated == "" {
+		exe.Deprecated = DeprecatedVariable(name)
 	}
+
+	r.data[name] = *exe
 }
 
 func (r *ExecutableRegistry) Set(name string, exe *Executable) {
 	r.data[name] = *exe
 }
 
+// Override puts the path and search paths of exe in front of the
+// ones of the executable registered under name, or registers exe
+// when there is none. The variable of the executable is kept.
+func (r *ExecutableRegistry) Override(name string, exe *Executable) {
+	m, ok := r.data[name]
+	if !ok {
+		r.Register(name, exe)
+		return
+	}
+
+	prepend := func(first, then []string) []string {
+		return append(append([]string{}, first...), then...)
+	}
+
+	if exe.Name != "" {
+		m.Name = exe.Name
+	}
+
+	if exe.Path != "" {
+		m.Path = exe.Path
+	}
+
+	m.Windows = prepend(exe.Windows, m.Windows)
+	m.Linux = prepend(exe.Linux, m.Linux)
+	m.Darwin = prepend(exe.Darwin, m.Darwin)
+
+	if len(exe.Arch) > 0 {
+		arch := map[string][]string{}
+		for key, paths := range m.Arch {
+			arch[key] = paths
+		}
+		for key, paths := range exe.Arch {
+			arch[key] = prepend(paths, arch[key])
+		}
+		m.Arch = arch
+	}
+
+	r.data[name] = m
+}
+
 func (r *ExecutableRegistry) Get(name string) (*Executable, bool) {
 	item, ok := r.data[name]
 	return &item, ok
@@ -46,14 +141,25 @@ func (r *ExecutableRegistry) Has(name string) bool {
 	return ok
 }
 
-func (r *ExecutableRegistry) Find(name string, options *WhichOptions) (string, error) {
-	m, ok := r.data[name]
-	if !ok {
-		sb := underscore([]rune(name), &underscoreOptions{Screaming: true})
-		m = Executable{Name: name}
-		m.Variable = string(sb)
-		r.data[name] = m
+// Names returns the names of the registered executables, sorted.
+func (r *ExecutableRegistry) Names() []string {
+	names := make([]string, 0, len(r.data))
+	for name := range r.data {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Find returns the first candidate that resolves to an executable:
+// the path in the variable of the executable, the one in its
+// deprecated variable, its path, the search paths of the
+// architecture and then those of the platform. Search paths may be
+// globs, whose matches are tried from the highest version to the
+// lowest.
+func (r *ExecutableRegistry) Find(name string, options *WhichOptions) (string, error) {
+	m := r.lookup(name)
 
 	if options == nil {
 		options = &WhichOptions{}
@@ -63,88 +169,160 @@ func (r *ExecutableRegistry) Find(name string, options *WhichOptions) (string, e
 		return m.Path, nil
 	}
 
+	found := ""
+	m.candidates(func(c *Candidate) bool {
+		if resolve(c, options) {
+			found = c.Resolved
+			return false
+		}
+		return true
+	})
+
+	if found == "" {
+		return "", errors.New("executable not found: " + name)
+	}
+
+	return found, nil
+}
+
+// Explain returns every candidate Find considers for the executable,
+// in order, and the one Find picks, the first that resolves.
+func (r *ExecutableRegistry) Explain(name string, options *WhichOptions) ([]Candidate, string) {
+	m := r.lookup(name)
+
+	candidates := []Candidate{}
+	found := ""
+	m.candidates(func(c *Candidate) bool {
+		if resolve(c, options) && found == "" {
+			found = c.Resolved
+		}
+		candidates = append(candidates, *c)
+		return true
+	})
+
+	return candidates, found
+}
+
+// lookup returns the executable registered under name, registering
+// one without search paths when there is none.
+func (r *ExecutableRegistry) lookup(name string) Executable {
+	m, ok := r.data[name]
+	if !ok {
+		m = Executable{Name: name, Variable: Variable(name), Deprecated: DeprecatedVariable(name)}
+		r.data[name] = m
+	}
+
+	return m
+}
+
+// searchList is a list of search paths and where it comes from.
+type searchList struct {
+	source string
+	paths  []string
+}
+
+// candidates calls yield with each candidate of the executable in the
+// order they are searched until yield returns false. Candidates that
+// cannot resolve, such as an unset variable, carry the reason.
+func (m *Executable) candidates(yield func(c *Candidate) bool) {
 	if m.Variable != "" {
+		c := &Candidate{Source: m.Variable}
 		value := env.Get(m.Variable)
 		if value != "" {
-			value, _ = env.Expand(value)
-			if value != "" {
-				next, ok := WhichFirst(value, options)
-				if ok {
-					m.Path = next
-					return m.Path, nil
-				}
-			}
+			value = expandPath(value)
+		}
+		c.Path = value
+		if value == "" {
+			c.Reason = "not set"
+		}
+		if !yield(c) {
+			return
 		}
 	}
 
-	if m.Path != "" {
-		next, ok := WhichFirst(m.Path, options)
-		if ok {
-			m.Path = next
-			return m.Path, nil
+	// the deprecated variable is only a candidate while it is set.
+	if m.Deprecated != "" {
+		if value := env.Get(m.Deprecated); value != "" {
+			c := &Candidate{Source: m.Deprecated, Path: expandPath(value), Deprecated: true}
+			if !yield(c) {
+				return
+			}
 		}
 	}
 
-	if runtime.GOOS == "windows" {
-		for _, path := range m.Windows {
+	if m.Path != "" && !yield(&Candidate{Source: "path", Path: m.Path}) {
+		return
+	}
+
+	platform := runtime.GOOS
+	lists := []searchList{
+		{platform + "/" + runtime.GOARCH, m.Arch[platform+"/"+runtime.GOARCH]},
+		{runtime.GOARCH, m.Arch[runtime.GOARCH]},
+	}
+
+	switch platform {
+	case "windows":
+		lists = append(lists, searchList{"windows", m.Windows})
+	case "darwin":
+		// darwin falls back to the paths of linux.
+		lists = append(lists, searchList{"darwin", m.Darwin}, searchList{"linux", m.Linux})
+	default:
+		lists = append(lists, searchList{"linux", m.Linux})
+	}
+
+	for _, list := range lists {
+		for _, path := range list.paths {
 			if emptySpace(path) {
 				continue
 			}
 
-			exe2, _ := env.Expand(path)
-			if exe2 == "" {
+			expanded := expandPath(path)
+			if expanded == "" {
+				if !yield(&Candidate{Source: list.source, Path: path, Reason: "empty after expanding variables"}) {
+					return
+				}
 				continue
 			}
 
-			next, ok := WhichFirst(exe2, options)
-			if ok {
-				m.Path = next
-				return m.Path, nil
-			}
-		}
-
-		return "", errors.New("executable not found: " + name)
-	}
-
-	if runtime.GOOS == "darwin" {
-		for _, path := range m.Darwin {
-			if emptySpace(path) {
+			if !isGlob(expanded) {
+				if !yield(&Candidate{Source: list.source, Path: expanded}) {
+					return
+				}
 				continue
 			}
 
-			exe2, _ := env.Expand(path)
-			if exe2 == "" {
+			matches := Glob(expanded)
+			if len(matches) == 0 {
+				if !yield(&Candidate{Source: list.source, Path: expanded, Reason: "no files match"}) {
+					return
+				}
 				continue
 			}
 
-			next, ok := WhichFirst(exe2, options)
-			if ok {
-				m.Path = next
-				return m.Path, nil
+			for _, match := range matches {
+				if !yield(&Candidate{Source: list.source, Path: match}) {
+					return
+				}
 			}
 		}
-
-		// fallthrough to unix
 	}
+}
 
-	for _, path := range m.Linux {
-		if emptySpace(path) {
-			continue
-		}
-
-		exe2, _ := env.Expand(path)
-		if exe2 == "" {
-			continue
-		}
+// resolve looks up the path of the candidate, setting Resolved or the
+// reason it does not resolve, and reports whether it resolved.
+func resolve(c *Candidate, options *WhichOptions) bool {
+	if c.Reason != "" {
+		return false
+	}
 
-		next, ok := WhichFirst(exe2, options)
-		if ok {
-			m.Path = next
-			return m.Path, nil
-		}
+	next, ok := WhichFirst(c.Path, options)
+	if !ok {
+		c.Reason = "not found"
+		return false
 	}
 
-	return "", errors.New("executable not found: " + name)
+	c.Resolved = next
+	return true
 }
 
 func Register(name string, exe *Executable) {
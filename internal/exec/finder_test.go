@@ -0,0 +1,112 @@
+package exec_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExecutable(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+}
+
+func TestVariable(t *testing.T) {
+	assert.Equal(t, "RUN_BASH_EXE", exec.Variable("bash"))
+	assert.Equal(t, "RUN_NODE_LTS_EXE", exec.Variable("node-lts"))
+
+	registry := exec.NewRegistry()
+	registry.Register("pwsh", &exec.Executable{Name: "pwsh"})
+	exe, _ := registry.Get("pwsh")
+	assert.Equal(t, "RUN_PWSH_EXE", exe.Variable)
+}
+
+func TestRegistry_DeprecatedVariable(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old", "tool")
+	current := filepath.Join(dir, "new", "tool")
+	writeExecutable(t, old)
+	writeExecutable(t, current)
+
+	registry := exec.NewRegistry()
+	registry.Register("tool", &exec.Executable{Name: "tool"})
+	exe, _ := registry.Get("tool")
+	assert.Equal(t, "XTASK_TOOL_EXE", exe.Deprecated)
+
+	// unset, the deprecated variable is not a candidate.
+	candidates, _ := registry.Explain("tool", nil)
+	for _, c := range candidates {
+		assert.False(t, c.Deprecated)
+	}
+
+	t.Setenv("XTASK_TOOL_EXE", old)
+	candidates, found := registry.Explain("tool", nil)
+	assert.Equal(t, old, found)
+	assert.Equal(t, exec.Candidate{Source: "XTASK_TOOL_EXE", Path: old, Resolved: old, Deprecated: true}, candidates[1])
+
+	// the new variable wins over the deprecated one.
+	t.Setenv("RUN_TOOL_EXE", current)
+	path, err := registry.Find("tool", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, current, path)
+}
+
+func TestGlob_VersionOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"jdk-8", "jdk-17", "jdk-21.0.2", "jdk-21.0.10"} {
+		writeExecutable(t, filepath.Join(dir, version, "bin", "java"))
+	}
+
+	matches := exec.Glob(filepath.Join(dir, "*", "bin", "java"))
+	names := []string{}
+	for _, match := range matches {
+		names = append(names, filepath.Base(filepath.Dir(filepath.Dir(match))))
+	}
+	assert.Equal(t, []string{"jdk-21.0.10", "jdk-21.0.2", "jdk-17", "jdk-8"}, names)
+}
+
+func TestRegistry_Explain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("search paths of linux")
+	}
+
+	dir := t.TempDir()
+	writeExecutable(t, filepath.Join(dir, "tool-1.9", "tool"))
+	writeExecutable(t, filepath.Join(dir, "tool-1.10", "tool"))
+	writeExecutable(t, filepath.Join(dir, "arch", "tool"))
+
+	registry := exec.NewRegistry()
+	registry.Register("tool", &exec.Executable{
+		Name:   "tool",
+		Linux:  []string{filepath.Join(dir, "missing", "tool"), filepath.Join(dir, "tool-*", "tool")},
+		Darwin: []string{filepath.Join(dir, "none-*", "tool")},
+	})
+
+	candidates, found := registry.Explain("tool", nil)
+	assert.Equal(t, filepath.Join(dir, "tool-1.10", "tool"), found)
+	assert.Equal(t, exec.Candidate{Source: "RUN_TOOL_EXE", Reason: "not set"}, candidates[0])
+
+	last := candidates[len(candidates)-1]
+	assert.Equal(t, filepath.Join(dir, "tool-1.9", "tool"), last.Resolved)
+
+	registry.Override("tool", &exec.Executable{
+		Arch: map[string][]string{runtime.GOARCH: {filepath.Join(dir, "arch", "tool")}},
+	})
+	path, err := registry.Find("tool", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "arch", "tool"), path)
+
+	t.Setenv("RUN_TOOL_EXE", filepath.Join(dir, "tool-1.9", "tool"))
+	path, err = registry.Find("tool", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "tool-1.9", "tool"), path)
+
+	_, err = registry.Find("missing", nil)
+	assert.EqualError(t, err, "executable not found: missing")
+}
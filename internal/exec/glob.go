@@ -0,0 +1,71 @@
+package exec
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isGlob reports whether the path has glob patterns.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Glob returns the files matching the pattern, the highest version
+// first, so /usr/lib/jvm/*/bin/java finds java-21 before java-17 and
+// java-17 before java-8.
+func Glob(pattern string) []string {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return compareVersions(matches[i], matches[j]) > 0
+	})
+
+	return matches
+}
+
+// compareVersions compares a and b with their runs of digits compared
+// as numbers, so 'jdk-17' sorts after 'jdk-8' and 'python3.12' after
+// 'python3.9'.
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		da, ra := leadingDigits(a)
+		db, rb := leadingDigits(b)
+		if da != "" && db != "" {
+			na, _ := strconv.ParseUint(strings.TrimLeft(da, "0"), 10, 64)
+			nb, _ := strconv.ParseUint(strings.TrimLeft(db, "0"), 10, 64)
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			a, b = ra, rb
+			continue
+		}
+
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+
+	return len(a) - len(b)
+}
+
+// leadingDigits splits s into its leading digits and the rest.
+func leadingDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
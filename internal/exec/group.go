@@ -0,0 +1,144 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWaitDelay is how long a canceled command gets to exit after
+// it was interrupted before it is killed.
+const DefaultWaitDelay = 5 * time.Second
+
+var (
+	// forwarded lists the signals that are passed on to the process
+	// group of a running command.
+	forwarded = []os.Signal{}
+
+	// terminate is sent to the process group of a canceled command
+	// that did not receive a forwarded signal.
+	terminate = os.Kill
+)
+
+// group tracks the process group of a started command.
+type group struct {
+	signals chan os.Signal
+	done    chan struct{}
+
+	// signaled is set once a signal was forwarded to the group.
+	signaled atomic.Bool
+
+	// tty is the terminal the group was given as its foreground
+	// process group, which is handed back when the command exits.
+	tty *os.File
+}
+
+// newGroup returns the group of a command that is about to start.
+// The forwarded signals are caught from here on, so a signal that
+// arrives while the command starts is still passed on, and the
+// channels are in place before a canceled context reads them.
+func newGroup() *group {
+	g := &group{}
+	if len(forwarded) > 0 {
+		g.signals = make(chan os.Signal, len(forwarded))
+		g.done = make(chan struct{})
+		signal.Notify(g.signals, forwarded...)
+	}
+
+	return g
+}
+
+// forward passes the signals the current process receives on to the
+// process group of the command until the command exits.
+func (c *Cmd) forward() {
+	g := c.group
+	if g.signals == nil {
+		return
+	}
+
+	signals, done := g.signals, g.done
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				g.signaled.Store(true)
+				c.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// SignalError is the cause of a context canceled by NotifyContext.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return "received " + e.Signal.String()
+}
+
+// NotifyContext is like signal.NotifyContext, but cancels the
+// context with a SignalError as its cause, which tells the commands
+// that a signal forwarded to them canceled it.
+func NotifyContext(parent context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		select {
+		case sig := <-ch:
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		cancel(nil)
+	}
+}
+
+// interrupt asks the process group of a command to exit when ctx is
+// done. A signal forwarded to the group, such as the SIGINT that
+// canceled a context of NotifyContext, is left to take effect,
+// otherwise the group receives terminate. The command is killed if
+// it is still running after its WaitDelay.
+func (c *Cmd) interrupt(ctx context.Context) error {
+	g := c.group
+	if g == nil {
+		return c.Signal(terminate)
+	}
+
+	// the signal reaches the group through forward as well.
+	var sigErr *SignalError
+	if g.signals != nil && errors.As(context.Cause(ctx), &sigErr) && slices.Contains(forwarded, sigErr.Signal) {
+		return nil
+	}
+
+	if g.signaled.Load() {
+		return nil
+	}
+
+	return c.Signal(terminate)
+}
+
+// release stops forwarding signals after the command exits, or
+// fails to start, and cleans up the processes left in the group.
+func (c *Cmd) release() {
+	g := c.group
+	if g.done != nil {
+		signal.Stop(g.signals)
+		close(g.done)
+		g.done = nil
+	}
+
+	killStragglers(c)
+	restoreTerminal(g)
+}
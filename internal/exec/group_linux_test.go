@@ -0,0 +1,238 @@
+package exec_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	ose "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/stretchr/testify/assert"
+)
+
+// tree starts three sleeps below a shell, two of them grandchildren.
+const tree = "sleep 100 & sleep 100 & sleep 100; wait"
+
+// nested starts a sleep below two shells. Unlike background jobs,
+// which a non-interactive shell starts with SIGINT ignored, every
+// process of the tree can be interrupted.
+const nested = "sh -c 'sleep 100; :'; :"
+
+// groupMembers returns the live processes of the process group by
+// reading /proc. Zombies are left out as they only wait to be reaped.
+func groupMembers(t *testing.T, pgid int) []int {
+	t.Helper()
+
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	members := []int{}
+	for _, stat := range stats {
+		data, err := os.ReadFile(stat)
+		if err != nil {
+			continue
+		}
+
+		// pid (comm) state ppid pgrp ...; comm may contain blanks.
+		s := string(data)
+		end := strings.LastIndexByte(s, ')')
+		if end < 0 {
+			continue
+		}
+
+		fields := strings.Fields(s[end+1:])
+		if len(fields) < 3 || fields[0] == "Z" {
+			continue
+		}
+
+		if pgrp, _ := strconv.Atoi(fields[2]); pgrp == pgid {
+			pid, _ := strconv.Atoi(strings.Fields(s)[0])
+			members = append(members, pid)
+		}
+	}
+
+	return members
+}
+
+// waitForMembers polls until the group has n live processes.
+func waitForMembers(t *testing.T, pgid int, n int) []int {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		members := groupMembers(t, pgid)
+		if len(members) == n || time.Now().After(deadline) {
+			return members
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func requireSleep(t *testing.T) {
+	t.Helper()
+	if _, err := ose.LookPath("sleep"); err != nil {
+		t.Skip("sleep not found")
+	}
+	if _, err := ose.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+}
+
+func TestCmd_OwnProcessGroup(t *testing.T) {
+	requireSleep(t)
+
+	cmd := exec.New("sh", "-c", tree)
+	assert.NoError(t, cmd.Start())
+	defer cmd.Wait()
+	defer cmd.Kill()
+
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	assert.NoError(t, err)
+	assert.Equal(t, cmd.Process.Pid, pgid)
+	assert.NotEqual(t, syscall.Getpgrp(), pgid)
+
+	// the shell and its three sleeps.
+	assert.Len(t, waitForMembers(t, pgid, 4), 4)
+}
+
+func TestCmd_CancelKillsGroup(t *testing.T) {
+	requireSleep(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.NewContext(ctx, "sh", "-c", tree)
+	assert.NoError(t, cmd.Start())
+	pgid := cmd.Process.Pid
+	assert.Len(t, waitForMembers(t, pgid, 4), 4)
+
+	cancel()
+	assert.Error(t, cmd.Wait())
+	assert.Empty(t, waitForMembers(t, pgid, 0))
+}
+
+func TestCmd_ForwardsSignals(t *testing.T) {
+	requireSleep(t)
+
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT} {
+		t.Run(sig.String(), func(t *testing.T) {
+			cmd := exec.New("sh", "-c", nested)
+			assert.NoError(t, cmd.Start())
+			pgid := cmd.Process.Pid
+			assert.Len(t, waitForMembers(t, pgid, 3), 3)
+
+			// the signal reaches the current process, which passes
+			// it on instead of dying.
+			assert.NoError(t, syscall.Kill(os.Getpid(), sig))
+
+			done := make(chan error, 1)
+			go func() { done <- cmd.Wait() }()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				cmd.Kill()
+				t.Fatalf("%s was not forwarded", sig)
+			}
+
+			assert.Empty(t, waitForMembers(t, pgid, 0))
+		})
+	}
+}
+
+func TestCmd_CancelForwardsSignal(t *testing.T) {
+	requireSleep(t)
+
+	// the context is canceled by the same SIGINT that is forwarded,
+	// as in the run command.
+	ctx, stop := exec.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd := exec.NewContext(ctx, "sh", "-c", `trap "echo got-int; exit 3" INT; sleep 5 >/dev/null & wait`)
+	var out strings.Builder
+	cmd.Stdout = &out
+	assert.NoError(t, cmd.Start())
+	pgid := cmd.Process.Pid
+	assert.Len(t, waitForMembers(t, pgid, 2), 2)
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	err := cmd.Wait()
+
+	var exitErr *ose.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, cmd.ProcessState.ExitCode())
+	assert.Equal(t, "got-int\n", out.String())
+
+	// the background sleep ignores SIGINT and outlives the shell.
+	syscall.Kill(-pgid, syscall.SIGKILL)
+}
+
+func TestCmd_CancelWaitsBeforeKill(t *testing.T) {
+	requireSleep(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.NewContext(ctx, "sh", "-c", `trap "" TERM; sleep 100; :`)
+	cmd.WaitDelay = 300 * time.Millisecond
+	assert.NoError(t, cmd.Start())
+	pgid := cmd.Process.Pid
+	assert.Len(t, waitForMembers(t, pgid, 2), 2)
+
+	start := time.Now()
+	cancel()
+	assert.Error(t, cmd.Wait())
+	assert.GreaterOrEqual(t, time.Since(start), cmd.WaitDelay)
+	assert.Empty(t, waitForMembers(t, pgid, 0))
+}
+
+func TestPipeline_CancelKillsGroups(t *testing.T) {
+	requireSleep(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	first := exec.New("sh", "-c", tree)
+	second := exec.New("sh", "-c", "cat; "+tree)
+	_, err := first.Pipe(second).WithContext(ctx).Output()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Empty(t, waitForMembers(t, first.Process.Pid, 0))
+	assert.Empty(t, waitForMembers(t, second.Process.Pid, 0))
+}
+
+// TestCmd_ParentDeathSignal runs a helper process that starts a
+// sleep and exits without waiting for it. The sleep must not outlive
+// the helper.
+func TestCmd_ParentDeathSignal(t *testing.T) {
+	requireSleep(t)
+
+	if os.Getenv("RUN_EXEC_ORPHAN_HELPER") == "1" {
+		cmd := exec.New("sleep", "100")
+		if err := cmd.Start(); err != nil {
+			os.Exit(2)
+		}
+
+		fmt.Println(cmd.Process.Pid)
+		time.Sleep(100 * time.Millisecond)
+		os.Exit(0)
+	}
+
+	helper := ose.Command(os.Args[0], "-test.run=^TestCmd_ParentDeathSignal$")
+	helper.Env = append(os.Environ(), "RUN_EXEC_ORPHAN_HELPER=1")
+	out, err := helper.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, helper.Start())
+
+	scanner := bufio.NewScanner(out)
+	assert.True(t, scanner.Scan())
+	pid, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	assert.NoError(t, err)
+	helper.Wait()
+
+	assert.Empty(t, waitForMembers(t, pid, 0))
+}
@@ -0,0 +1,134 @@
+//go:build !windows
+
+package exec
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+func init() {
+	forwarded = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+	terminate = syscall.SIGTERM
+}
+
+// prepareGroup starts the command in a process group of its own so
+// signals and cancellation reach the processes it spawns as well. A
+// command that reads the terminal the current process controls gets
+// the terminal as its foreground process group, otherwise it would
+// be stopped on the first read.
+func (c *Cmd) prepareGroup() {
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	setParentDeathSignal(c.SysProcAttr)
+
+	if c.tty != nil {
+		// a session of its own, which also creates the process
+		// group, with the pseudo-terminal on the child's stdin as
+		// the controlling terminal.
+		c.SysProcAttr.Setsid = true
+		c.SysProcAttr.Setctty = true
+		c.SysProcAttr.Ctty = 0
+	} else {
+		c.SysProcAttr.Setpgid = true
+		if f, ok := c.Stdin.(*os.File); ok && isForeground(f) {
+			c.SysProcAttr.Foreground = true
+			// the child's stdin.
+			c.SysProcAttr.Ctty = 0
+			c.group.tty = f
+		}
+	}
+
+	if c.ctx != nil {
+		ctx := *c.ctx
+		c.Cmd.Cancel = func() error { return c.interrupt(ctx) }
+		if c.Cmd.WaitDelay == 0 {
+			c.Cmd.WaitDelay = DefaultWaitDelay
+		}
+	}
+}
+
+// isForeground reports whether f is a terminal whose foreground
+// process group is the group of the current process.
+func isForeground(f *os.File) bool {
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	return err == nil && pgrp == syscall.Getpgrp()
+}
+
+// restoreTerminal makes the current process group the foreground
+// process group of the terminal again.
+func restoreTerminal(g *group) {
+	if g.tty == nil {
+		return
+	}
+
+	// a background process group that changes the foreground group
+	// receives SIGTTOU, which would stop the current process.
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	unix.IoctlSetPointerInt(int(g.tty.Fd()), unix.TIOCSPGRP, syscall.Getpgrp())
+	g.tty = nil
+}
+
+// killStragglers kills the processes left in the group of a command
+// that was killed by a signal, such as background jobs, which shells
+// start with SIGINT ignored.
+func killStragglers(c *Cmd) {
+	if c.ProcessState == nil {
+		return
+	}
+
+	status, ok := c.ProcessState.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() {
+		syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// Signal sends the signal to every process in the process group of
+// the command.
+func (c *Cmd) Signal(sig os.Signal) error {
+	if c.Process == nil {
+		return errors.New("exec: command not started")
+	}
+
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return c.Process.Signal(sig)
+	}
+
+	if err := syscall.Kill(-c.Process.Pid, s); err != nil {
+		// the group is gone or was never created.
+		return c.Process.Signal(sig)
+	}
+
+	return nil
+}
+
+// Kill kills every process in the process group of the command.
+func (c *Cmd) Kill() error {
+	return c.Signal(syscall.SIGKILL)
+}
+
+// exitStatus returns the exit code of the process and, when it was
+// killed by a signal, the name of the signal with 128 plus the
+// signal number as the code, like a shell reports it.
+func exitStatus(state *os.ProcessState) (int, string) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() {
+		return 128 + int(status.Signal()), unix.SignalName(status.Signal())
+	}
+
+	return state.ExitCode(), ""
+}
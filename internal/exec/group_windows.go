@@ -0,0 +1,41 @@
+//go:build windows
+
+package exec
+
+import (
+	"errors"
+	"os"
+)
+
+// prepareGroup is a no-op on Windows: console control events are
+// already delivered to every process attached to the console.
+func (c *Cmd) prepareGroup() {}
+
+func restoreTerminal(g *group) {}
+
+func killStragglers(c *Cmd) {}
+
+// Signal sends the signal to the command. Windows only supports
+// os.Kill.
+func (c *Cmd) Signal(sig os.Signal) error {
+	if c.Process == nil {
+		return errors.New("exec: command not started")
+	}
+
+	return c.Process.Signal(sig)
+}
+
+// Kill kills the command.
+func (c *Cmd) Kill() error {
+	if c.Process == nil {
+		return errors.New("exec: command not started")
+	}
+
+	return c.Process.Kill()
+}
+
+// exitStatus returns the exit code of the process. Windows has no
+// signals.
+func exitStatus(state *os.ProcessState) (int, string) {
+	return state.ExitCode(), ""
+}
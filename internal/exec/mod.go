@@ -29,6 +29,8 @@ type Cmd struct {
 	logger        func(cmd *Cmd)
 	disableLogger bool
 	TempFile      *string
+	group         *group
+	tty           *tty
 }
 
 func New(name string, args ...string) *Cmd {
@@ -203,30 +205,8 @@ func (c *Cmd) WithStdio(stdin, stdout, stderr int) *Cmd {
 func (c *Cmd) Quiet() (*Result, error) {
 	c.Cmd.Stdout = nil
 	c.Cmd.Stderr = nil
-	var out Result
-	out.FileName = c.Cmd.Path
-	out.Args = c.Cmd.Args
-	out.Stdout = make([]byte, 0)
-	out.Stderr = make([]byte, 0)
-	// use utc time
-	out.StartedAt = time.Now().UTC()
 
-	err := c.Start()
-	if err != nil {
-		return nil, err
-	}
-
-	err = c.Wait()
-	if err != nil {
-		return nil, err
-	}
-	out.EndedAt = time.Now().UTC()
-	out.Code = c.Cmd.ProcessState.ExitCode()
-	if c.TempFile != nil {
-		out.TempFile = c.TempFile
-	}
-
-	return &out, nil
+	return c.run()
 }
 
 // Runs the command and waits for it to finish
@@ -236,81 +216,150 @@ func (c *Cmd) Run() (*Result, error) {
 	c.Cmd.Stdout = os.Stdout
 	c.Cmd.Stderr = os.Stderr
 	c.Cmd.Stdin = os.Stdin
-	var out Result
-	out.FileName = c.Cmd.Path
-	out.Args = c.Cmd.Args
-	// use utc time
-	out.StartedAt = time.Now().UTC()
-	out.Stdout = make([]byte, 0)
-	out.Stderr = make([]byte, 0)
 
-	err := c.Start()
-	if err != nil {
-		out.EndedAt = time.Now().UTC()
-		out.Code = 1
-		return &out, err
+	return c.run()
+}
+
+// Runs the command and captures the PsOutput
+// PsOutputs are captured from the current process and
+// are not inherited
+func (c *Cmd) Output() (*Result, error) {
+	var outb, errb bytes.Buffer
+	c.Stdout = &outb
+	c.Stderr = &errb
+
+	out, err := c.run()
+	out.Stdout = outb.Bytes()
+	out.Stderr = errb.Bytes()
+
+	return out, err
+}
+
+// DefaultTeeLimit is the number of bytes Tee keeps of each stream
+// when no limit is given.
+const DefaultTeeLimit = 64 * 1024
+
+// TeeOptions configures Tee.
+type TeeOptions struct {
+	// Limit is the number of bytes kept of each stream, defaults to
+	// DefaultTeeLimit.
+	Limit int
+
+	// Stdout and Stderr receive the output as it is written and
+	// default to the stdout and stderr of the current process.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// LogFile, when set, receives the complete output of both
+	// streams. The file is created or truncated.
+	LogFile string
+}
+
+// Tee runs the command with stdin inherited and streams its output
+// while keeping the last bytes of stdout and stderr in the result,
+// which is useful to show live progress and still report the tail
+// of the output when the command fails.
+func (c *Cmd) Tee(options *TeeOptions) (*Result, error) {
+	if options == nil {
+		options = &TeeOptions{}
 	}
 
-	err = c.Wait()
-	if err != nil {
-		out.EndedAt = time.Now().UTC()
-		out.Code = 1
-		return &out, err
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultTeeLimit
 	}
 
-	out.EndedAt = time.Now().UTC()
-	out.Code = c.Cmd.ProcessState.ExitCode()
-	if c.TempFile != nil {
-		out.TempFile = c.TempFile
+	stdout := options.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
 	}
 
-	return &out, nil
-}
+	stderr := options.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
 
-// Runs the command and captures the PsOutput
-// PsOutputs are captured from the current process and
-// are not inherited
-func (c *Cmd) Output() (*Result, error) {
+	outb := NewRingBuffer(limit)
+	errb := NewRingBuffer(limit)
+	outs := []io.Writer{stdout, outb}
+	errs := []io.Writer{stderr, errb}
+
+	var log *os.File
+	if options.LogFile != "" {
+		f, err := os.Create(options.LogFile)
+		if err != nil {
+			return &Result{FileName: c.Cmd.Path, Args: c.Cmd.Args, Code: 1}, err
+		}
+		defer f.Close()
+
+		log = f
+		shared := &lockedWriter{w: f}
+		outs = append(outs, shared)
+		errs = append(errs, shared)
+	}
+
+	c.Cmd.Stdin = os.Stdin
+	c.Cmd.Stdout = io.MultiWriter(outs...)
+	c.Cmd.Stderr = io.MultiWriter(errs...)
 
+	out, err := c.run()
+	out.Stdout = outb.Bytes()
+	out.Stderr = errb.Bytes()
+	out.Truncated = outb.Truncated() || errb.Truncated()
+	if log != nil {
+		out.LogFile = &options.LogFile
+	}
+
+	return out, err
+}
+
+// run starts the command, waits for it and reports its exit status.
+// The result is never nil. A command that cannot be started returns
+// a StartError and a command that exits with a non-zero code or is
+// killed by a signal returns an ExitError.
+func (c *Cmd) run() (*Result, error) {
 	var out Result
+	out.FileName = c.Cmd.Path
+	out.Args = c.Cmd.Args
 	out.Stdout = make([]byte, 0)
 	out.Stderr = make([]byte, 0)
+	// use utc time
 	out.StartedAt = time.Now().UTC()
-	out.FileName = c.Cmd.Path
-	out.Args = c.Cmd.Args
-
-	var outb, errb bytes.Buffer
-	c.Stdout = &outb
-	c.Stderr = &errb
+	if c.TempFile != nil {
+		out.TempFile = c.TempFile
+	}
 
 	err := c.Start()
 	if err != nil {
 		out.EndedAt = time.Now().UTC()
 		out.Code = 1
+		var startErr *StartError
+		if errors.As(err, &startErr) {
+			out.Code = startErr.ExitCode()
+		}
 		return &out, err
 	}
 
+	out.FileName = c.Cmd.Path
 	err = c.Wait()
-	if err != nil {
-		out.EndedAt = time.Now().UTC()
+	out.EndedAt = time.Now().UTC()
+	if c.ProcessState == nil {
 		out.Code = 1
 		return &out, err
 	}
 
-	out.EndedAt = time.Now().UTC()
-	out.Code = c.Cmd.ProcessState.ExitCode()
-	out.Stdout = outb.Bytes()
-	out.Stderr = errb.Bytes()
-	if c.TempFile != nil {
-		out.TempFile = c.TempFile
+	out.Code, out.Signal = exitStatus(c.ProcessState)
+	var exitErr *exec.ExitError
+	if out.Code != 0 && (err == nil || errors.As(err, &exitErr)) {
+		return &out, &ExitError{Result: &out}
 	}
 
-	return &out, nil
+	return &out, err
 }
 
 func (c *Cmd) Start() error {
 	if c.disableLogger {
-		return c.Cmd.Start()
+		return c.start()
 	}
 
 	if c.logger != nil {
@@ -329,9 +378,56 @@ func (c *Cmd) Start() error {
 		}
 	}
 
-	return c.Cmd.Start()
+	return c.start()
+}
+
+// start starts the command in a process group of its own. Until
+// Wait returns, SIGINT, SIGTERM and SIGHUP received by the current
+// process are forwarded to the group. When the context of a command
+// is done, the group receives the forwarded signal or SIGTERM, and
+// is killed if the command has not exited after WaitDelay, which
+// defaults to DefaultWaitDelay.
+func (c *Cmd) start() error {
+	c.group = newGroup()
+
+	var (
+		stdin  io.Reader
+		stdout io.Writer
+	)
+	if c.tty != nil {
+		c.tty = &tty{}
+		var err error
+		if stdin, stdout, err = c.startTTY(); err != nil {
+			return &StartError{Path: c.Cmd.Path, Err: err}
+		}
+	}
+
+	c.prepareGroup()
+	if err := c.Cmd.Start(); err != nil {
+		c.release()
+		if c.tty != nil {
+			c.closeTTY()
+		}
+		return &StartError{Path: c.Cmd.Path, Err: err}
+	}
+
+	if c.tty != nil {
+		c.connect(stdin, stdout)
+	}
+
+	c.forward()
+	return nil
 }
 
 func (c *Cmd) Wait() error {
-	return c.Cmd.Wait()
+	if c.group != nil {
+		defer c.release()
+	}
+
+	err := c.Cmd.Wait()
+	if c.tty != nil && c.tty.master != nil {
+		c.closeTTY()
+	}
+
+	return err
 }
@@ -1,8 +1,16 @@
 package exec_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyprxlabs/run/internal/exec"
 	"github.com/stretchr/testify/assert"
@@ -47,3 +55,246 @@ func TestPipeCommand(t *testing.T) {
 	assert.Equal(t, 0, o.Code)
 	assert.Equal(t, "Hello World", strings.TrimSpace(o.Text()))
 }
+
+func TestPipeline_LargeOutput(t *testing.T) {
+	for _, name := range []string{"seq", "cat", "wc"} {
+		if _, ok := exec.Which(name); !ok {
+			t.Skip(name + " not found")
+		}
+	}
+
+	o, err := exec.New("seq", "1", "200000").
+		Pipe(exec.New("cat"), exec.New("cat"), exec.New("wc", "-l")).
+		Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, o.Code)
+	assert.Len(t, o.Stages, 4)
+	assert.Equal(t, "200000", strings.TrimSpace(o.Text()))
+}
+
+func TestPipeline_StageStderr(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	var stderr strings.Builder
+	o, err := exec.New("sh", "-c", "echo first >&2; echo data").
+		Pipe(exec.New("sh", "-c", "cat; echo second >&2")).
+		WithStderr(&stderr).
+		Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "first\n", o.Stages[0].ErrorText())
+	assert.Equal(t, "second\n", o.Stages[1].ErrorText())
+	assert.Equal(t, "data", strings.TrimSpace(o.Text()))
+	assert.Contains(t, stderr.String(), "first\n")
+	assert.Contains(t, stderr.String(), "second\n")
+}
+
+func TestPipeline_StageStderrTail(t *testing.T) {
+	for _, name := range []string{"sh", "seq"} {
+		if _, ok := exec.Which(name); !ok {
+			t.Skip(name + " not found")
+		}
+	}
+
+	o, err := exec.New("sh", "-c", "seq 1 100000 >&2; echo data").
+		Pipe(exec.New("cat")).
+		Output()
+	assert.NoError(t, err)
+	assert.Len(t, o.Stages[0].Stderr, exec.DefaultTeeLimit)
+	assert.True(t, o.Stages[0].Truncated)
+	assert.True(t, strings.HasSuffix(o.Stages[0].ErrorText(), "99999\n100000\n"))
+	assert.False(t, o.Stages[1].Truncated)
+	assert.Equal(t, "data", strings.TrimSpace(o.Text()))
+}
+
+func TestPipeline_MergeStderr(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	var own strings.Builder
+	first := exec.New("sh", "-c", "echo merged >&2; echo data")
+	second := exec.New("sh", "-c", "cat; echo own >&2")
+	second.Stderr = &own
+
+	o, err := first.Pipe(second).MergeStderr(0).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "merged\ndata\n", o.Text())
+	assert.Equal(t, "own\n", own.String())
+	assert.Equal(t, "own\n", o.Stages[1].ErrorText())
+}
+
+func TestPipeline_Pipefail(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	o, err := exec.New("sh", "-c", "exit 3").Pipe(exec.New("sh", "-c", "cat")).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, o.Code)
+	assert.Equal(t, 3, o.Stages[0].Code)
+
+	o, err = exec.New("sh", "-c", "exit 3").Pipe(exec.New("sh", "-c", "cat")).WithPipefail(true).Output()
+	assert.Error(t, err)
+	assert.Equal(t, 3, o.Code)
+	assert.Len(t, o.Failed(), 1)
+}
+
+func TestPipeline_StartError(t *testing.T) {
+	if _, ok := exec.Which("sleep"); !ok {
+		t.Skip("sleep not found")
+	}
+
+	o, err := exec.New("sleep", "10").Pipe(exec.New("definitely-not-a-command-xyz")).Output()
+	var startErr *exec.StartError
+	assert.ErrorAs(t, err, &startErr)
+	assert.True(t, startErr.NotFound())
+	assert.Equal(t, 127, o.Code)
+}
+
+func TestPipeline_Cancel(t *testing.T) {
+	for _, name := range []string{"sleep", "cat"} {
+		if _, ok := exec.Which(name); !ok {
+			t.Skip(name + " not found")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := exec.New("sleep", "10").Pipe(exec.New("cat")).WithContext(ctx).Output()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestOutput_ExitCode(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	o, err := exec.New("sh", "-c", "echo out; echo err >&2; exit 3").Output()
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Equal(t, 3, o.Code)
+	assert.Empty(t, o.Signal)
+	assert.Equal(t, "out\n", o.Text())
+	assert.Equal(t, "err\n", o.ErrorText())
+	assert.Same(t, o, exitErr.Result)
+}
+
+func TestQuiet_ExitCode(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	o, err := exec.New("sh", "-c", "exit 5").Quiet()
+	assert.Error(t, err)
+	if assert.NotNil(t, o) {
+		assert.Equal(t, 5, o.Code)
+	}
+
+	o, err = exec.New("sh", "-c", "exit 0").Quiet()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, o.Code)
+}
+
+func TestOutput_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no signals on windows")
+	}
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	o, err := exec.New("sh", "-c", "echo before; kill -TERM $$").Output()
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, "command "+o.FileName+" was killed by SIGTERM", err.Error())
+	assert.Equal(t, 143, o.Code)
+	assert.Equal(t, "SIGTERM", o.Signal)
+	assert.Equal(t, "before\n", o.Text())
+}
+
+func TestRun_StartErrors(t *testing.T) {
+	o, err := exec.New("definitely-not-a-command-xyz").Output()
+	var startErr *exec.StartError
+	if assert.ErrorAs(t, err, &startErr) {
+		assert.True(t, startErr.NotFound())
+	}
+	assert.Equal(t, 127, o.Code)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	// a file without the executable bit.
+	path := filepath.Join(t.TempDir(), "script.sh")
+	assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho hi\n"), 0o644))
+
+	o, err = exec.New(path).Quiet()
+	if assert.ErrorAs(t, err, &startErr) {
+		assert.False(t, startErr.NotFound())
+		assert.ErrorIs(t, err, fs.ErrPermission)
+	}
+	assert.Equal(t, 126, o.Code)
+}
+
+func TestExitCode(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	_, err := exec.New("sh", "-c", "exit 7").Quiet()
+	code, ok := exec.ExitCode(fmt.Errorf("task failed: %w", err))
+	assert.True(t, ok)
+	assert.Equal(t, 7, code)
+
+	cmd := exec.New("sh", "-c", "exit 9")
+	assert.NoError(t, cmd.Start())
+	code, ok = exec.ExitCode(cmd.Wait())
+	assert.True(t, ok)
+	assert.Equal(t, 9, code)
+
+	_, ok = exec.ExitCode(errors.New("other"))
+	assert.False(t, ok)
+}
+
+func TestTee(t *testing.T) {
+	if _, ok := exec.Which("sh"); !ok {
+		t.Skip("sh not found")
+	}
+
+	var stdout, stderr strings.Builder
+	log := filepath.Join(t.TempDir(), "out.log")
+	o, err := exec.New("sh", "-c", "seq 1 1000; echo failed >&2; exit 2").Tee(&exec.TeeOptions{
+		Limit:   16,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+		LogFile: log,
+	})
+
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, o.Code)
+
+	// the live streams get everything, the result only the tail.
+	assert.True(t, strings.HasPrefix(stdout.String(), "1\n2\n3\n"))
+	assert.Equal(t, "failed\n", stderr.String())
+	assert.Equal(t, "97\n998\n999\n1000\n", o.Text())
+	assert.Equal(t, "failed\n", o.ErrorText())
+	assert.True(t, o.Truncated)
+
+	data, err := os.ReadFile(log)
+	assert.NoError(t, err)
+	// the streams are copied concurrently, so only their own order
+	// is kept.
+	assert.Len(t, data, stdout.Len()+stderr.Len())
+	assert.Contains(t, string(data), "999\n1000\n")
+	assert.Contains(t, string(data), "failed\n")
+	if assert.NotNil(t, o.LogFile) {
+		assert.Equal(t, log, *o.LogFile)
+	}
+}
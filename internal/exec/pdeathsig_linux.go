@@ -0,0 +1,11 @@
+package exec
+
+import "syscall"
+
+// setParentDeathSignal kills the command when the current process
+// dies without cleaning up, e.g. when it is killed with SIGKILL. Only
+// the command itself receives the signal, the processes it spawned
+// keep running unless it takes them down on its way out.
+func setParentDeathSignal(attr *syscall.SysProcAttr) {
+	attr.Pdeathsig = syscall.SIGKILL
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package exec
+
+import "syscall"
+
+// setParentDeathSignal is a no-op: only Linux supports a parent
+// death signal.
+func setParentDeathSignal(attr *syscall.SysProcAttr) {}
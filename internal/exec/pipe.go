@@ -4,14 +4,41 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"sync"
 	"time"
 )
 
 type Pipeline struct {
-	cmds []*Cmd
-	ctx  *context.Context // if true, the command is a context command
+	cmds     []*Cmd
+	ctx      *context.Context // if true, the command is a context command
+	pipefail bool
+	stdin    io.Reader
+	stderr   io.Writer
+	merged   map[int]bool
+}
+
+// PipelineResult holds the outcome of every stage of a pipeline.
+type PipelineResult struct {
+	// Stages holds the result of each stage in pipeline order. The
+	// last DefaultTeeLimit bytes of the stderr of each stage are
+	// captured in its result.
+	Stages []*Result
+
+	// Code is the exit code of the last stage or, with pipefail, of
+	// the last stage that failed.
+	Code int
+
+	// Stdout holds the output of the last stage when the pipeline
+	// was run with Output.
+	Stdout []byte
+
+	Pipefail  bool
+	StartedAt time.Time
+	EndedAt   time.Time
 }
 
 func (p *Pipeline) Pipe(subcommands ...*Cmd) *Pipeline {
@@ -63,196 +90,345 @@ func (c *Cmd) PipeCommand(subcommands ...string) *Pipeline {
 	return p
 }
 
-func (p *Pipeline) Output() (*Result, error) {
-	var o Result
-	o.Stdout = make([]byte, 0)
-	o.Stderr = make([]byte, 0)
-	o.StartedAt = time.Now().UTC()
+// NewPipeline creates a pipeline from the commands.
+func NewPipeline(cmds ...*Cmd) *Pipeline {
+	return &Pipeline{cmds: cmds}
+}
+
+// WithContext kills every stage when the context is done.
+func (p *Pipeline) WithContext(ctx context.Context) *Pipeline {
+	p.ctx = &ctx
+	return p
+}
+
+// WithPipefail makes the pipeline fail with the exit code of the
+// last stage that failed instead of the exit code of the last stage.
+func (p *Pipeline) WithPipefail(enable bool) *Pipeline {
+	p.pipefail = enable
+	return p
+}
+
+// WithStdin sets the input of the first stage.
+func (p *Pipeline) WithStdin(stdin io.Reader) *Pipeline {
+	p.stdin = stdin
+	return p
+}
+
+// WithStderr streams the stderr of every stage to w, in addition to
+// capturing it in the stage results.
+func (p *Pipeline) WithStderr(stderr io.Writer) *Pipeline {
+	p.stderr = stderr
+	return p
+}
+
+// MergeStderr sends the stderr of the stage, counted from zero, to
+// the same place as its stdout, like '2>&1' in a shell. The stderr
+// of a stage that writes to a pipe or file is not captured.
+func (p *Pipeline) MergeStderr(stage int) *Pipeline {
+	if p.merged == nil {
+		p.merged = map[int]bool{}
+	}
+	p.merged[stage] = true
+	return p
+}
 
-	lastIndex := len(p.cmds) - 1
-	r, w := io.Pipe()
+// Cmds returns the stages of the pipeline.
+func (p *Pipeline) Cmds() []*Cmd {
+	return p.cmds
+}
 
-	errs := make([]error, 0)
-	count := 0
-	var outb, errb bytes.Buffer
+// Output runs the pipeline and captures the output of the last stage.
+func (p *Pipeline) Output() (*PipelineResult, error) {
+	var outb bytes.Buffer
+	res, err := p.Stream(&outb)
+	if res != nil {
+		res.Stdout = outb.Bytes()
+	}
+
+	return res, err
+}
+
+// Run runs the pipeline with the output of the last stage and the
+// stderr of every stage written to the current process. The first
+// stage reads stdin unless WithStdin was used.
+func (p *Pipeline) Run() (*PipelineResult, error) {
+	if p.stdin == nil {
+		p.stdin = os.Stdin
+	}
+
+	if p.stderr == nil {
+		p.stderr = os.Stderr
+	}
 
-	prev := p.cmds[0]
+	return p.Stream(os.Stdout)
+}
+
+// Stream starts every stage at once, connected by OS pipes, and
+// writes the output of the last stage to w. A stage that already has
+// a stdin or a stdout, e.g. a file a shell redirected it to, keeps it
+// instead of the pipe. The stderr of a stage goes to the writer the
+// stage already has, or else to the writer set by WithStderr. It waits for all stages to finish. The error is
+// set when a stage cannot be started, when the context is done or
+// when the pipeline exits with a non-zero code.
+func (p *Pipeline) Stream(w io.Writer) (*PipelineResult, error) {
+	n := len(p.cmds)
+	if n == 0 {
+		return nil, errors.New("pipeline has no commands")
+	}
+
+	res := &PipelineResult{
+		Stages:    make([]*Result, n),
+		Pipefail:  p.pipefail,
+		StartedAt: time.Now().UTC(),
+	}
+
+	// the stdout and stderr of the stages are copied by goroutines of
+	// their own, so every writer they share goes through one lock.
+	locks := lockedWriters{}
+	shared := locks.get(p.stderr)
+
+	// only the tail of the stderr of each stage is kept, so a noisy
+	// stage cannot grow memory without limit.
+	stderrs := make([]*RingBuffer, n)
+	files := []*os.File{}
+	closeFiles := func() {
+		for _, f := range files {
+			f.Close()
+		}
+		files = nil
+	}
+
+	var prev *os.File
 	for i, cmd := range p.cmds {
-		count++
+		res.Stages[i] = &Result{
+			FileName:  cmd.Path,
+			Args:      cmd.Args,
+			Stdout:    make([]byte, 0),
+			Stderr:    make([]byte, 0),
+			StartedAt: res.StartedAt,
+			TempFile:  cmd.TempFile,
+		}
+
+		// a stage after the first that already has a stdin keeps it,
+		// which leaves the pipe from the stage before without a
+		// reader, like '<' does in a shell.
 		if i == 0 {
-			cmd.Stdout = w
-			err := cmd.Start()
+			cmd.Stdin = p.stdin
+		} else if cmd.Stdin == nil {
+			cmd.Stdin = prev
+		}
+
+		if i == n-1 {
+			cmd.Stdout = locks.get(w)
+		} else {
+			r, pw, err := os.Pipe()
 			if err != nil {
-				errs = append(errs, err)
-				break
+				closeFiles()
+				return res, err
 			}
-		} else if i == lastIndex {
-			cmd.Stdin = r
-			cmd.Stdout = &outb
-			cmd.Stderr = &errb
-			err := cmd.Start()
-			if err != nil {
-				errs = append(errs, err)
-				break
+			files = append(files, r, pw)
+			prev = r
+
+			// a stage that already has a stdout keeps it, and the
+			// next stage reads the pipe, which nothing writes to,
+			// to EOF.
+			if cmd.Stdout == nil {
+				cmd.Stdout = pw
+			} else {
+				cmd.Stdout = locks.get(cmd.Stdout)
 			}
+		}
 
-			err = prev.Wait()
-			if err != nil {
-				errs = append(errs, err)
+		// a stage that already has a stderr keeps it, e.g. when a
+		// shell redirected it to a file.
+		stderrs[i] = NewRingBuffer(DefaultTeeLimit)
+		switch {
+		case p.merged[i]:
+			// the parent closes its copy of a pipe once the stages
+			// start, so a pipe is shared with the stage instead of
+			// copied to, and the stderr is not captured.
+			if f, ok := cmd.Stdout.(*os.File); ok {
+				cmd.Stderr = f
+			} else {
+				cmd.Stderr = io.MultiWriter(stderrs[i], cmd.Stdout)
 			}
+		case cmd.Stderr != nil:
+			cmd.Stderr = io.MultiWriter(stderrs[i], locks.get(cmd.Stderr))
+		case shared != nil:
+			cmd.Stderr = io.MultiWriter(stderrs[i], shared)
+		default:
+			cmd.Stderr = stderrs[i]
+		}
+	}
 
-			err = w.Close()
-			if err != nil {
-				errs = append(errs, err)
+	started := 0
+	var startErr error
+	for i, cmd := range p.cmds {
+		if err := cmd.Start(); err != nil {
+			startErr = fmt.Errorf("pipeline stage %d: %w", i+1, err)
+			res.Stages[i].Code = 1
+			var se *StartError
+			if errors.As(err, &se) {
+				res.Stages[i].Code = se.ExitCode()
 			}
+			break
+		}
+		res.Stages[i].FileName = cmd.Path
+		started++
+	}
 
-			err = cmd.Wait()
-			o.EndedAt = time.Now().UTC()
-			if err != nil {
-				errs = append(errs, err)
-			}
+	// the children hold their own copies of the pipe ends, which
+	// lets every stage see EOF once its writer exits.
+	closeFiles()
 
-			o.FileName = cmd.Path
-			o.Args = cmd.Args
-			o.Code = cmd.Cmd.ProcessState.ExitCode()
-			o.Stdout = outb.Bytes()
-			o.Stderr = errb.Bytes()
-		} else {
-			r2, w2 := io.Pipe()
-			cmd.Stdin = r
-			cmd.Stdout = w2
-			err := cmd.Start()
-			if err != nil {
-				errs = append(errs, err)
-				break
-			}
+	if startErr != nil {
+		for _, cmd := range p.cmds[:started] {
+			cmd.Kill()
+			cmd.Wait()
+		}
 
-			err = prev.Wait()
-			if err != nil {
-				errs = append(errs, err)
-			}
+		res.Code = res.Stages[started].Code
+		res.EndedAt = time.Now().UTC()
+		return res, startErr
+	}
 
-			err = w.Close()
-			if err != nil {
-				errs = append(errs, err)
-			}
+	ctx := context.Background()
+	if p.ctx != nil {
+		ctx = *p.ctx
+	}
 
-			w = w2
-			prev = cmd
-			if err != r.Close() {
-				errs = append(errs, err)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			for _, cmd := range p.cmds {
+				cmd.interrupt(ctx)
 			}
 
-			r = r2
+			select {
+			case <-time.After(DefaultWaitDelay):
+				for _, cmd := range p.cmds {
+					cmd.Kill()
+				}
+			case <-done:
+			}
+		case <-done:
 		}
-	}
+	}()
 
-	if len(errs) > 0 {
-		msg := "Pipeline execution failed with errors: "
-		for _, err := range errs {
-			if err != nil {
-				msg += err.Error() + ";\n"
+	var wg sync.WaitGroup
+	for i, cmd := range p.cmds {
+		wg.Add(1)
+		go func(i int, cmd *Cmd) {
+			defer wg.Done()
+			cmd.Wait()
+
+			stage := res.Stages[i]
+			stage.EndedAt = time.Now().UTC()
+			stage.Code, stage.Signal = exitStatus(cmd.ProcessState)
+			stage.Stderr = stderrs[i].Bytes()
+			stage.Truncated = stderrs[i].Truncated()
+		}(i, cmd)
+	}
+	wg.Wait()
+	close(done)
+
+	res.EndedAt = time.Now().UTC()
+	res.Code = res.Stages[n-1].Code
+	if p.pipefail {
+		for i := n - 1; i >= 0; i-- {
+			if res.Stages[i].Code != 0 {
+				res.Code = res.Stages[i].Code
+				break
 			}
 		}
-		e := errors.New(msg)
+	}
 
-		return &o, e
+	if err := ctx.Err(); err != nil {
+		return res, err
 	}
 
-	return &o, nil
+	return res, res.ToError()
 }
 
-func (p *Pipeline) Run() (*Result, error) {
-	var o Result
-	o.Stdout = make([]byte, 0)
-	o.Stderr = make([]byte, 0)
-	o.StartedAt = time.Now().UTC()
+// Text returns the captured output of the last stage.
+func (r *PipelineResult) Text() string {
+	return string(r.Stdout)
+}
 
-	lastIndex := len(p.cmds) - 1
-	r, w := io.Pipe()
+func (r *PipelineResult) IsOk() bool {
+	return r.Code == 0
+}
 
-	errs := make([]error, len(p.cmds))
-	count := 0
+// Last returns the result of the last stage.
+func (r *PipelineResult) Last() *Result {
+	if len(r.Stages) == 0 {
+		return nil
+	}
 
-	prev := p.cmds[0]
-	for i, cmd := range p.cmds {
-		count++
-		if i == 0 {
-			cmd.Stdout = w
-			err := cmd.Start()
-			if err != nil {
-				errs = append(errs, err)
-				break
-			}
-		} else if i == lastIndex {
-			cmd.Stdin = r
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			err := cmd.Start()
-			if err != nil {
-				errs = append(errs, err)
-				break
-			}
+	return r.Stages[len(r.Stages)-1]
+}
 
-			err = prev.Wait()
-			if err != nil {
-				errs = append(errs, err)
-			}
+// Failed returns the stages that exited with a non-zero code.
+func (r *PipelineResult) Failed() []*Result {
+	failed := []*Result{}
+	for _, stage := range r.Stages {
+		if stage.Code != 0 {
+			failed = append(failed, stage)
+		}
+	}
 
-			err = w.Close()
-			if err != nil {
-				errs = append(errs, err)
-			}
+	return failed
+}
 
-			err = cmd.Wait()
-			o.EndedAt = time.Now().UTC()
-			if err != nil {
-				errs = append(errs, err)
-			}
+func (r *PipelineResult) ToError() error {
+	if r.IsOk() {
+		return nil
+	}
 
-			o.FileName = cmd.Path
-			o.Args = cmd.Args
-			o.Code = cmd.Cmd.ProcessState.ExitCode()
-		} else {
-			r2, w2 := io.Pipe()
-			cmd.Stdin = r
-			cmd.Stdout = w2
-			err := cmd.Start()
-			if err != nil {
-				errs = append(errs, err)
-				break
-			}
+	for i := len(r.Stages) - 1; i >= 0; i-- {
+		stage := r.Stages[i]
+		if stage.Code == r.Code {
+			return fmt.Errorf("pipeline stage %d (%s) failed with code %d: %s", i+1, stage.FileName, stage.Code, stage.ErrorText())
+		}
+	}
 
-			err = prev.Wait()
-			if err != nil {
-				errs = append(errs, err)
-			}
+	return fmt.Errorf("pipeline failed with code %d", r.Code)
+}
 
-			err = w.Close()
-			if err != nil {
-				errs = append(errs, err)
-			}
+// lockedWriter serializes writes from stages that share a writer.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
 
-			w = w2
-			prev = cmd
-			if err != r.Close() {
-				errs = append(errs, err)
-			}
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
 
-			r = r2
-		}
+// lockedWriters hands out one lockedWriter per writer, so a writer
+// used as the stdout of one stage and the stderr of another, or of
+// the same stage as with '2>&1', is never written to at once.
+type lockedWriters map[io.Writer]*lockedWriter
+
+func (l lockedWriters) get(w io.Writer) io.Writer {
+	if w == nil {
+		return nil
 	}
 
-	if len(errs) > 0 {
-		msg := "Pipeline execution failed with errors: "
-		for _, err := range errs {
-			if err != nil {
-				msg += err.Error() + ";\n"
-			}
-		}
-		e := errors.New(msg)
-		return &o, e
+	// files are handed to the stages as they are, and writers that
+	// cannot be map keys cannot be shared by identity either.
+	if _, ok := w.(*os.File); ok || !reflect.TypeOf(w).Comparable() {
+		return w
+	}
+
+	if locked, ok := l[w]; ok {
+		return locked
 	}
 
-	return &o, nil
+	locked := &lockedWriter{w: w}
+	l[w] = locked
+	return locked
 }
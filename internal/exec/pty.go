@@ -0,0 +1,175 @@
+package exec
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ErrPtyNotSupported is returned when pseudo-terminals are not
+// available on the current platform.
+var ErrPtyNotSupported = errors.New("pseudo-terminals are not supported on this platform")
+
+// WithTTY runs the command under a pseudo-terminal, for programs that
+// behave differently or refuse to run when their output is not a
+// terminal. Stdout and stderr of the command both go to the terminal,
+// whose output is written to Stdout, so it can still be captured.
+// When stdin is a terminal it is put in raw mode while the command
+// runs and its window size is passed on as it changes.
+func (c *Cmd) WithTTY() *Cmd {
+	c.tty = &tty{}
+	return c
+}
+
+// tty holds the pseudo-terminal of a running command.
+type tty struct {
+	master *os.File
+	slave  *os.File
+
+	// in is the terminal of the current process when stdin is one.
+	in    *os.File
+	state *term.State
+
+	// stdin is a non-blocking copy of stdin, so reading it can be
+	// stopped when the command exits.
+	stdin   *os.File
+	resized chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+	input   sync.WaitGroup
+	output  sync.WaitGroup
+}
+
+// startTTY opens the pseudo-terminal and makes it the stdio and the
+// controlling terminal of the command. The stdio the command was
+// given is connected to the other end once the command started.
+func (c *Cmd) startTTY() (stdin io.Reader, stdout io.Writer, err error) {
+	master, slave, err := OpenPty()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.tty.master = master
+	c.tty.slave = slave
+	stdin, stdout = c.Cmd.Stdin, c.Cmd.Stdout
+	c.Cmd.Stdin = slave
+	c.Cmd.Stdout = slave
+	c.Cmd.Stderr = slave
+
+	return stdin, stdout, nil
+}
+
+// connect copies between the pseudo-terminal and the stdio of the
+// command after it started.
+func (c *Cmd) connect(stdin io.Reader, stdout io.Writer) {
+	t := c.tty
+	t.slave.Close()
+	t.slave = nil
+	t.done = make(chan struct{})
+
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		t.in = f
+		resize(f, t.master)
+		if state, err := term.MakeRaw(int(f.Fd())); err == nil {
+			t.state = state
+		}
+
+		t.resized = make(chan os.Signal, 1)
+		notifyResize(t.resized)
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			for {
+				select {
+				case <-t.resized:
+					resize(f, t.master)
+				case <-t.done:
+					return
+				}
+			}
+		}()
+	}
+
+	if stdin != nil {
+		// only reading a copy of stdin can be stopped; other readers
+		// are left to finish on their own.
+		var wg *sync.WaitGroup
+		if f, ok := stdin.(*os.File); ok {
+			if dup, err := nonblocking(f); err == nil {
+				t.stdin = dup
+				stdin = dup
+				wg = &t.input
+				wg.Add(1)
+			}
+		}
+
+		go func() {
+			if wg != nil {
+				defer wg.Done()
+			}
+			io.Copy(t.master, stdin)
+			if t.in == nil {
+				select {
+				case <-t.done:
+				default:
+					// end of input, like ctrl-d on a terminal.
+					t.master.Write([]byte{4})
+				}
+			}
+		}()
+	}
+
+	if stdout == nil {
+		stdout = io.Discard
+	}
+
+	t.output.Add(1)
+	go func() {
+		defer t.output.Done()
+		// reading fails with EIO once the command and everything it
+		// started closed the terminal.
+		io.Copy(stdout, t.master)
+	}()
+}
+
+// closeTTY waits for the output of the command and restores the
+// terminal of the current process.
+func (c *Cmd) closeTTY() {
+	t := c.tty
+	if t.slave != nil {
+		t.slave.Close()
+		t.slave = nil
+	}
+
+	if t.done == nil {
+		// the command did not start.
+		t.master.Close()
+		t.master = nil
+		return
+	}
+
+	t.output.Wait()
+	close(t.done)
+	if t.resized != nil {
+		signal.Stop(t.resized)
+	}
+
+	t.wg.Wait()
+	if t.stdin != nil {
+		t.stdin.SetReadDeadline(time.Now())
+		t.input.Wait()
+		restoreBlocking(t.stdin)
+		t.stdin.Close()
+	}
+
+	t.master.Close()
+	t.master = nil
+	if t.state != nil {
+		term.Restore(int(t.in.Fd()), t.state)
+	}
+}
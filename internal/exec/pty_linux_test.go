@@ -0,0 +1,111 @@
+package exec_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// syncBuffer is a buffer that can be read while a command writes to
+// it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitForOutput(t *testing.T, b *syncBuffer, s string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(b.String(), s) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q, got %q", s, b.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCmd_TTY(t *testing.T) {
+	requireSleep(t)
+
+	out, err := exec.New("sh", "-c", "test -t 0 && test -t 1 && test -t 2 && echo tty; echo err >&2").
+		WithTTY().
+		Output()
+	assert.NoError(t, err)
+	// the terminal turns newlines into CRLF and merges stderr.
+	assert.Equal(t, "tty\r\nerr\r\n", out.Text())
+	assert.Empty(t, out.Stderr)
+
+	out, err = exec.New("sh", "-c", "exit 3").WithTTY().Output()
+	assert.Error(t, err)
+	assert.Equal(t, 3, out.Code)
+}
+
+func TestCmd_TTY_Stdin(t *testing.T) {
+	requireSleep(t)
+
+	out, err := exec.New("sh", "-c", "read line; echo \"got $line\"; cat").
+		WithTTY().
+		WithStdin(strings.NewReader("hello\nworld\n")).
+		Output()
+	assert.NoError(t, err)
+	assert.Contains(t, out.Text(), "got hello\r\n")
+	// cat ends once the end of the input is passed on.
+	assert.Contains(t, out.Text(), "world\r\n")
+}
+
+func TestCmd_TTY_WindowSize(t *testing.T) {
+	requireSleep(t)
+
+	// the terminal of the current process is played by a pty pair.
+	master, slave, err := exec.OpenPty()
+	assert.NoError(t, err)
+	defer master.Close()
+	defer slave.Close()
+
+	fd := int(slave.Fd())
+	assert.NoError(t, unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: 40, Col: 100}))
+
+	var out syncBuffer
+	cmd := exec.New("sh", "-c", "stty size; trap 'stty size; exit 0' WINCH; echo ready; while :; do sleep 0.05; done").
+		WithTTY().
+		WithStdin(slave).
+		WithStdout(&out)
+	assert.NoError(t, cmd.Start())
+
+	waitForOutput(t, &out, "ready")
+	assert.Contains(t, out.String(), "40 100")
+
+	// stdin is in raw mode while the command runs.
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	assert.NoError(t, err)
+	assert.Zero(t, termios.Lflag&unix.ECHO)
+
+	assert.NoError(t, unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: 50, Col: 120}))
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGWINCH))
+	waitForOutput(t, &out, "50 120")
+	assert.NoError(t, cmd.Wait())
+
+	termios, err = unix.IoctlGetTermios(fd, unix.TCGETS)
+	assert.NoError(t, err)
+	assert.NotZero(t, termios.Lflag&unix.ECHO)
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package exec
+
+import "os"
+
+// OpenPty returns ErrPtyNotSupported: pseudo-terminals are only
+// supported on Linux and macOS.
+func OpenPty() (master *os.File, slave *os.File, err error) {
+	return nil, nil, ErrPtyNotSupported
+}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+package exec
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// notifyResize delivers a signal on c whenever the window size of
+// the terminal changes.
+func notifyResize(c chan os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+// resize gives the pseudo-terminal the window size of the terminal.
+func resize(from *os.File, to *os.File) {
+	size, err := unix.IoctlGetWinsize(int(from.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return
+	}
+
+	unix.IoctlSetWinsize(int(to.Fd()), unix.TIOCSWINSZ, size)
+}
+
+// nonblocking returns a copy of f whose reads can be interrupted
+// with a deadline. The copy shares the file status flags with f, see
+// restoreBlocking.
+func nonblocking(f *os.File) (*os.File, error) {
+	fd, err := unix.Dup(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	unix.CloseOnExec(fd)
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), f.Name()), nil
+}
+
+// restoreBlocking puts the file back in blocking mode, which the
+// processes that share it, such as the shell that started the
+// current process, expect.
+func restoreBlocking(f *os.File) {
+	if raw, err := f.SyscallConn(); err == nil {
+		raw.Control(func(fd uintptr) {
+			unix.SetNonblock(int(fd), false)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package exec
+
+import (
+	"errors"
+	"os"
+)
+
+func notifyResize(c chan os.Signal) {}
+
+func resize(from *os.File, to *os.File) {}
+
+func nonblocking(f *os.File) (*os.File, error) {
+	return nil, errors.New("not supported")
+}
+
+func restoreBlocking(f *os.File) {}
@@ -16,6 +16,17 @@ type Result struct {
 	StartedAt time.Time
 	EndedAt   time.Time
 	TempFile  *string
+
+	// Signal is the name of the signal that killed the process,
+	// e.g. SIGKILL. The code is then 128 plus the signal number.
+	Signal string
+
+	// Truncated reports whether Stdout or Stderr only hold the tail
+	// of the output, see Tee.
+	Truncated bool
+
+	// LogFile is the file that holds the complete output, see Tee.
+	LogFile *string
 }
 
 func (o *Result) Text() string {
@@ -0,0 +1,94 @@
+package exec
+
+import "sync"
+
+// RingBuffer is a writer that keeps the last Size bytes written to
+// it and drops older ones. It is safe for concurrent use.
+type RingBuffer struct {
+	mu      sync.Mutex
+	buf     []byte
+	size    int
+	start   int
+	written int64
+}
+
+// NewRingBuffer creates a ring buffer that keeps the last size bytes.
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 0 {
+		size = 0
+	}
+
+	return &RingBuffer{size: size}
+}
+
+// Write keeps the tail of p. It never fails.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(p)
+	r.written += int64(n)
+	if r.size == 0 {
+		return n, nil
+	}
+
+	if n >= r.size {
+		r.buf = append(r.buf[:0], p[n-r.size:]...)
+		r.start = 0
+		return n, nil
+	}
+
+	// fill up before wrapping around.
+	if len(r.buf) < r.size {
+		free := r.size - len(r.buf)
+		if n <= free {
+			r.buf = append(r.buf, p...)
+			return n, nil
+		}
+
+		r.buf = append(r.buf, p[:free]...)
+		p = p[free:]
+	}
+
+	for len(p) > 0 {
+		c := copy(r.buf[r.start:], p)
+		p = p[c:]
+		r.start = (r.start + c) % r.size
+	}
+
+	return n, nil
+}
+
+// Bytes returns a copy of the bytes kept, oldest first.
+func (r *RingBuffer) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]byte, 0, len(r.buf))
+	out = append(out, r.buf[r.start:]...)
+	return append(out, r.buf[:r.start]...)
+}
+
+// Len returns the number of bytes kept.
+func (r *RingBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.buf)
+}
+
+// Written returns the number of bytes written in total.
+func (r *RingBuffer) Written() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.written
+}
+
+// Truncated reports whether older bytes were dropped.
+func (r *RingBuffer) Truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.written > int64(len(r.buf))
+}
@@ -0,0 +1,44 @@
+package exec_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	tests := []struct {
+		size   int
+		writes []string
+		want   string
+	}{
+		{8, []string{"abc"}, "abc"},
+		{8, []string{"abcd", "efgh"}, "abcdefgh"},
+		{8, []string{"abcd", "efgh", "ij"}, "cdefghij"},
+		{8, []string{"abcdef", "ghijkl"}, "efghijkl"},
+		{8, []string{"abc", "0123456789"}, "23456789"},
+		{8, []string{"abcdefgh", "i", "j", "k"}, "defghijk"},
+		{4, []string{"ab", "cd", "ef", "gh", "ij"}, "ghij"},
+		{0, []string{"abc"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.writes, "+"), func(t *testing.T) {
+			r := exec.NewRingBuffer(tt.size)
+			total := 0
+			for _, w := range tt.writes {
+				n, err := r.Write([]byte(w))
+				assert.NoError(t, err)
+				assert.Equal(t, len(w), n)
+				total += len(w)
+			}
+
+			assert.Equal(t, tt.want, string(r.Bytes()))
+			assert.Equal(t, len(tt.want), r.Len())
+			assert.Equal(t, int64(total), r.Written())
+			assert.Equal(t, total > len(tt.want), r.Truncated())
+		})
+	}
+}
@@ -43,25 +43,19 @@ func WhichFirst(command string, options *WhichOptions) (string, bool) {
 		}
 	}
 
+	// an absolute path is the executable itself, not a name to look
+	// for on the PATH.
 	if filepath.IsAbs(command) {
-		fi, err := os.Lstat(command)
-
+		path, err := ose.LookPath(command)
 		if err != nil {
 			return "", false
 		}
 
-		if fi.Mode()&os.ModeSymlink != 0 {
-			path, err := ose.LookPath(command)
-			if err != nil {
-				return "", false
-			}
-
-			if options.UseCache {
-				whichCache[name] = path
-			}
-
-			return path, true
+		if options.UseCache {
+			whichCache[name] = path
 		}
+
+		return path, true
 	}
 
 	pathSegments := []string{}
@@ -72,7 +66,7 @@ func WhichFirst(command string, options *WhichOptions) (string, bool) {
 	pathSegments = append(pathSegments, env.SplitPath()...)
 
 	for i, path := range pathSegments {
-		value, _ := env.Expand(path)
+		value := expandPath(path)
 		if value == "" {
 			continue
 		}
@@ -175,3 +169,10 @@ func emptySpace(s string) bool {
 
 	return true
 }
+
+// expandPath expands the variables of a search path against the
+// process environment.
+func expandPath(path string) string {
+	value, _ := env.Expand(path, env.WithLookup(env.Lookup), env.WithExpandUnixArgs(false))
+	return value
+}
@@ -0,0 +1,199 @@
+package fingerprint
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/crypto/hashes"
+	"github.com/hyprxlabs/run/internal/globs"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// HashType is the digest used for file contents, the task
+// definition and the task environment.
+var HashType = hashes.SHA256
+
+// Fingerprint captures the inputs of a task at the time it last
+// completed successfully.
+type Fingerprint struct {
+	Task       string            `json:"task"`
+	Algorithm  string            `json:"algorithm"`
+	Definition string            `json:"definition"`
+	Env        string            `json:"env"`
+	Sources    map[string]string `json:"sources"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// Compute fingerprints the task using its definition, the given
+// environment and the content of every file matched by its
+// sources globs. Relative globs are resolved against dir. Files in
+// StateDir are skipped, since saving a fingerprint changes them.
+func Compute(task *schema.Task, env map[string]string, dir string) (*Fingerprint, error) {
+	fp := &Fingerprint{
+		Task:      task.Id,
+		Algorithm: HashType.String(),
+		Sources:   map[string]string{},
+		CreatedAt: time.Now().UTC(),
+	}
+
+	definition, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+
+	fp.Definition = digest(definition)
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := newHash()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, env[k])
+	}
+	fp.Env = hex.EncodeToString(h.Sum(nil))
+
+	files, err := globs.Glob(dir, task.Sources)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if isState(file) {
+			continue
+		}
+
+		sum, err := hashFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = file
+		}
+
+		fp.Sources[filepath.ToSlash(rel)] = sum
+	}
+
+	return fp, nil
+}
+
+// Status explains whether a task is up to date.
+type Status struct {
+	Task     string
+	UpToDate bool
+	Reasons  []string
+}
+
+// Check compares the previous fingerprint with the current one and
+// verifies that the task's generated files exist. A nil previous
+// fingerprint means the task has never completed.
+func Check(task *schema.Task, prev *Fingerprint, next *Fingerprint, dir string) (*Status, error) {
+	status := &Status{Task: task.Id, Reasons: []string{}}
+
+	if len(task.Sources) == 0 {
+		status.Reasons = append(status.Reasons, "task does not declare any sources")
+		return status, nil
+	}
+
+	if prev == nil {
+		status.Reasons = append(status.Reasons, "task has not run before")
+		return status, nil
+	}
+
+	if prev.Algorithm != next.Algorithm {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("fingerprint algorithm changed from %s to %s", prev.Algorithm, next.Algorithm))
+		return status, nil
+	}
+
+	if prev.Definition != next.Definition {
+		status.Reasons = append(status.Reasons, "task definition changed")
+	}
+
+	if prev.Env != next.Env {
+		status.Reasons = append(status.Reasons, "task environment changed")
+	}
+
+	for _, file := range sortedKeys(next.Sources) {
+		sum, ok := prev.Sources[file]
+		if !ok {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("source %s was added", file))
+			continue
+		}
+
+		if sum != next.Sources[file] {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("source %s changed", file))
+		}
+	}
+
+	for _, file := range sortedKeys(prev.Sources) {
+		if _, ok := next.Sources[file]; !ok {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("source %s was removed", file))
+		}
+	}
+
+	for _, pattern := range task.Generates {
+		files, err := globs.Glob(dir, []string{pattern})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(files) == 0 {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("output %s does not exist", pattern))
+		}
+	}
+
+	status.UpToDate = len(status.Reasons) == 0
+	return status, nil
+}
+
+// isState reports whether the file is below a StateDir, the
+// fingerprints and their temporary files included.
+func isState(file string) bool {
+	return strings.Contains(filepath.ToSlash(file), "/"+StateDir+"/")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newHash() hash.Hash {
+	return HashType.HashNew()()
+}
+
+func digest(data []byte) string {
+	h := newHash()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
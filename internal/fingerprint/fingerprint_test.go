@@ -0,0 +1,92 @@
+package fingerprint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/fingerprint"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTask() *schema.Task {
+	run := "cat src/*.txt > out.txt"
+	return &schema.Task{
+		Id:        "gen",
+		Run:       &run,
+		Sources:   []string{"src/*.txt"},
+		Generates: []string{"out.txt"},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	assert.NoError(t, os.MkdirAll(src, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644))
+
+	task := newTask()
+	env := map[string]string{"A": "1"}
+	store := fingerprint.NewStore(filepath.Join(dir, fingerprint.StateDir))
+
+	next, err := fingerprint.Compute(task, env, dir)
+	assert.NoError(t, err)
+	assert.Contains(t, next.Sources, "src/a.txt")
+
+	status, err := fingerprint.Check(task, nil, next, dir)
+	assert.NoError(t, err)
+	assert.False(t, status.UpToDate)
+	assert.Equal(t, []string{"task has not run before"}, status.Reasons)
+
+	assert.NoError(t, store.Save(next))
+	prev, ok, err := store.Load("gen")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the output does not exist yet.
+	status, err = fingerprint.Check(task, prev, next, dir)
+	assert.NoError(t, err)
+	assert.False(t, status.UpToDate)
+	assert.Equal(t, []string{"output out.txt does not exist"}, status.Reasons)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "out.txt"), []byte("a"), 0644))
+	status, err = fingerprint.Check(task, prev, next, dir)
+	assert.NoError(t, err)
+	assert.True(t, status.UpToDate)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("changed"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "b.txt"), []byte("b"), 0644))
+	next, err = fingerprint.Compute(task, map[string]string{"A": "2"}, dir)
+	assert.NoError(t, err)
+
+	status, err = fingerprint.Check(task, prev, next, dir)
+	assert.NoError(t, err)
+	assert.False(t, status.UpToDate)
+	assert.Equal(t, []string{
+		"task environment changed",
+		"source src/a.txt changed",
+		"source src/b.txt was added",
+	}, status.Reasons)
+}
+
+func TestCheck_NoSources(t *testing.T) {
+	task := newTask()
+	task.Sources = nil
+
+	next, err := fingerprint.Compute(task, nil, t.TempDir())
+	assert.NoError(t, err)
+
+	status, err := fingerprint.Check(task, next, next, t.TempDir())
+	assert.NoError(t, err)
+	assert.False(t, status.UpToDate)
+}
+
+func TestStore_Missing(t *testing.T) {
+	store := fingerprint.NewStore(t.TempDir())
+	fp, ok, err := store.Load("nope")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, fp)
+	assert.NoError(t, store.Remove("nope"))
+}
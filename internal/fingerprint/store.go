@@ -0,0 +1,90 @@
+package fingerprint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// StateDir is the directory, relative to the runfile, where
+// fingerprints are stored.
+const StateDir = ".run/state"
+
+// Store persists fingerprints as json files, one per task.
+type Store struct {
+	Dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Load returns the stored fingerprint for the task. The second
+// return value is false if no fingerprint was stored.
+func (s *Store) Load(task string) (*Fingerprint, bool, error) {
+	data, err := os.ReadFile(s.path(task))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var fp Fingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		// a corrupt fingerprint is treated as missing so that
+		// the task simply runs again.
+		return nil, false, nil
+	}
+
+	// different task names can map to the same file name.
+	if fp.Task != task {
+		return nil, false, nil
+	}
+
+	return &fp, true, nil
+}
+
+// Save writes the fingerprint to the store, replacing any previous
+// fingerprint for the same task.
+func (s *Store) Save(fp *Fingerprint) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.path(fp.Task)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Remove deletes the stored fingerprint for the task.
+func (s *Store) Remove(task string) error {
+	err := os.Remove(s.path(task))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) path(task string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, task)
+
+	return filepath.Join(s.Dir, name+".json")
+}
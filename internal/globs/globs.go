@@ -0,0 +1,174 @@
+package globs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HasMeta reports whether the pattern contains any glob meta characters.
+func HasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// Match reports whether name matches the slash separated pattern.
+// In addition to the syntax supported by path.Match, a path segment
+// of "**" matches zero or more directories.
+func Match(pattern, name string) bool {
+	pattern = filepath.ToSlash(pattern)
+	name = filepath.ToSlash(name)
+
+	return matchSegments(splitSegments(pattern), splitSegments(name))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		p := pattern[0]
+		if p == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(p, name[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
+
+func splitSegments(s string) []string {
+	parts := strings.Split(s, "/")
+	segments := make([]string, 0, len(parts))
+	for i, p := range parts {
+		if p == "" && i > 0 {
+			continue
+		}
+
+		if p == "." {
+			continue
+		}
+
+		segments = append(segments, p)
+	}
+
+	return segments
+}
+
+// Glob returns the files below dir that match any of the patterns.
+// Relative patterns are resolved against dir and patterns prefixed
+// with "!" exclude previously matched files. The returned paths are
+// absolute, cleaned and sorted.
+func Glob(dir string, patterns []string) ([]string, error) {
+	matches := map[string]bool{}
+
+	for _, pattern := range patterns {
+		exclude := false
+		if strings.HasPrefix(pattern, "!") {
+			exclude = true
+			pattern = pattern[1:]
+		}
+
+		if pattern == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+
+		pattern = filepath.Clean(pattern)
+
+		if exclude {
+			for m := range matches {
+				if Match(pattern, m) {
+					delete(matches, m)
+				}
+			}
+			continue
+		}
+
+		if !HasMeta(pattern) {
+			fi, err := os.Stat(pattern)
+			if err != nil {
+				continue
+			}
+
+			if !fi.IsDir() {
+				matches[pattern] = true
+				continue
+			}
+
+			// a plain directory includes everything below it.
+			pattern = filepath.Join(pattern, "**")
+		}
+
+		root := staticPrefix(pattern)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) || os.IsPermission(err) {
+					return nil
+				}
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			if Match(pattern, p) {
+				matches[p] = true
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]string, 0, len(matches))
+	for m := range matches {
+		results = append(results, m)
+	}
+
+	sort.Strings(results)
+	return results, nil
+}
+
+// staticPrefix returns the leading directory of the pattern that
+// does not contain any meta characters.
+func staticPrefix(pattern string) string {
+	dir := pattern
+	for HasMeta(dir) {
+		next := filepath.Dir(dir)
+		if next == dir {
+			break
+		}
+		dir = next
+	}
+
+	return dir
+}
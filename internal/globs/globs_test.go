@@ -0,0 +1,58 @@
+package globs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/globs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/sub/main.go", true},
+		{"cmd/**", "cmd/sub/main.go", true},
+		{"cmd/**/main.go", "cmd/main.go", true},
+		{"cmd/**/main.go", "internal/main.go", false},
+		{"src/?.txt", "src/a.txt", true},
+		{"src/[ab].txt", "src/c.txt", false},
+		{"/abs/**/*.txt", "/abs/x/y.txt", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, globs.Match(tt.pattern, tt.name), "%s ~ %s", tt.pattern, tt.name)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"a.txt", "b.md", "sub/c.txt", "sub/deep/d.txt"}
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, os.WriteFile(p, []byte(f), 0644))
+	}
+
+	matches, err := globs.Glob(dir, []string{"**/*.txt", "!sub/deep/**"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, "sub", "c.txt"),
+	}, matches)
+
+	matches, err = globs.Glob(dir, []string{"sub"})
+	assert.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	matches, err = globs.Glob(dir, []string{"missing/*.txt"})
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+}
@@ -0,0 +1,117 @@
+// Package osinfo detects the facts schema.OS holds about an operating
+// system: its platform, architecture, distribution and versions, on
+// the local machine or on a host over ssh.
+package osinfo
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hyprxlabs/run/internal/dotenv"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// OSReleasePaths are the os-release files read on linux, in order.
+var OSReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// families are the distributions other distributions derive from,
+// in the order they are picked from the ID and ID_LIKE of os-release.
+var families = []string{"debian", "rhel", "suse", "arch", "alpine", "gentoo", "fedora"}
+
+// Local returns the facts about the local machine, detected once.
+var Local = sync.OnceValues(Detect)
+
+// Detect returns the facts about the local machine. On linux they
+// come from os-release, uname and runtime.GOARCH, on other platforms
+// only the platform and architecture are known.
+func Detect() (*schema.OS, error) {
+	return detect()
+}
+
+// ParseOSRelease returns the variables of an os-release file.
+func ParseOSRelease(data string) (map[string]string, error) {
+	doc, err := dotenv.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	release := map[string]string{}
+	for _, key := range doc.Keys() {
+		release[key], _ = doc.Get(key)
+	}
+
+	return release, nil
+}
+
+// FromOSRelease sets the variant, family, codename and version of o
+// from the variables of os-release. The variant is the ID of the
+// distribution and the family the distribution it derives from, or
+// the variant itself, e.g. debian for ubuntu.
+func FromOSRelease(o *schema.OS, release map[string]string) {
+	o.Variant = strings.ToLower(release["ID"])
+	o.Version = release["VERSION_ID"]
+
+	o.Codename = release["VERSION_CODENAME"]
+	if o.Codename == "" {
+		o.Codename = release["UBUNTU_CODENAME"]
+	}
+
+	candidates := append([]string{o.Variant}, strings.Fields(strings.ToLower(release["ID_LIKE"]))...)
+	o.Family = candidates[len(candidates)-1]
+	for _, family := range families {
+		for _, candidate := range candidates {
+			if candidate == family {
+				o.Family = family
+				return
+			}
+		}
+	}
+}
+
+// Arch returns the GOARCH name of the machine hardware name printed by
+// 'uname -m', e.g. amd64 for x86_64.
+func Arch(machine string) string {
+	switch machine = strings.ToLower(strings.TrimSpace(machine)); machine {
+	case "x86_64", "x64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "i386", "i486", "i586", "i686", "x86":
+		return "386"
+	case "armv6l", "armv7l", "armv8l":
+		return "arm"
+	case "loongarch64":
+		return "loong64"
+	default:
+		return machine
+	}
+}
+
+// Vars returns the facts as the variables tasks see them, e.g.
+// RUN_OS_FAMILY, so conditions and templates can read them like any
+// other variable.
+func Vars(o *schema.OS) map[string]string {
+	if o == nil {
+		return map[string]string{}
+	}
+
+	return map[string]string{
+		"RUN_OS_PLATFORM":      o.Platform,
+		"RUN_OS_ARCH":          o.Arch,
+		"RUN_OS_VARIANT":       o.Variant,
+		"RUN_OS_FAMILY":        o.Family,
+		"RUN_OS_CODENAME":      o.Codename,
+		"RUN_OS_VERSION":       o.Version,
+		"RUN_OS_BUILD_VERSION": o.BuildVersion,
+	}
+}
+
+// platform returns the normalized platform of the local machine.
+func platform() string {
+	if p, ok := schema.NormalizePlatform(runtime.GOOS); ok {
+		return p
+	}
+
+	return runtime.GOOS
+}
@@ -0,0 +1,53 @@
+package osinfo
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+func detect() (*schema.OS, error) {
+	o := &schema.OS{Platform: platform(), Arch: runtime.GOARCH}
+
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err == nil {
+		o.BuildVersion = utsString(uts.Release[:])
+	}
+
+	for _, path := range OSReleasePaths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return o, err
+		}
+
+		release, err := ParseOSRelease(string(data))
+		if err != nil {
+			return o, err
+		}
+
+		FromOSRelease(o, release)
+		break
+	}
+
+	return o, nil
+}
+
+// utsString returns the NUL-terminated field of syscall.Utsname, whose
+// type differs by architecture.
+func utsString[T int8 | uint8](field []T) string {
+	var sb strings.Builder
+	for _, c := range field {
+		if c == 0 {
+			break
+		}
+		sb.WriteByte(byte(c))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package osinfo
+
+import (
+	"runtime"
+
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+func detect() (*schema.OS, error) {
+	return &schema.OS{Platform: platform(), Arch: runtime.GOARCH}, nil
+}
@@ -0,0 +1,91 @@
+package osinfo_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/osinfo"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromOSRelease(t *testing.T) {
+	for name, expected := range map[string]schema.OS{
+		"debian": {Variant: "debian", Family: "debian", Codename: "bookworm", Version: "12"},
+		"alpine": {Variant: "alpine", Family: "alpine", Version: "3.20.3"},
+		"rhel":   {Variant: "rhel", Family: "rhel", Version: "9.4"},
+		"ubuntu": {Variant: "ubuntu", Family: "debian", Codename: "noble", Version: "24.04"},
+	} {
+		data, err := os.ReadFile(filepath.Join("testdata", name+".os-release"))
+		require.NoError(t, err)
+
+		release, err := osinfo.ParseOSRelease(string(data))
+		require.NoError(t, err, name)
+
+		var o schema.OS
+		osinfo.FromOSRelease(&o, release)
+		assert.Equal(t, expected, o, name)
+	}
+}
+
+func TestFromOSRelease_DerivedFamily(t *testing.T) {
+	var o schema.OS
+	osinfo.FromOSRelease(&o, map[string]string{"ID": "rocky", "ID_LIKE": "rhel centos fedora"})
+	assert.Equal(t, "rhel", o.Family)
+
+	osinfo.FromOSRelease(&o, map[string]string{"ID": "nixos"})
+	assert.Equal(t, "nixos", o.Family)
+}
+
+func TestArch(t *testing.T) {
+	assert.Equal(t, "amd64", osinfo.Arch("x86_64\n"))
+	assert.Equal(t, "arm64", osinfo.Arch("aarch64"))
+	assert.Equal(t, "arm", osinfo.Arch("armv7l"))
+	assert.Equal(t, "riscv64", osinfo.Arch("riscv64"))
+}
+
+func TestParseRemote(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ubuntu.os-release"))
+	require.NoError(t, err)
+
+	o, err := osinfo.ParseRemote("Linux\n6.8.0-45-generic\naarch64\n" + string(data))
+	require.NoError(t, err)
+	assert.Equal(t, &schema.OS{
+		Platform:     "linux",
+		Arch:         "arm64",
+		Variant:      "ubuntu",
+		Family:       "debian",
+		Codename:     "noble",
+		Version:      "24.04",
+		BuildVersion: "6.8.0-45-generic",
+	}, o)
+
+	o, err = osinfo.ParseRemote("Darwin\n24.0.0\narm64\n")
+	require.NoError(t, err)
+	assert.Equal(t, &schema.OS{Platform: "darwin", Arch: "arm64", BuildVersion: "24.0.0"}, o)
+
+	_, err = osinfo.ParseRemote("FreeBSD\n14.1\namd64\n")
+	assert.EqualError(t, err, "unsupported platform 'FreeBSD'")
+}
+
+func TestSSHArgs(t *testing.T) {
+	port := uint(2222)
+	user := "deploy"
+	identity := "~/.ssh/id_deploy"
+	host := &schema.HostEntry{Host: "db1", Port: &port, User: &user, IdentityFile: &identity}
+
+	assert.Equal(t,
+		[]string{"-o", "BatchMode=yes", "-p", "2222", "-i", "~/.ssh/id_deploy", "deploy@db1", "true"},
+		osinfo.SSHArgs(host, "true"))
+	assert.Equal(t, []string{"-o", "BatchMode=yes", "db1"}, osinfo.SSHArgs(&schema.HostEntry{Host: "db1"}))
+}
+
+func TestDetect(t *testing.T) {
+	o, err := osinfo.Detect()
+	require.NoError(t, err)
+	assert.Equal(t, runtime.GOARCH, o.Arch)
+	assert.Equal(t, o.Platform, osinfo.Vars(o)["RUN_OS_PLATFORM"])
+}
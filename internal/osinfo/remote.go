@@ -0,0 +1,110 @@
+package osinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// remoteScript prints what DetectRemote reads: the kernel name,
+// release and machine, followed by os-release when the host has one.
+const remoteScript = "uname -s; uname -r; uname -m; cat /etc/os-release 2>/dev/null || cat /usr/lib/os-release 2>/dev/null || true"
+
+// SSHArgs returns the arguments of ssh that run the command on the
+// host. ssh never prompts, so hosts must accept a key or the agent.
+func SSHArgs(host *schema.HostEntry, command ...string) []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if host.Port != nil {
+		args = append(args, "-p", strconv.FormatUint(uint64(*host.Port), 10))
+	}
+
+	if host.IdentityFile != nil && *host.IdentityFile != "" {
+		args = append(args, "-i", *host.IdentityFile)
+	}
+
+	target := host.Host
+	if host.User != nil && *host.User != "" {
+		target = *host.User + "@" + target
+	}
+
+	return append(append(args, target), command...)
+}
+
+// DetectRemote returns the facts about the host, read over ssh.
+func DetectRemote(ctx context.Context, host *schema.HostEntry) (*schema.OS, error) {
+	cmd := exec.NewContext(ctx, "ssh", SSHArgs(host, remoteScript)...)
+	cmd.DisableLogger()
+	out, err := cmd.Output()
+	if err != nil {
+		if stderr := strings.TrimSpace(string(out.Stderr)); stderr != "" {
+			return nil, fmt.Errorf("detect os of host '%s': %w: %s", host.Host, err, stderr)
+		}
+		return nil, fmt.Errorf("detect os of host '%s': %w", host.Host, err)
+	}
+
+	o, err := ParseRemote(string(out.Stdout))
+	if err != nil {
+		return nil, fmt.Errorf("detect os of host '%s': %w", host.Host, err)
+	}
+
+	return o, nil
+}
+
+// ParseRemote reads the output of the script DetectRemote runs.
+func ParseRemote(output string) (*schema.OS, error) {
+	lines := strings.SplitN(strings.ReplaceAll(output, "\r\n", "\n"), "\n", 4)
+	if len(lines) < 3 {
+		return nil, errors.New("unexpected output of uname")
+	}
+
+	name := strings.TrimSpace(lines[0])
+	platform, ok := schema.NormalizePlatform(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported platform '%s'", name)
+	}
+
+	o := &schema.OS{
+		Platform:     platform,
+		Arch:         Arch(lines[2]),
+		BuildVersion: strings.TrimSpace(lines[1]),
+	}
+
+	if len(lines) == 4 && strings.TrimSpace(lines[3]) != "" {
+		release, err := ParseOSRelease(lines[3])
+		if err != nil {
+			return nil, err
+		}
+		FromOSRelease(o, release)
+	}
+
+	return o, nil
+}
+
+// DetectHosts fills the os of the hosts that do not declare a
+// platform, detecting it over ssh. It returns the errors of the hosts
+// it could not detect, keyed by host, after trying every host.
+func DetectHosts(ctx context.Context, hosts *schema.Hosts) map[string]error {
+	errs := map[string]error{}
+	for _, key := range hosts.Keys() {
+		host, ok := hosts.Get(key)
+		if !ok || host.OS != nil && host.OS.Platform != "" {
+			continue
+		}
+
+		o, err := DetectRemote(ctx, host)
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+
+		host.OS = o
+		hosts.Set(key, host)
+	}
+
+	return errs
+}
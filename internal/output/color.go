@@ -0,0 +1,38 @@
+package output
+
+import (
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// palette holds the ANSI colors used for prefixes. Red is left out
+// as it reads like an error.
+var palette = []int{36, 32, 33, 34, 35, 96, 92, 93, 94, 95}
+
+// ColorEnabled reports whether colors should be written to w: w must
+// be a terminal and NO_COLOR must not be set.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Color returns the ANSI color of the source with the given name. A
+// name always gets the same color.
+func Color(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// Colorize wraps text in the color of the source with the given name.
+func Colorize(name, text string) string {
+	return "\x1b[" + strconv.Itoa(Color(name)) + "m" + text + "\x1b[0m"
+}
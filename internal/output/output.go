@@ -0,0 +1,161 @@
+// Package output writes the output of tasks and hosts that run at the
+// same time, so the lines of one source can be told apart from the
+// lines of another.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Mode controls how the output of a source is written.
+type Mode int
+
+const (
+	// Interleaved writes the output as it arrives, unchanged.
+	Interleaved Mode = iota
+
+	// Prefixed writes whole lines, each prefixed with the name of
+	// its source.
+	Prefixed
+
+	// Grouped buffers the output of a source and writes it as one
+	// block when the source closes.
+	Grouped
+
+	// Quiet discards the output.
+	Quiet
+)
+
+var modes = []string{"interleaved", "prefixed", "grouped", "quiet"}
+
+func (m Mode) String() string {
+	if int(m) < len(modes) {
+		return modes[m]
+	}
+
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ParseMode returns the mode with the given name.
+func ParseMode(name string) (Mode, error) {
+	for i, mode := range modes {
+		if strings.EqualFold(name, mode) {
+			return Mode(i), nil
+		}
+	}
+
+	return Interleaved, fmt.Errorf("invalid output mode '%s', expected one of %s", name, strings.Join(modes, ", "))
+}
+
+// Modes returns the names of the modes.
+func Modes() []string {
+	return append([]string{}, modes...)
+}
+
+// Output hands out the writers of each source. The writers of all
+// sources share the same destinations and never write at the same
+// time, so a line or block of one source is never split by another.
+type Output struct {
+	Mode   Mode
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Color enables a colored prefix in the prefixed mode.
+	Color bool
+
+	mu sync.Mutex
+}
+
+// New creates the output for the mode. Color is enabled when stdout
+// is a terminal and NO_COLOR is not set.
+func New(mode Mode, stdout, stderr io.Writer) *Output {
+	return &Output{
+		Mode:   mode,
+		Stdout: stdout,
+		Stderr: stderr,
+		Color:  ColorEnabled(stdout),
+	}
+}
+
+// Source returns the writers for the source with the given name,
+// such as a task or a host. Close the source when it is done to
+// flush what is left.
+func (o *Output) Source(name string) *Source {
+	s := &Source{}
+	switch o.Mode {
+	case Prefixed:
+		prefix := "[" + name + "] "
+		if o.Color {
+			prefix = Colorize(name, "["+name+"]") + " "
+		}
+
+		stdout := &lineWriter{mu: &o.mu, out: o.Stdout, prefix: []byte(prefix)}
+		stderr := &lineWriter{mu: &o.mu, out: o.Stderr, prefix: []byte(prefix)}
+		s.stdout, s.stderr = stdout, stderr
+		s.close = func() error {
+			// the streams are flushed in the order they were
+			// written to last.
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			first, second := stdout, stderr
+			if stdout.last > stderr.last {
+				first, second = stderr, stdout
+			}
+
+			if err := first.flush(); err != nil {
+				return err
+			}
+			return second.flush()
+		}
+	case Grouped:
+		rec := &recorder{stdout: o.Stdout, stderr: o.Stderr}
+		s.stdout = &recordWriter{rec: rec}
+		s.stderr = &recordWriter{rec: rec, stderr: true}
+		s.close = func() error {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			return rec.replay()
+		}
+	case Quiet:
+		s.stdout, s.stderr = io.Discard, io.Discard
+	default:
+		s.stdout = &lockedWriter{mu: &o.mu, out: o.Stdout}
+		s.stderr = &lockedWriter{mu: &o.mu, out: o.Stderr}
+	}
+
+	return s
+}
+
+// Source holds the writers of a single source.
+type Source struct {
+	stdout io.Writer
+	stderr io.Writer
+	close  func() error
+	once   sync.Once
+	err    error
+}
+
+// Stdout returns the writer for the standard output of the source.
+func (s *Source) Stdout() io.Writer {
+	return s.stdout
+}
+
+// Stderr returns the writer for the standard error of the source.
+func (s *Source) Stderr() io.Writer {
+	return s.stderr
+}
+
+// Close flushes partial lines and buffered output. Writes after
+// Close are not flushed.
+func (s *Source) Close() error {
+	s.once.Do(func() {
+		if s.close != nil {
+			s.err = s.close()
+		}
+	})
+
+	return s.err
+}
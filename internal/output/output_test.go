@@ -0,0 +1,150 @@
+package output_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// both records the writes to stdout and stderr in one buffer, so the
+// order across the streams can be checked.
+type both struct {
+	buf    *bytes.Buffer
+	stream string
+}
+
+func (w both) Write(p []byte) (int, error) {
+	fmt.Fprintf(w.buf, "%s:%s", w.stream, p)
+	return len(p), nil
+}
+
+func newOutput(mode output.Mode) (*output.Output, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &output.Output{Mode: mode, Stdout: both{&buf, "1"}, Stderr: both{&buf, "2"}}, &buf
+}
+
+func TestParseMode(t *testing.T) {
+	for _, name := range output.Modes() {
+		mode, err := output.ParseMode(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, mode.String())
+	}
+
+	mode, err := output.ParseMode("Grouped")
+	assert.NoError(t, err)
+	assert.Equal(t, output.Grouped, mode)
+
+	_, err = output.ParseMode("loud")
+	assert.EqualError(t, err, "invalid output mode 'loud', expected one of interleaved, prefixed, grouped, quiet")
+}
+
+func TestPrefixed(t *testing.T) {
+	o, buf := newOutput(output.Prefixed)
+	src := o.Source("build")
+
+	fmt.Fprint(src.Stdout(), "one\ntw")
+	fmt.Fprint(src.Stderr(), "oops\n")
+	fmt.Fprint(src.Stdout(), "o\nthree")
+	fmt.Fprint(src.Stderr(), "partial")
+	assert.NoError(t, src.Close())
+
+	assert.Equal(t,
+		"1:[build] one\n"+
+			"2:[build] oops\n"+
+			"1:[build] two\n"+
+			"1:[build] three\n"+
+			"2:[build] partial\n",
+		buf.String())
+}
+
+func TestPrefixed_Color(t *testing.T) {
+	o, buf := newOutput(output.Prefixed)
+	o.Color = true
+	src := o.Source("test")
+	fmt.Fprintln(src.Stdout(), "ok")
+	src.Close()
+
+	assert.Equal(t, "1:"+output.Colorize("test", "[test]")+" ok\n", buf.String())
+	assert.Equal(t, output.Color("test"), output.Color("test"))
+	assert.NotEqual(t, 31, output.Color("test"))
+}
+
+func TestPrefixed_Concurrent(t *testing.T) {
+	o, buf := newOutput(output.Prefixed)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			src := o.Source(name)
+			defer src.Close()
+			for i := 0; i < 100; i++ {
+				// each line arrives in two writes.
+				fmt.Fprint(src.Stdout(), name)
+				fmt.Fprintln(src.Stdout(), name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 300)
+	for _, line := range lines {
+		name := string(line[len("1:[")])
+		assert.Equal(t, "1:["+name+"] "+name+name, string(line))
+	}
+}
+
+func TestGrouped(t *testing.T) {
+	o, buf := newOutput(output.Grouped)
+	a := o.Source("a")
+	b := o.Source("b")
+
+	fmt.Fprint(a.Stdout(), "a1\n")
+	fmt.Fprint(b.Stdout(), "b1\n")
+	fmt.Fprint(a.Stderr(), "a2\n")
+	fmt.Fprint(a.Stdout(), "a3")
+	fmt.Fprint(b.Stderr(), "b2\n")
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, b.Close())
+	assert.NoError(t, a.Close())
+	assert.Equal(t, "1:b1\n2:b2\n1:a1\n2:a2\n1:a3", buf.String())
+}
+
+func TestInterleaved(t *testing.T) {
+	o, buf := newOutput(output.Interleaved)
+	src := o.Source("a")
+	fmt.Fprint(src.Stdout(), "a")
+	fmt.Fprint(src.Stderr(), "b\n")
+	assert.NoError(t, src.Close())
+	assert.Equal(t, "1:a2:b\n", buf.String())
+}
+
+func TestQuiet(t *testing.T) {
+	o, buf := newOutput(output.Quiet)
+	src := o.Source("a")
+	fmt.Fprintln(src.Stdout(), "a")
+	fmt.Fprintln(src.Stderr(), "b")
+	assert.NoError(t, src.Close())
+	assert.Empty(t, buf.String())
+}
+
+func TestColorEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	assert.False(t, output.ColorEnabled(&buf))
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.False(t, output.ColorEnabled(f))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, output.ColorEnabled(os.Stdout))
+}
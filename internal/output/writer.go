@@ -0,0 +1,136 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// order counts the writes of all line writers, so the streams of a
+// source can be flushed in the order they were written to.
+var order atomic.Uint64
+
+// lockedWriter writes through to out while holding the lock shared
+// by all sources.
+type lockedWriter struct {
+	mu  *sync.Mutex
+	out io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.out.Write(p)
+}
+
+// lineWriter buffers partial lines and writes each complete line with
+// the prefix in front of it.
+type lineWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix []byte
+	buf    []byte
+	last   uint64
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = order.Add(1)
+	w.buf = append(w.buf, p...)
+	end := bytes.LastIndexByte(w.buf, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+
+	lines := w.buf[:end+1]
+	var out bytes.Buffer
+	for len(lines) > 0 {
+		i := bytes.IndexByte(lines, '\n')
+		out.Write(w.prefix)
+		out.Write(lines[:i+1])
+		lines = lines[i+1:]
+	}
+
+	w.buf = append(w.buf[:0], w.buf[end+1:]...)
+	if _, err := w.out.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// flush writes the partial line that is left, ending it with a
+// newline so the next line starts with its own prefix. The caller
+// holds the lock.
+func (w *lineWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	line := append(append(append([]byte{}, w.prefix...), w.buf...), '\n')
+	w.buf = w.buf[:0]
+	_, err := w.out.Write(line)
+	return err
+}
+
+// chunk is output written to one stream.
+type chunk struct {
+	stderr bool
+	data   []byte
+}
+
+// recorder keeps the output of a source in the order it was written,
+// across both of its streams.
+type recorder struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+	chunks []chunk
+}
+
+func (r *recorder) write(stderr bool, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := len(r.chunks); n > 0 && r.chunks[n-1].stderr == stderr {
+		r.chunks[n-1].data = append(r.chunks[n-1].data, p...)
+		return
+	}
+
+	r.chunks = append(r.chunks, chunk{stderr: stderr, data: append([]byte{}, p...)})
+}
+
+// replay writes the recorded output to its streams.
+func (r *recorder) replay() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.chunks {
+		out := r.stdout
+		if c.stderr {
+			out = r.stderr
+		}
+
+		if _, err := out.Write(c.data); err != nil {
+			return err
+		}
+	}
+
+	r.chunks = nil
+	return nil
+}
+
+// recordWriter records the writes to one stream of a source.
+type recordWriter struct {
+	rec    *recorder
+	stderr bool
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.rec.write(w.stderr, p)
+	return len(p), nil
+}
@@ -0,0 +1,116 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type Item struct {
+	// Label is shown in the list and matched against the query.
+	Label string
+
+	// Detail is shown next to the label.
+	Detail string
+}
+
+type match struct {
+	index int
+	score int
+}
+
+// Filter returns the items whose label contains the characters of
+// the query in order, best matches first. Consecutive characters
+// and characters at the start of a word score higher. Items with
+// the same score keep their original order. An empty query returns
+// every item.
+func Filter(query string, items []Item) []Item {
+	indexes := FilterIndex(query, items)
+	results := make([]Item, 0, len(indexes))
+	for _, i := range indexes {
+		results = append(results, items[i])
+	}
+
+	return results
+}
+
+// FilterIndex is Filter returning the indexes of the items, which
+// tells items with the same label apart.
+func FilterIndex(query string, items []Item) []int {
+	query = strings.TrimSpace(query)
+	matches := []match{}
+	for i, item := range items {
+		if query == "" {
+			matches = append(matches, match{index: i})
+			continue
+		}
+
+		score, ok := Score(query, item.Label)
+		if ok {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].score > matches[b].score
+	})
+
+	indexes := make([]int, 0, len(matches))
+	for _, m := range matches {
+		indexes = append(indexes, m.index)
+	}
+
+	return indexes
+}
+
+// Score rates how well text matches the query. The second return
+// value is false when text does not contain every query character
+// in order. Matching ignores case.
+func Score(query string, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	score := 0
+	qi := 0
+	last := -1
+	for ti := 0; ti < len(lower) && qi < len(q); ti++ {
+		if lower[ti] != q[qi] {
+			continue
+		}
+
+		score += 1
+		if last == ti-1 {
+			score += 5
+		}
+
+		if ti == 0 {
+			score += 10
+		} else if isBoundary(t[ti-1], t[ti]) {
+			score += 8
+		}
+
+		if last >= 0 {
+			score -= ti - last - 1
+		}
+
+		last = ti
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+
+	// prefer shorter labels when everything else is equal.
+	score -= len(t) / 10
+	return score, true
+}
+
+func isBoundary(prev rune, current rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(current)
+}
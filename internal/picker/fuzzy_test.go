@@ -0,0 +1,123 @@
+package picker_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/picker"
+	"github.com/stretchr/testify/assert"
+)
+
+func labels(items []picker.Item) []string {
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.Label)
+	}
+	return names
+}
+
+func TestScore_Subsequence(t *testing.T) {
+	_, ok := picker.Score("bld", "build")
+	assert.True(t, ok)
+
+	_, ok = picker.Score("dlb", "build")
+	assert.False(t, ok)
+
+	_, ok = picker.Score("BU", "build")
+	assert.True(t, ok)
+}
+
+func TestFilter_Ranking(t *testing.T) {
+	items := []picker.Item{
+		{Label: "docs:publish"},
+		{Label: "test"},
+		{Label: "build"},
+		{Label: "db:backup"},
+	}
+
+	assert.Equal(t, []string{"build", "db:backup"}, labels(picker.Filter("b", items))[:2])
+	assert.Equal(t, []string{"db:backup", "docs:publish"}, labels(picker.Filter("db", items)))
+	assert.Equal(t, []string{"docs:publish", "db:backup"}, labels(picker.Filter("dp", items)))
+	assert.Len(t, picker.Filter("", items), 4)
+	assert.Empty(t, picker.Filter("xyz", items))
+}
+
+func TestPick_Keys(t *testing.T) {
+	items := []picker.Item{
+		{Label: "build"},
+		{Label: "bench"},
+		{Label: "test"},
+	}
+
+	var out bytes.Buffer
+	index, err := picker.Pick(strings.NewReader("b\x1b[B\r"), &out, "> ", items)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	index, err = picker.Pick(strings.NewReader("tx\x7f\r"), &out, "> ", items)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	_, err = picker.Pick(strings.NewReader("\x03"), &out, "> ", items)
+	assert.ErrorIs(t, err, picker.ErrCancelled)
+}
+
+func TestPick_DuplicateLabels(t *testing.T) {
+	items := []picker.Item{
+		{Label: "dev", Detail: "us"},
+		{Label: "dev", Detail: "eu"},
+		{Label: "prod"},
+	}
+
+	var out bytes.Buffer
+	index, err := picker.Pick(strings.NewReader("\t\r"), &out, "> ", items)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	index, err = picker.Pick(strings.NewReader("dev\x1b[B\r"), &out, "> ", items)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	assert.Equal(t, []int{0, 1}, picker.FilterIndex("dv", items))
+}
+
+func TestPick_Escape(t *testing.T) {
+	items := []picker.Item{{Label: "build"}, {Label: "test"}}
+
+	var out bytes.Buffer
+	_, err := picker.Pick(strings.NewReader("\x1b"), &out, "> ", items)
+	assert.ErrorIs(t, err, picker.ErrCancelled)
+
+	// Esc alone cancels without waiting for another key.
+	in, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("\x1b"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := picker.Pick(in, &out, "> ", items)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, picker.ErrCancelled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Esc did not cancel the picker")
+	}
+}
+
+func TestPrompt_Default(t *testing.T) {
+	var out bytes.Buffer
+	answer, err := picker.Prompt(strings.NewReader("\n"), &out, "target", "linux")
+	assert.NoError(t, err)
+	assert.Equal(t, "linux", answer)
+	assert.Equal(t, "target [linux]: ", out.String())
+
+	answer, err = picker.Prompt(strings.NewReader("darwin\n"), &out, "target", "linux")
+	assert.NoError(t, err)
+	assert.Equal(t, "darwin", answer)
+}
@@ -0,0 +1,196 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the user leaves the picker without
+// choosing an item.
+var ErrCancelled = errors.New("cancelled")
+
+// MaxVisible is the number of items shown at once.
+var MaxVisible = 10
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// PickTerminal puts the terminal in raw mode and lets the user
+// choose one of the items. It returns the index of the chosen item
+// in items.
+func PickTerminal(in *os.File, out io.Writer, prompt string, items []Item) (int, error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return -1, err
+	}
+	defer term.Restore(fd, state)
+
+	return Pick(in, out, prompt, items)
+}
+
+// Pick reads keys from in and renders a fuzzy filtered list of the
+// items to out until an item is chosen with enter. The caller is
+// responsible for putting the terminal into raw mode. It returns
+// the index of the chosen item in items.
+func Pick(in io.Reader, out io.Writer, prompt string, items []Item) (int, error) {
+	reader := bufio.NewReader(in)
+	query := []rune{}
+	selected := 0
+
+	filter := func() []int {
+		return FilterIndex(string(query), items)
+	}
+
+	visible := filter()
+	for {
+		render(out, prompt, string(query), items, visible, selected)
+
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			clear(out)
+			if err == io.EOF {
+				return -1, ErrCancelled
+			}
+			return -1, err
+		}
+
+		switch r {
+		case keyCtrlC, keyCtrlD:
+			clear(out)
+			return -1, ErrCancelled
+		case keyEnter, keyLineFeed:
+			if len(visible) == 0 {
+				continue
+			}
+			clear(out)
+			return visible[selected], nil
+		case keyBackspace, keyDelete:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				visible = filter()
+				selected = 0
+			}
+		case keyCtrlU:
+			query = query[:0]
+			visible = filter()
+			selected = 0
+		case keyTab, keyCtrlN:
+			if selected < len(visible)-1 {
+				selected++
+			}
+		case keyCtrlP:
+			if selected > 0 {
+				selected--
+			}
+		case keyEscape:
+			// a terminal writes an escape sequence at once, so an
+			// escape without buffered input is the Esc key itself.
+			if reader.Buffered() == 0 {
+				clear(out)
+				return -1, ErrCancelled
+			}
+
+			next, _, err := reader.ReadRune()
+			if err != nil || next != '[' {
+				clear(out)
+				return -1, ErrCancelled
+			}
+
+			code, _, err := reader.ReadRune()
+			if err != nil {
+				clear(out)
+				return -1, ErrCancelled
+			}
+
+			switch code {
+			case 'A':
+				if selected > 0 {
+					selected--
+				}
+			case 'B':
+				if selected < len(visible)-1 {
+					selected++
+				}
+			}
+		default:
+			if unicode.IsPrint(r) {
+				query = append(query, r)
+				visible = filter()
+				selected = 0
+			}
+		}
+	}
+}
+
+// render draws the visible items and a counter below the prompt
+// line and leaves the cursor after the query.
+func render(out io.Writer, prompt string, query string, items []Item, visible []int, selected int) {
+	var sb strings.Builder
+	sb.WriteString("\r\x1b[J")
+
+	start := 0
+	if selected >= MaxVisible {
+		start = selected - MaxVisible + 1
+	}
+
+	end := start + MaxVisible
+	if end > len(visible) {
+		end = len(visible)
+	}
+
+	lines := 0
+	for i := start; i < end; i++ {
+		item := items[visible[i]]
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+
+		sb.WriteString("\r\n")
+		sb.WriteString(marker)
+		sb.WriteString(item.Label)
+		if item.Detail != "" {
+			sb.WriteString("  \x1b[2m")
+			sb.WriteString(item.Detail)
+			sb.WriteString("\x1b[0m")
+		}
+		lines++
+	}
+
+	sb.WriteString(fmt.Sprintf("\r\n  \x1b[2m%d/%d\x1b[0m", len(visible), len(items)))
+	lines++
+
+	sb.WriteString(fmt.Sprintf("\x1b[%dA\r", lines))
+	sb.WriteString(prompt)
+	sb.WriteString(query)
+
+	io.WriteString(out, sb.String())
+}
+
+func clear(out io.Writer) {
+	io.WriteString(out, "\r\x1b[J")
+}
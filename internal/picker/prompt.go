@@ -0,0 +1,33 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompt asks the question on out and reads a line from in. The
+// default is shown in brackets and returned for an empty answer.
+func Prompt(in io.Reader, out io.Writer, question string, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", ErrCancelled
+		}
+		return "", err
+	}
+
+	answer := strings.TrimRight(line, "\r\n")
+	if answer == "" {
+		return def, nil
+	}
+
+	return answer, nil
+}
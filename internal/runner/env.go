@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/hyprxlabs/run/internal/dotenv"
+	"github.com/hyprxlabs/run/internal/env"
+	"github.com/hyprxlabs/run/internal/osinfo"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// TaskEnv is the environment a task runs with.
+type TaskEnv struct {
+	// All is the process environment merged with the declared
+	// variables.
+	All *schema.Environment
+
+	// Declared contains only the variables declared by the runfile,
+	// the task's dotenv files, its inputs and the task itself.
+	Declared map[string]string
+}
+
+// Env composes the environment for the task. Variables are applied
+// in order: process environment, the os facts as RUN_OS_* unless the
+// process environment sets them, runfile env, the runner's dotenv
+// files, the task's dotenv files, the task inputs as INPUT_<ID> and
+// finally the task env.
+// Values are expanded against the environment composed so far and
+// the task arguments as $1, $2 and so on, without reading or changing
+// the process environment.
+func (r *Runner) Env(ctx context.Context, task *schema.Task, args ...string) (*TaskEnv, error) {
+	all := schema.NewEnv()
+	for k, v := range env.All() {
+		all.Set(k, v)
+	}
+
+	facts := r.OS
+	if facts == nil {
+		facts, _ = osinfo.Local()
+	}
+	for k, v := range osinfo.Vars(facts) {
+		if !all.Has(k) {
+			all.Set(k, v)
+		}
+	}
+
+	declared := map[string]string{}
+	set := func(k, v string) error {
+		all.Set(k, v)
+		declared[k] = v
+		return nil
+	}
+
+	expansion := env.NewContext(ctx, all, args...)
+	expand := func(value string) (string, error) {
+		return expansion.Expand(value, env.WithSet(set))
+	}
+
+	for k, v := range r.Runfile.Config.Env.Iter() {
+		value, err := expand(v)
+		if err != nil {
+			return nil, err
+		}
+		set(k, value)
+	}
+
+	files := append([]string{}, r.DotEnv...)
+	files = append(files, task.DotEnv...)
+	for _, file := range files {
+		path, err := expand(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.Runfile.Dir(), path)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := dotenv.Parse(string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, k := range doc.Keys() {
+			v, _ := doc.Get(k)
+			value, err := expand(v)
+			if err != nil {
+				return nil, err
+			}
+			set(k, value)
+		}
+	}
+
+	inputs, err := r.ResolveInputs(task)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, input := range task.Inputs {
+		if value, ok := inputs[input.Id]; ok {
+			set(InputVariable(input.Id), value)
+		}
+	}
+
+	if task.Env != nil {
+		for k, v := range task.Env.Iter() {
+			value, err := expand(v)
+			if err != nil {
+				return nil, err
+			}
+			set(k, value)
+		}
+	}
+
+	return &TaskEnv{All: all, Declared: declared}, nil
+}
@@ -0,0 +1,31 @@
+package runner
+
+import (
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// RegisterExecutables adds the executables of the runfile to the
+// registry the runtimes find their executables in. The paths of an
+// executable that is already registered are searched first, while a
+// new executable is searched for by name on the PATH last.
+func RegisterExecutables(rf *schema.Runfile, registry *exec.ExecutableRegistry) {
+	for _, id := range rf.Executables.Keys() {
+		def, _ := rf.Executables.Get(id)
+		exe := &exec.Executable{
+			Name:    def.Exe,
+			Path:    def.Path,
+			Windows: def.Windows,
+			Linux:   def.Linux,
+			Darwin:  def.Darwin,
+			Arch:    def.Arch,
+		}
+
+		if !registry.Has(def.Id) {
+			exe.Windows = append(append([]string{}, def.Windows...), def.Exe)
+			exe.Linux = append(append([]string{}, def.Linux...), def.Exe)
+		}
+
+		registry.Override(def.Id, exe)
+	}
+}
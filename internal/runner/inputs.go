@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hyprxlabs/run/internal/errors"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// InputVariable returns the name of the environment variable that
+// holds the value of the input, e.g. INPUT_TARGET_OS for target-os.
+func InputVariable(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, id)
+
+	return "INPUT_" + name
+}
+
+// MissingInputs returns the declared inputs of the task that have
+// neither a given value nor a default.
+func (r *Runner) MissingInputs(task *schema.Task) []schema.Input {
+	missing := []schema.Input{}
+	for _, input := range task.Inputs {
+		if _, ok := r.inputValue(input.Id); ok {
+			continue
+		}
+
+		if _, ok := input.DefaultString(); ok {
+			continue
+		}
+
+		missing = append(missing, input)
+	}
+
+	return missing
+}
+
+// ResolveInputs returns the values of the task's declared inputs,
+// keyed by input id. Given values take precedence over defaults.
+// Values must be one of the input's selection when it declares one.
+func (r *Runner) ResolveInputs(task *schema.Task) (map[string]string, error) {
+	values := map[string]string{}
+	for _, input := range task.Inputs {
+		value, ok := r.inputValue(input.Id)
+		if !ok {
+			value, ok = input.DefaultString()
+		}
+
+		if !ok {
+			if input.IsRequired() {
+				return nil, errors.NewDetails(
+					fmt.Sprintf("task '%s' requires input '%s'", task.Id, input.Id),
+					"MissingInput",
+					input.Id)
+			}
+			continue
+		}
+
+		if len(input.Selection) > 0 {
+			valid := false
+			for _, choice := range input.Selection {
+				if choice == value {
+					valid = true
+					break
+				}
+			}
+
+			if !valid {
+				return nil, errors.NewDetails(
+					fmt.Sprintf("invalid value '%s' for input '%s' of task '%s', expected one of: %s",
+						value, input.Id, task.Id, strings.Join(input.Selection, ", ")),
+					"InvalidInput",
+					input.Id)
+			}
+		}
+
+		values[input.Id] = value
+	}
+
+	return values, nil
+}
+
+func (r *Runner) inputValue(id string) (string, bool) {
+	if r.Inputs == nil {
+		return "", false
+	}
+
+	v, ok := r.Inputs.TryGetValue(id)
+	if !ok || v == nil {
+		return "", false
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value, true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
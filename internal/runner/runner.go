@@ -0,0 +1,462 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/errors"
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/fingerprint"
+	"github.com/hyprxlabs/run/internal/output"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/hyprxlabs/run/internal/shell"
+)
+
+type Runner struct {
+	Runfile *schema.Runfile
+
+	// Force runs tasks even when their fingerprint is up to date.
+	Force bool
+
+	// Store holds the fingerprints of tasks that declare sources.
+	Store *fingerprint.Store
+
+	// Inputs holds the values given for the inputs declared by
+	// tasks, keyed by input id.
+	Inputs *schema.Inputs
+
+	// Hosts replaces the hosts and groups declared by the tasks.
+	Hosts []string
+
+	// DotEnv lists extra dotenv files loaded for every task after
+	// the runfile env.
+	DotEnv []string
+
+	// Output writes the output of each task as a source of its own,
+	// e.g. prefixed with the task name. When nil the output goes
+	// to Stdout and Stderr unchanged.
+	Output *output.Output
+
+	// Platform picks the per-platform variants of tasks, defaults to
+	// the platform run is running on.
+	Platform string
+
+	// OS holds the facts about the machine tasks run on, which tasks
+	// see as RUN_OS_* variables. Detected when nil.
+	OS *schema.OS
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func New(rf *schema.Runfile) *Runner {
+	return &Runner{
+		Runfile: rf,
+		Store:   fingerprint.NewStore(filepath.Join(rf.Dir(), fingerprint.StateDir)),
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
+}
+
+// Task returns the task with the given name as it runs on the
+// platform of the runner. The name may be prefixed with the runfile
+// name, e.g. 'project:build'.
+func (r *Runner) Task(name string) (*schema.Task, error) {
+	task, ok := r.Runfile.Tasks.Get(name)
+	if !ok && r.Runfile.Name != "" {
+		if rest, found := strings.CutPrefix(name, r.Runfile.Name+":"); found {
+			task, ok = r.Runfile.Tasks.Get(rest)
+		}
+	}
+
+	if !ok {
+		return nil, errors.NewDetails("task not found: "+name, "TaskNotFound", name)
+	}
+
+	return task.ForPlatform(r.platform()), nil
+}
+
+// platform returns the normalized platform tasks run on.
+func (r *Runner) platform() string {
+	if platform, ok := schema.NormalizePlatform(r.Platform); ok {
+		return platform
+	}
+
+	return runtime.GOOS
+}
+
+// Plan returns the tasks required to run the named tasks in the
+// order they must run. Each task appears once and always after the
+// tasks it needs.
+func (r *Runner) Plan(names ...string) ([]*schema.Task, error) {
+	plan := []*schema.Task{}
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+	stack := []string{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		task, err := r.Task(name)
+		if err != nil {
+			if len(stack) > 0 {
+				return errors.NewDetails(
+					fmt.Sprintf("task '%s' needs unknown task '%s'", stack[len(stack)-1], name),
+					"TaskNotFound",
+					name)
+			}
+			return err
+		}
+
+		if done[task.Id] {
+			return nil
+		}
+
+		if visiting[task.Id] {
+			cycle := append(stack, task.Id)
+			return errors.NewDetails(
+				"dependency cycle detected: "+strings.Join(cycle, " -> "),
+				"TaskCycle",
+				strings.Join(cycle, " -> "))
+		}
+
+		visiting[task.Id] = true
+		stack = append(stack, task.Id)
+		for _, need := range task.Needs {
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		visiting[task.Id] = false
+
+		done[task.Id] = true
+		plan = append(plan, task)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// Run runs the named task after the tasks it needs. The args are
+// passed to the named task only.
+func (r *Runner) Run(ctx context.Context, name string, args ...string) error {
+	plan, err := r.Plan(name)
+	if err != nil {
+		return err
+	}
+
+	for i, task := range plan {
+		var taskArgs []string
+		if i == len(plan)-1 {
+			taskArgs = args
+		}
+
+		if err := r.RunTask(ctx, task, taskArgs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunTask runs a single task without its needs. Tasks that declare
+// sources are skipped when their fingerprint is up to date, unless
+// Force is set.
+func (r *Runner) RunTask(ctx context.Context, task *schema.Task, args ...string) error {
+	taskEnv, err := r.Env(ctx, task, args...)
+	if err != nil {
+		return err
+	}
+
+	// extra arguments are not part of the fingerprint, so a task
+	// invoked with arguments always runs and is not recorded.
+	var next *fingerprint.Fingerprint
+	if len(task.Sources) > 0 && len(args) == 0 {
+		status, fp, err := r.status(task, taskEnv)
+		if err != nil {
+			return err
+		}
+
+		next = fp
+		if status.UpToDate && !r.Force {
+			fmt.Fprintf(r.Stderr, "task '%s' is up to date\n", task.Id)
+			return nil
+		}
+	}
+
+	if task.Timeout != nil && *task.Timeout != "" {
+		timeout, err := time.ParseDuration(*task.Timeout)
+		if err != nil {
+			return errors.NewDetails(
+				fmt.Sprintf("invalid timeout '%s' for task '%s'", *task.Timeout, task.Id),
+				"InvalidTimeout",
+				err.Error())
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if r.Output != nil {
+		src := r.Output.Source(task.Id)
+		defer src.Close()
+
+		tr := *r
+		tr.Stdout, tr.Stderr = src.Stdout(), src.Stderr()
+		r = &tr
+	}
+
+	if r.Uses(task) == shell.Name {
+		err = r.runShell(ctx, task, taskEnv, args...)
+	} else {
+		err = r.runCommand(ctx, task, taskEnv, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if next != nil {
+		// the outputs may only exist now, so the fingerprint is
+		// stored after the task completes.
+		if err := r.Store.Save(next); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) runCommand(ctx context.Context, task *schema.Task, taskEnv *TaskEnv, args ...string) error {
+	cmd, err := r.Command(ctx, task, taskEnv, args...)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+	cmd.Stdin = os.Stdin
+	if task.TTY {
+		cmd.WithTTY()
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return errors.WithCause(
+			errors.NewDetails(fmt.Sprintf("task '%s' failed: %v", task.Id, err), "TaskFailed", err.Error()),
+			err)
+	}
+
+	return nil
+}
+
+// runShell runs the task with the run-shell interpreter in this
+// process. The task args and the extra args are the positional
+// arguments of the script.
+func (r *Runner) runShell(ctx context.Context, task *schema.Task, taskEnv *TaskEnv, args ...string) error {
+	if err := r.runnable(task); err != nil {
+		return err
+	}
+
+	if task.TTY {
+		return errors.NewDetails(
+			fmt.Sprintf("task '%s' sets tty, which %s does not support", task.Id, shell.Name),
+			"NotSupported",
+			shell.Name)
+	}
+
+	sh := shell.New()
+	sh.Dir = r.Cwd(task)
+	sh.Env = map[string]string{}
+	for k, v := range taskEnv.All.Iter() {
+		sh.Env[k] = v
+	}
+	sh.Args = append(append([]string{}, task.Args...), args...)
+	sh.Stdout = r.Stdout
+	sh.Stderr = r.Stderr
+
+	code, err := sh.Run(ctx, *task.Run)
+	if err != nil {
+		return errors.WithCause(
+			errors.NewDetails(fmt.Sprintf("task '%s' failed: %v", task.Id, err), "TaskFailed", err.Error()),
+			err)
+	}
+
+	if code != 0 {
+		msg := fmt.Sprintf("exit status %d", code)
+		return errors.WithCause(
+			errors.NewDetails(fmt.Sprintf("task '%s' failed: %s", task.Id, msg), "TaskFailed", msg),
+			&exec.ExitError{Result: &exec.Result{FileName: shell.Name, Code: code}})
+	}
+
+	return nil
+}
+
+// Uses returns the name of the runtime that runs the task: 'uses',
+// the runfile shell or the default shell.
+func (r *Runner) Uses(task *schema.Task) string {
+	uses := DefaultShell()
+	if r.Runfile.Config.Shell != nil && *r.Runfile.Config.Shell != "" {
+		uses = *r.Runfile.Config.Shell
+	}
+
+	if task.Uses != nil && *task.Uses != "" {
+		uses = *task.Uses
+	}
+
+	return uses
+}
+
+// runnable reports tasks that do not support the platform, target
+// hosts or have nothing to run.
+func (r *Runner) runnable(task *schema.Task) error {
+	if platform := r.platform(); !task.Supports(platform) {
+		return errors.NewDetails(
+			fmt.Sprintf("task '%s' does not support %s, only %s", task.Id, platform, strings.Join(task.Platforms(), ", ")),
+			"UnsupportedPlatform",
+			task.Id)
+	}
+
+	hosts := task.Hosts
+	if len(r.Hosts) > 0 {
+		hosts = r.Hosts
+	}
+
+	if len(hosts) > 0 {
+		for _, host := range hosts {
+			if _, ok := r.Runfile.Hosts.FindAll(host); !ok {
+				return errors.NewDetails(
+					fmt.Sprintf("task '%s' targets unknown host or group '%s'", task.Id, host),
+					"UnknownHost",
+					host)
+			}
+		}
+
+		return errors.NewDetails(
+			fmt.Sprintf("task '%s' targets remote hosts, which are not supported yet", task.Id),
+			"NotSupported",
+			strings.Join(hosts, ", "))
+	}
+
+	if task.Run == nil || strings.TrimSpace(*task.Run) == "" {
+		return errors.NewDetails(fmt.Sprintf("task '%s' has nothing to run", task.Id), "EmptyTask", task.Id)
+	}
+
+	return nil
+}
+
+// Command creates the command for the task using the runtime named
+// by 'uses', the runfile shell or the default shell.
+func (r *Runner) Command(ctx context.Context, task *schema.Task, taskEnv *TaskEnv, args ...string) (*exec.Cmd, error) {
+	if err := r.runnable(task); err != nil {
+		return nil, err
+	}
+
+	uses := r.Uses(task)
+	f, ok := r.lookupRuntime(uses)
+	if !ok {
+		return nil, errors.NewDetails(fmt.Sprintf("task '%s' uses unknown runtime '%s'", task.Id, uses), "UnknownRuntime", uses)
+	}
+
+	allArgs := append([]string{}, task.Args...)
+	allArgs = append(allArgs, args...)
+
+	script := &Script{
+		Run:         *task.Run,
+		With:        task.With,
+		Dir:         r.Cwd(task),
+		RunfileDir:  r.Runfile.Dir(),
+		Permissions: task.Permissions,
+	}
+
+	cmd := f(ctx, script, allArgs...)
+	if cmd.Err != nil {
+		return nil, cmd.Err
+	}
+
+	cmd.Dir = script.Dir
+
+	environ := make([]string, 0, taskEnv.All.Len())
+	for k, v := range taskEnv.All.Iter() {
+		environ = append(environ, k+"="+v)
+	}
+	cmd.Env = environ
+
+	return cmd, nil
+}
+
+// Cwd returns the working directory of the task. Relative
+// directories are resolved against the runfile directory.
+func (r *Runner) Cwd(task *schema.Task) string {
+	dir := r.Runfile.Dir()
+	if task.Cwd == nil || *task.Cwd == "" {
+		return dir
+	}
+
+	if filepath.IsAbs(*task.Cwd) {
+		return *task.Cwd
+	}
+
+	return filepath.Join(dir, *task.Cwd)
+}
+
+// Status explains whether the task would run or be skipped because
+// its fingerprint is up to date.
+func (r *Runner) Status(name string) (*fingerprint.Status, error) {
+	task, err := r.Task(name)
+	if err != nil {
+		return nil, err
+	}
+
+	taskEnv, err := r.Env(context.Background(), task)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _, err := r.status(task, taskEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Force {
+		status.UpToDate = false
+		status.Reasons = append(status.Reasons, "--force was given")
+	}
+
+	return status, nil
+}
+
+func (r *Runner) status(task *schema.Task, taskEnv *TaskEnv) (*fingerprint.Status, *fingerprint.Fingerprint, error) {
+	dir := r.Cwd(task)
+	next, err := fingerprint.Compute(task, taskEnv.Declared, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prev, _, err := r.Store.Load(task.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status, err := fingerprint.Check(task, prev, next, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return status, next, nil
+}
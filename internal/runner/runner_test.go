@@ -0,0 +1,519 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestPlan_Order(t *testing.T) {
+	rf := &schema.Runfile{}
+	rf.Tasks.Set(&schema.Task{Id: "a", Needs: []string{"b", "c"}})
+	rf.Tasks.Set(&schema.Task{Id: "b", Needs: []string{"c"}})
+	rf.Tasks.Set(&schema.Task{Id: "c"})
+
+	plan, err := New(rf).Plan("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "c, b, a", taskNames(plan))
+}
+
+func TestPlan_Cycle(t *testing.T) {
+	rf := &schema.Runfile{}
+	rf.Tasks.Set(&schema.Task{Id: "a", Needs: []string{"b"}})
+	rf.Tasks.Set(&schema.Task{Id: "b", Needs: []string{"a"}})
+
+	_, err := New(rf).Plan("a")
+	assert.EqualError(t, err, "dependency cycle detected: a -> b -> a")
+}
+
+func TestPlan_UnknownNeed(t *testing.T) {
+	rf := &schema.Runfile{}
+	rf.Tasks.Set(&schema.Task{Id: "a", Needs: []string{"missing"}})
+
+	_, err := New(rf).Plan("a")
+	assert.EqualError(t, err, "task 'a' needs unknown task 'missing'")
+}
+
+func TestTask_ProjectPrefix(t *testing.T) {
+	rf := &schema.Runfile{Name: "demo"}
+	rf.Tasks.Set(&schema.Task{Id: "build"})
+
+	task, err := New(rf).Task("demo:build")
+	assert.NoError(t, err)
+	assert.Equal(t, "build", task.Id)
+
+	_, err = New(rf).Task("other:build")
+	assert.Error(t, err)
+}
+
+func TestRunTask_RunShell(t *testing.T) {
+	dir := t.TempDir()
+	rf := &schema.Runfile{Path: filepath.Join(dir, "runfile.yaml")}
+	script := "GREETING=hello\necho \"$GREETING $1\" > out.txt && echo ok || echo failed\necho $(wc -c < out.txt)"
+	uses := "run-shell"
+	rf.Tasks.Set(&schema.Task{Id: "greet", Run: &script, Uses: &uses, Args: []string{"world"}})
+
+	var out bytes.Buffer
+	r := New(rf)
+	r.Stdout = &out
+	r.Stderr = &out
+	task, _ := r.Task("greet")
+
+	assert.NoError(t, r.RunTask(context.Background(), task))
+	assert.Equal(t, "ok\n12\n", out.String())
+
+	fail := "exit 3"
+	rf.Tasks.Set(&schema.Task{Id: "fail", Run: &fail, Uses: &uses})
+	task, _ = r.Task("fail")
+	err := r.RunTask(context.Background(), task)
+	assert.EqualError(t, err, "task 'fail' failed: exit status 3")
+
+	code, ok := exec.ExitCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, code)
+}
+
+func TestRunTask_UpToDate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "src.txt"), []byte("one"), 0o644))
+
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+tasks:
+  build:
+    uses: run-shell
+    run: echo built >> log.txt && cp src.txt out.txt
+    sources: [src.txt]
+    generates: [out.txt]
+  broken:
+    uses: run-shell
+    run: echo broken >> broken.txt && exit 1
+    sources: [src.txt]
+`), &rf)
+	assert.NoError(t, err)
+	rf.Path = filepath.Join(dir, "runfile.yaml")
+
+	var stderr bytes.Buffer
+	r := New(&rf)
+	r.Stdout = &bytes.Buffer{}
+	r.Stderr = &stderr
+	task, _ := r.Task("build")
+
+	runs := func(file string) int {
+		data, _ := os.ReadFile(filepath.Join(dir, file))
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	tests := []struct {
+		name    string
+		prepare func()
+		args    []string
+		runs    int
+		skipped bool
+	}{
+		{name: "first run", runs: 1},
+		{name: "up to date", runs: 1, skipped: true},
+		{name: "force", prepare: func() { r.Force = true }, runs: 2},
+		{name: "up to date after force", prepare: func() { r.Force = false }, runs: 2, skipped: true},
+		{name: "args bypass the fingerprint", args: []string{"extra"}, runs: 3},
+		{name: "changed source", prepare: func() {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "src.txt"), []byte("two"), 0o644))
+		}, runs: 4},
+		{name: "up to date after change", runs: 4, skipped: true},
+	}
+
+	for _, tt := range tests {
+		if tt.prepare != nil {
+			tt.prepare()
+		}
+
+		stderr.Reset()
+		assert.NoError(t, r.RunTask(context.Background(), task, tt.args...), tt.name)
+		assert.Equal(t, tt.runs, runs("log.txt"), tt.name)
+		assert.Equal(t, tt.skipped, strings.Contains(stderr.String(), "task 'build' is up to date"), tt.name)
+	}
+
+	// a failed task does not store its fingerprint, so it runs again.
+	broken, _ := r.Task("broken")
+	assert.Error(t, r.RunTask(context.Background(), broken))
+	_, ok, err := r.Store.Load("broken")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Error(t, r.RunTask(context.Background(), broken))
+	assert.Equal(t, 2, runs("broken.txt"))
+}
+
+func TestRunTask_UpToDate_AllSources(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(t.TempDir(), "log.txt")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "src.txt"), []byte("one"), 0o644))
+
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+tasks:
+  build:
+    uses: run-shell
+    run: echo built >> "$LOG"
+    env:
+      LOG: `+log+`
+    sources: ["**"]
+`), &rf)
+	assert.NoError(t, err)
+	rf.Path = filepath.Join(dir, "runfile.yaml")
+
+	var stderr bytes.Buffer
+	r := New(&rf)
+	r.Stdout = &bytes.Buffer{}
+	r.Stderr = &stderr
+	task, _ := r.Task("build")
+
+	// the stored fingerprint is below dir, yet it is not a source.
+	for range 2 {
+		assert.NoError(t, r.RunTask(context.Background(), task))
+	}
+
+	data, _ := os.ReadFile(log)
+	assert.Equal(t, "built\n", string(data))
+	assert.Contains(t, stderr.String(), "task 'build' is up to date")
+}
+
+func TestResolveInputs(t *testing.T) {
+	var task schema.Task
+	err := yaml.Unmarshal([]byte(`
+id: deploy
+inputs:
+  - id: target
+    required: true
+    selection: [dev, prod]
+  - id: region
+    default: eu
+  - id: replicas
+    default: 2
+  - id: tag
+  - id: mode
+    default: fast
+    selection: [safe, careful]
+`), &task)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		given   map[string]any
+		want    map[string]string
+		missing []string
+		err     string
+	}{
+		{
+			name:    "missing required input",
+			given:   map[string]any{"mode": "safe"},
+			missing: []string{"target", "tag"},
+			err:     "task 'deploy' requires input 'target'",
+		},
+		{
+			name:    "defaults",
+			given:   map[string]any{"target": "dev", "mode": "safe"},
+			want:    map[string]string{"target": "dev", "region": "eu", "replicas": "2", "mode": "safe"},
+			missing: []string{"tag"},
+		},
+		{
+			name:    "given values win over defaults",
+			given:   map[string]any{"target": "prod", "region": "us", "replicas": 3, "tag": "v1", "mode": "careful"},
+			want:    map[string]string{"target": "prod", "region": "us", "replicas": "3", "tag": "v1", "mode": "careful"},
+			missing: []string{},
+		},
+		{
+			name:    "value not in selection",
+			given:   map[string]any{"target": "staging", "mode": "safe"},
+			missing: []string{"tag"},
+			err:     "invalid value 'staging' for input 'target' of task 'deploy', expected one of: dev, prod",
+		},
+		{
+			name:    "default not in selection",
+			given:   map[string]any{"target": "dev"},
+			missing: []string{"tag"},
+			err:     "invalid value 'fast' for input 'mode' of task 'deploy', expected one of: safe, careful",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(&schema.Runfile{})
+			r.Inputs = &schema.Inputs{}
+			for k, v := range tt.given {
+				r.Inputs.Set(k, v)
+			}
+
+			missing := []string{}
+			for _, input := range r.MissingInputs(&task) {
+				missing = append(missing, input.Id)
+			}
+			assert.Equal(t, tt.missing, missing)
+
+			values, err := r.ResolveInputs(&task)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestDenoAllow(t *testing.T) {
+	var task schema.Task
+	err := yaml.Unmarshal([]byte(`
+uses: deno
+run: main.ts
+permissions:
+  read: [src, /etc/hosts]
+  write: out
+  net: true
+  env: [HOME, PATH]
+  run: [git, ./bin/tool]
+`), &task)
+	assert.NoError(t, err)
+
+	dir := filepath.FromSlash("/project")
+	assert.Equal(t, []string{
+		"--allow-read=" + filepath.Join(dir, "src") + ",/etc/hosts",
+		"--allow-write=" + filepath.Join(dir, "out"),
+		"--allow-net",
+		"--allow-env=HOME,PATH",
+		"--allow-run=git," + filepath.Join(dir, "bin/tool"),
+	}, denoAllow(task.Permissions, dir))
+
+	assert.Nil(t, denoAllow(nil, dir))
+	assert.Nil(t, denoAllow(&schema.Permissions{All: true}, dir))
+	assert.Equal(t, []string{}, denoAllow(&schema.Permissions{Net: nil, Read: &schema.Permission{Values: []string{}}}, dir))
+
+	err = yaml.Unmarshal([]byte("permissions: none"), &task)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, denoAllow(task.Permissions, dir))
+
+	err = yaml.Unmarshal([]byte("permissions: {disk: true}"), &task)
+	assert.ErrorContains(t, err, "unexpected field 'disk' in permissions")
+}
+
+func TestCommand_RunfileRuntime(t *testing.T) {
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+runtimes:
+  shx:
+    exe: sh
+    extensions: [.shx]
+    inline-args: [-c]
+  bash: {exe: sh, inline-args: [-c]}
+tasks:
+  inline:
+    uses: shx
+    run: echo hello
+  file:
+    uses: shx
+    run: script.shx
+  overridden:
+    uses: bash
+    run: echo hi
+`), &rf)
+	assert.NoError(t, err)
+	rf.Path = filepath.Join(t.TempDir(), "runfile.yaml")
+
+	r := New(&rf)
+	taskEnv := &TaskEnv{All: &schema.Environment{}}
+	for name, expected := range map[string][]string{
+		"inline":     {"-c", "echo hello"},
+		"file":       {"script.shx"},
+		"overridden": {"-c", "echo hi"},
+	} {
+		task, _ := r.Task(name)
+		cmd, err := r.Command(context.Background(), task, taskEnv)
+		assert.NoError(t, err)
+		assert.Equal(t, "sh", filepath.Base(cmd.Path), name)
+		assert.Equal(t, expected, cmd.Args[1:], name)
+	}
+}
+
+func TestTask_Platform(t *testing.T) {
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+tasks:
+  build:
+    run:
+      linux: make
+      win: nmake
+    uses: {windows: pwsh, default: bash}
+    cwd: {macos: mac}
+    env:
+      linux: {CC: gcc}
+      default: {CC: cc}
+  plain:
+    run: echo plain
+    env:
+      linux: {value: x}
+`), &rf)
+	assert.NoError(t, err)
+
+	r := New(&rf)
+	r.Platform = "linux"
+	task, err := r.Task("build")
+	assert.NoError(t, err)
+	assert.Equal(t, "make", *task.Run)
+	assert.Equal(t, "bash", *task.Uses)
+	assert.Nil(t, task.Cwd)
+	cc, _ := task.Env.Get("CC")
+	assert.Equal(t, "gcc", cc)
+	assert.NoError(t, r.runnable(task))
+
+	r.Platform = "windows"
+	task, _ = r.Task("build")
+	assert.Equal(t, "nmake", *task.Run)
+	assert.Equal(t, "pwsh", *task.Uses)
+	cc, _ = task.Env.Get("CC")
+	assert.Equal(t, "cc", cc)
+
+	r.Platform = "darwin"
+	task, _ = r.Task("build")
+	assert.Nil(t, task.Run)
+	assert.Equal(t, "mac", *task.Cwd)
+	assert.EqualError(t, r.runnable(task), "task 'build' does not support darwin, only linux, windows")
+
+	task, _ = r.Task("plain")
+	assert.NoError(t, r.runnable(task))
+	assert.True(t, task.Env.Has("linux"))
+
+	err = yaml.Unmarshal([]byte("tasks: {a: {run: {beos: x}}}"), &rf)
+	assert.ErrorContains(t, err, "unsupported platform 'beos' in 'run' field")
+}
+
+func TestEnv_OSFacts(t *testing.T) {
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+tasks:
+  pkg:
+    run: echo
+    env:
+      INSTALL: install-${RUN_OS_FAMILY}-${RUN_OS_ARCH}
+`), &rf)
+	assert.NoError(t, err)
+
+	r := New(&rf)
+	r.OS = &schema.OS{Platform: "linux", Arch: "arm64", Variant: "ubuntu", Family: "debian"}
+	task, _ := r.Task("pkg")
+	taskEnv, err := r.Env(context.Background(), task)
+	assert.NoError(t, err)
+
+	install, _ := taskEnv.All.Get("INSTALL")
+	assert.Equal(t, "install-debian-arm64", install)
+	variant, _ := taskEnv.All.Get("RUN_OS_VARIANT")
+	assert.Equal(t, "ubuntu", variant)
+	assert.NotContains(t, taskEnv.Declared, "RUN_OS_VARIANT")
+}
+
+func TestEnv_Hermetic(t *testing.T) {
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+tasks:
+  greet:
+    run: echo
+    env:
+      NAME: ${RUN_TEST_HERMETIC:=world}
+      GREETING: hello ${1:-nobody} from ${NAME}
+`), &rf)
+	assert.NoError(t, err)
+
+	r := New(&rf)
+	task, _ := r.Task("greet")
+
+	var wg sync.WaitGroup
+	envs := make([]*TaskEnv, 2)
+	for i, arg := range []string{"alice", "bob"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			envs[i], _ = r.Env(context.Background(), task, arg)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, "hello alice from world", envs[0].Declared["GREETING"])
+	assert.Equal(t, "hello bob from world", envs[1].Declared["GREETING"])
+	assert.Equal(t, "world", envs[0].Declared["RUN_TEST_HERMETIC"])
+
+	_, ok := os.LookupEnv("RUN_TEST_HERMETIC")
+	assert.False(t, ok)
+}
+
+func TestRunner_HostsAndDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, "extra.env")
+	assert.NoError(t, os.WriteFile(dotenv, []byte("EXTRA=from-file\nNAME=file\n"), 0o644))
+
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+hosts:
+  web1.example.com: {groups: [web]}
+tasks:
+  greet:
+    run: echo
+    env:
+      NAME: task
+`), &rf)
+	assert.NoError(t, err)
+	rf.Path = filepath.Join(dir, "runfile.yaml")
+
+	r := New(&rf)
+	task, _ := r.Task("greet")
+	assert.NoError(t, r.runnable(task))
+
+	r.Hosts = []string{"missing"}
+	assert.ErrorContains(t, r.runnable(task), "unknown host or group 'missing'")
+
+	r.Hosts = []string{"web"}
+	assert.ErrorContains(t, r.runnable(task), "not supported yet")
+
+	// the task env still wins over the runner's dotenv files.
+	r.DotEnv = []string{dotenv}
+	taskEnv, err := r.Env(context.Background(), task)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", taskEnv.Declared["EXTRA"])
+	assert.Equal(t, "task", taskEnv.Declared["NAME"])
+}
+
+func TestRegisterExecutables(t *testing.T) {
+	var rf schema.Runfile
+	err := yaml.Unmarshal([]byte(`
+executables:
+  bash:
+    linux: /opt/bash/bin/bash
+    arch: {macos/arm64: /opt/homebrew/bin/bash}
+  java: {linux: [/usr/lib/jvm/*/bin/java]}
+  jq: /usr/local/bin/jq
+`), &rf)
+	assert.NoError(t, err)
+
+	registry := exec.NewRegistry()
+	registry.Register("bash", &exec.Executable{Name: "bash", Linux: []string{"/bin/bash"}})
+	RegisterExecutables(&rf, registry)
+
+	bash, _ := registry.Get("bash")
+	assert.Equal(t, []string{"/opt/bash/bin/bash", "/bin/bash"}, bash.Linux)
+	assert.Equal(t, []string{"/opt/homebrew/bin/bash"}, bash.Arch["darwin/arm64"])
+	assert.Equal(t, "RUN_BASH_EXE", bash.Variable)
+
+	java, _ := registry.Get("java")
+	assert.Equal(t, []string{"/usr/lib/jvm/*/bin/java", "java"}, java.Linux)
+	assert.Equal(t, "RUN_JAVA_EXE", java.Variable)
+
+	jq, _ := registry.Get("jq")
+	assert.Equal(t, "/usr/local/bin/jq", jq.Path)
+}
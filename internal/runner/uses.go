@@ -0,0 +1,256 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/hyprxlabs/run/internal/scriptx/bash"
+	"github.com/hyprxlabs/run/internal/scriptx/bun"
+	"github.com/hyprxlabs/run/internal/scriptx/custom"
+	"github.com/hyprxlabs/run/internal/scriptx/deno"
+	"github.com/hyprxlabs/run/internal/scriptx/dotnet"
+	"github.com/hyprxlabs/run/internal/scriptx/golang"
+	"github.com/hyprxlabs/run/internal/scriptx/js"
+	"github.com/hyprxlabs/run/internal/scriptx/node"
+	"github.com/hyprxlabs/run/internal/scriptx/pwsh"
+	"github.com/hyprxlabs/run/internal/scriptx/python"
+	"github.com/hyprxlabs/run/internal/scriptx/ruby"
+	"github.com/hyprxlabs/run/internal/scriptx/sh"
+)
+
+// ScriptFunc creates the command that runs a script, which is
+// either an inline script or the path to a script file.
+type ScriptFunc func(ctx context.Context, script string, args ...string) *exec.Cmd
+
+var runtimes = map[string]ScriptFunc{
+	"bash":       bash.ScriptContext,
+	"sh":         sh.ScriptContext,
+	"pwsh":       pwsh.ScriptContext,
+	"powershell": pwsh.ScriptContext,
+	"deno":       deno.ScriptContext,
+	"node":       node.ScriptContext,
+	"bun":        bun.ScriptContext,
+	"python":     python.ScriptContext,
+	"ruby":       ruby.ScriptContext,
+	"go":         golang.ScriptContext,
+	"golang":     golang.ScriptContext,
+	"dotnet":     dotnet.ScriptContext,
+	"csharp":     dotnet.ScriptContext,
+}
+
+// Script is the script of a task and what runtimes need to know
+// about the task to run it.
+type Script struct {
+	// Run is the inline script or the path to a script file.
+	Run  string
+	With schema.With
+
+	// Dir is the working directory of the task.
+	Dir string
+
+	// RunfileDir is the directory of the runfile.
+	RunfileDir string
+
+	// Permissions limit what the script may access, nil allows
+	// everything.
+	Permissions *schema.Permissions
+}
+
+// TaskFunc creates the command that runs the script of a task for
+// runtimes that read more than the script, such as the 'with' values
+// of the task.
+type TaskFunc func(ctx context.Context, script *Script, args ...string) *exec.Cmd
+
+// taskRuntimes take precedence over the runtimes of the same name.
+var taskRuntimes = map[string]TaskFunc{
+	"bun":    bunScript,
+	"deno":   denoScript,
+	"go":     goScript,
+	"golang": goScript,
+	"node":   nodeScript,
+	"python": pythonScript,
+}
+
+// RegisterRuntime makes a runtime available to tasks through 'uses'.
+func RegisterRuntime(name string, f ScriptFunc) {
+	runtimes[strings.ToLower(name)] = f
+	delete(taskRuntimes, strings.ToLower(name))
+}
+
+// LookupRuntime returns the runtime registered under name.
+func LookupRuntime(name string) (ScriptFunc, bool) {
+	f, ok := runtimes[strings.ToLower(name)]
+	return f, ok
+}
+
+// lookupTask returns the runtime registered under name, passing the
+// task to the runtimes that read it.
+func lookupTask(name string) (TaskFunc, bool) {
+	if f, ok := taskRuntimes[strings.ToLower(name)]; ok {
+		return f, true
+	}
+
+	f, ok := LookupRuntime(name)
+	if !ok {
+		return nil, false
+	}
+
+	return func(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+		return f(ctx, script.Run, args...)
+	}, true
+}
+
+// lookupRuntime returns the runtime defined in the runfile under
+// name, or the registered runtime. Runtimes of the runfile take
+// precedence, so a runfile can change how a built-in runtime runs.
+func (r *Runner) lookupRuntime(name string) (TaskFunc, bool) {
+	def, ok := r.Runfile.Runtimes.Get(name)
+	if !ok {
+		return lookupTask(name)
+	}
+
+	rt := custom.New(def)
+	return func(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+		return rt.ScriptContext(ctx, script.Run, args...)
+	}, true
+}
+
+// goScript passes 'requires' to inline go scripts, the modules they
+// need in addition to the ones named by //go:require comments.
+func goScript(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+	requires, ok := script.With.TryGetStringSlice("requires", "require")
+	if !ok {
+		if require, found := script.With.TryGetString("requires", "require"); found {
+			requires = strings.Fields(require)
+		}
+	}
+
+	return golang.ScriptOptionsContext(ctx, script.Run, &golang.Options{Requires: requires}, args...)
+}
+
+// pythonScript runs python scripts with the project virtualenv in the
+// working directory of the task or next to the runfile.
+func pythonScript(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+	options := &python.Options{Dirs: []string{script.Dir, script.RunfileDir}}
+	return python.ScriptOptionsContext(ctx, script.Run, options, args...)
+}
+
+// nodeScript runs node scripts, passing 'type' to override the type
+// detected for inline scripts.
+func nodeScript(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+	t, err := scriptType(script.With)
+	if err != nil {
+		cmd := node.NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	options := &node.Options{Type: t, Dir: script.Dir}
+	return node.ScriptOptionsContext(ctx, script.Run, options, args...)
+}
+
+// bunScript runs bun scripts like nodeScript runs node scripts.
+func bunScript(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+	t, err := scriptType(script.With)
+	if err != nil {
+		cmd := bun.NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	options := &bun.Options{Type: t, Dir: script.Dir}
+	return bun.ScriptOptionsContext(ctx, script.Run, options, args...)
+}
+
+// scriptType reads 'type', the type of an inline javascript script:
+// module, commonjs or typescript.
+func scriptType(with schema.With) (js.Type, error) {
+	name, ok := with.TryGetString("type")
+	if !ok || name == "" {
+		return "", nil
+	}
+
+	return js.ParseType(name)
+}
+
+// denoScript runs deno scripts with the permissions of the task.
+func denoScript(ctx context.Context, script *Script, args ...string) *exec.Cmd {
+	options := &deno.Options{Allow: denoAllow(script.Permissions, script.RunfileDir)}
+	return deno.ScriptOptionsContext(ctx, script.Run, options, args...)
+}
+
+// denoAllow translates the permissions into the --allow flags of
+// deno. Relative paths to read, write or run are resolved against the
+// runfile directory, while programs to run given by name are looked
+// up on the PATH by deno.
+func denoAllow(perms *schema.Permissions, dir string) []string {
+	if perms == nil || perms.All {
+		return nil
+	}
+
+	paths := func(values []string) []string {
+		resolved := make([]string, 0, len(values))
+		for _, value := range values {
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(dir, value)
+			}
+			resolved = append(resolved, value)
+		}
+		return resolved
+	}
+
+	programs := func(values []string) []string {
+		resolved := make([]string, 0, len(values))
+		for _, value := range values {
+			if strings.ContainsAny(value, `/\`) && !filepath.IsAbs(value) {
+				value = filepath.Join(dir, value)
+			}
+			resolved = append(resolved, value)
+		}
+		return resolved
+	}
+
+	kinds := []struct {
+		name       string
+		permission *schema.Permission
+		resolve    func([]string) []string
+	}{
+		{"read", perms.Read, paths},
+		{"write", perms.Write, paths},
+		{"net", perms.Net, nil},
+		{"env", perms.Env, nil},
+		{"run", perms.Run, programs},
+	}
+
+	allow := []string{}
+	for _, kind := range kinds {
+		p := kind.permission
+		switch {
+		case p == nil:
+		case p.All:
+			allow = append(allow, "--allow-"+kind.name)
+		case len(p.Values) > 0:
+			values := p.Values
+			if kind.resolve != nil {
+				values = kind.resolve(values)
+			}
+			allow = append(allow, "--allow-"+kind.name+"="+strings.Join(values, ","))
+		}
+	}
+
+	return allow
+}
+
+// DefaultShell returns the runtime used when a task does not
+// specify 'uses' and the runfile does not configure a shell.
+func DefaultShell() string {
+	if runtime.GOOS == "windows" {
+		return "pwsh"
+	}
+
+	return "bash"
+}
@@ -0,0 +1,292 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hyprxlabs/run/internal/fingerprint"
+	"github.com/hyprxlabs/run/internal/globs"
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/hyprxlabs/run/internal/watch"
+)
+
+// Watch runs the named task and its needs, then watches their
+// sources and reruns the affected tasks whenever files change. A
+// task without sources watches its working directory. Changes
+// cancel the run in flight. Watch returns when ctx is done.
+func (r *Runner) Watch(ctx context.Context, options *watch.Options, name string, args ...string) error {
+	plan, err := r.Plan(name)
+	if err != nil {
+		return err
+	}
+
+	options, err = r.watchOptions(options)
+	if err != nil {
+		return err
+	}
+
+	roots := []string{}
+	for _, task := range plan {
+		for _, root := range r.watchRoots(task) {
+			roots = appendRoot(roots, root)
+		}
+	}
+
+	w, err := watch.New(roots, options)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	var (
+		wg     sync.WaitGroup
+		cancel context.CancelFunc = func() {}
+	)
+
+	start := func(tasks []*schema.Task, force bool) {
+		// affected tasks rerun even when their own fingerprint is
+		// unchanged, since the tasks they need just ran again.
+		tr := *r
+		tr.Force = r.Force || force
+
+		var runCtx context.Context
+		runCtx, cancel = context.WithCancel(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, task := range tasks {
+				var taskArgs []string
+				if task.Id == plan[len(plan)-1].Id {
+					taskArgs = args
+				}
+
+				if err := tr.RunTask(runCtx, task, taskArgs...); err != nil {
+					if runCtx.Err() == nil {
+						fmt.Fprintf(r.Stderr, "%v\n", err)
+					}
+					return
+				}
+
+				if runCtx.Err() != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+	defer stop()
+
+	start(plan, false)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-w.Errors:
+			return err
+		case changed := <-w.Changes:
+			changed = r.withoutGenerated(plan, changed)
+			affected := r.affected(plan, changed)
+			if len(affected) == 0 {
+				continue
+			}
+
+			stop()
+			fmt.Fprintf(r.Stderr, "%d file(s) changed, restarting %s\n", len(changed), taskNames(affected))
+			start(affected, true)
+		}
+	}
+}
+
+// watchOptions returns a copy of the options whose ignore rules,
+// the runfile's .gitignore by default, also skip the local state
+// directory. The caller's options are left unchanged.
+func (r *Runner) watchOptions(options *watch.Options) (*watch.Options, error) {
+	copied := watch.Options{}
+	if options != nil {
+		copied = *options
+	}
+
+	if copied.Ignore == nil {
+		ignore, err := watch.ReadGitignore(r.Runfile.Dir())
+		if err != nil {
+			return nil, err
+		}
+		copied.Ignore = ignore
+	} else {
+		copied.Ignore = copied.Ignore.Clone()
+	}
+
+	// the local state directory, which holds the fingerprints,
+	// would otherwise retrigger the tasks that wrote them.
+	copied.Ignore.Add("/" + strings.Split(fingerprint.StateDir, "/")[0] + "/")
+
+	return &copied, nil
+}
+
+// watchRoots returns the directories to watch for the task: the
+// static prefix of each source glob or the task's working directory.
+func (r *Runner) watchRoots(task *schema.Task) []string {
+	cwd := r.Cwd(task)
+	if len(task.Sources) == 0 {
+		return []string{cwd}
+	}
+
+	roots := []string{}
+	for _, pattern := range task.Sources {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(cwd, pattern)
+		}
+
+		dir := filepath.Clean(pattern)
+		for globs.HasMeta(dir) {
+			dir = filepath.Dir(dir)
+		}
+
+		if dir == filepath.Clean(pattern) {
+			// a plain file is watched through its directory.
+			dir = filepath.Dir(dir)
+		}
+
+		roots = appendRoot(roots, dir)
+	}
+
+	return roots
+}
+
+// watches reports whether a change to path affects the task.
+func (r *Runner) watches(task *schema.Task, path string) bool {
+	cwd := r.Cwd(task)
+	if len(task.Sources) == 0 {
+		return isBelow(cwd, path)
+	}
+
+	matched := false
+	for _, pattern := range task.Sources {
+		exclude := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(cwd, pattern)
+		}
+
+		if !globs.HasMeta(pattern) && isBelow(pattern, path) {
+			matched = !exclude
+			continue
+		}
+
+		if globs.Match(pattern, path) {
+			matched = !exclude
+		}
+	}
+
+	return matched
+}
+
+// affected returns the tasks of the plan that watch one of the
+// changed paths, along with every task that needs them, in plan
+// order.
+func (r *Runner) affected(plan []*schema.Task, changed []string) []*schema.Task {
+	hit := map[string]bool{}
+	results := []*schema.Task{}
+	for _, task := range plan {
+		for _, need := range task.Needs {
+			if t, err := r.Task(need); err == nil && hit[t.Id] {
+				hit[task.Id] = true
+				break
+			}
+		}
+
+		if !hit[task.Id] {
+			for _, path := range changed {
+				if r.watches(task, path) {
+					hit[task.Id] = true
+					break
+				}
+			}
+		}
+
+		if hit[task.Id] {
+			results = append(results, task)
+		}
+	}
+
+	return results
+}
+
+// withoutGenerated drops the paths generated by tasks in the plan.
+func (r *Runner) withoutGenerated(plan []*schema.Task, changed []string) []string {
+	results := []string{}
+	for _, path := range changed {
+		generated := false
+		for _, task := range plan {
+			cwd := r.Cwd(task)
+			for _, pattern := range task.Generates {
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(cwd, pattern)
+				}
+
+				if globs.Match(pattern, path) || (!globs.HasMeta(pattern) && isBelow(pattern, path)) {
+					generated = true
+					break
+				}
+			}
+
+			if generated {
+				break
+			}
+		}
+
+		if !generated {
+			results = append(results, path)
+		}
+	}
+
+	return results
+}
+
+func isBelow(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// appendRoot adds root unless it or one of its parents is present,
+// and drops roots that are below it.
+func appendRoot(roots []string, root string) []string {
+	for _, existing := range roots {
+		if isBelow(existing, root) {
+			return roots
+		}
+	}
+
+	results := []string{}
+	for _, existing := range roots {
+		if !isBelow(root, existing) {
+			results = append(results, existing)
+		}
+	}
+
+	return append(results, root)
+}
+
+func taskNames(tasks []*schema.Task) string {
+	names := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		names = append(names, task.Id)
+	}
+
+	return strings.Join(names, ", ")
+}
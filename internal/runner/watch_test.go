@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/hyprxlabs/run/internal/watch"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWatchRunner(t *testing.T) (*Runner, string) {
+	dir := t.TempDir()
+	rf := &schema.Runfile{Path: filepath.Join(dir, "runfile.yaml")}
+	rf.Tasks.Set(&schema.Task{Id: "gen", Sources: []string{"src/**/*.txt", "!src/skip/**"}, Generates: []string{"out"}})
+	rf.Tasks.Set(&schema.Task{Id: "build", Needs: []string{"gen"}, Sources: []string{"main.go"}})
+	rf.Tasks.Set(&schema.Task{Id: "all", Needs: []string{"build"}})
+
+	return New(rf), dir
+}
+
+func TestWatch_Affected(t *testing.T) {
+	r, dir := newWatchRunner(t)
+	plan, err := r.Plan("all")
+	assert.NoError(t, err)
+
+	affected := r.affected(plan, []string{filepath.Join(dir, "src", "a", "b.txt")})
+	assert.Equal(t, "gen, build, all", taskNames(affected))
+
+	affected = r.affected(plan, []string{filepath.Join(dir, "main.go")})
+	assert.Equal(t, "build, all", taskNames(affected))
+
+	affected = r.affected(plan, []string{filepath.Join(dir, "src", "skip", "b.txt")})
+	assert.Equal(t, "all", taskNames(affected))
+
+	changed := r.withoutGenerated(plan, []string{filepath.Join(dir, "out", "x.bin"), filepath.Join(dir, "main.go")})
+	assert.Equal(t, []string{filepath.Join(dir, "main.go")}, changed)
+}
+
+func TestWatch_Roots(t *testing.T) {
+	r, dir := newWatchRunner(t)
+	plan, err := r.Plan("all")
+	assert.NoError(t, err)
+
+	roots := []string{}
+	for _, task := range plan {
+		for _, root := range r.watchRoots(task) {
+			roots = appendRoot(roots, root)
+		}
+	}
+
+	assert.Equal(t, []string{dir}, roots)
+	assert.Equal(t, []string{filepath.Join(dir, "src")}, r.watchRoots(plan[0]))
+}
+
+func TestWatch_Options(t *testing.T) {
+	r, dir := newWatchRunner(t)
+	state := filepath.Join(dir, ".run", "state")
+
+	ignore := watch.NewIgnore(dir, "*.log")
+	options := &watch.Options{Ignore: ignore}
+	for range 2 {
+		copied, err := r.watchOptions(options)
+		assert.NoError(t, err)
+		assert.NotSame(t, ignore, copied.Ignore)
+		assert.True(t, copied.Ignore.Match(state, true))
+		assert.True(t, copied.Ignore.Match(filepath.Join(dir, "a.log"), false))
+	}
+
+	assert.Same(t, ignore, options.Ignore)
+	assert.False(t, ignore.Match(state, true))
+
+	copied, err := r.watchOptions(nil)
+	assert.NoError(t, err)
+	assert.True(t, copied.Ignore.Match(state, true))
+}
@@ -25,7 +25,7 @@ type environmentVariable struct {
 	IsSecret bool
 }
 
-func (ev *environmentVariable) UnmarshalYAML(node yaml.Node) error {
+func (ev *environmentVariable) UnmarshalYAML(node *yaml.Node) error {
 	if ev == nil {
 		ev = &environmentVariable{}
 	}
@@ -44,7 +44,7 @@ func (ev *environmentVariable) UnmarshalYAML(node yaml.Node) error {
 			ev.IsSecret = true
 			return nil
 		} else {
-			return yamlErrorf(node, "invalid environment variable format, expected 'KEY=VALUE' or 'KEY:VALUE'")
+			return yamlErrorf(*node, "invalid environment variable format, expected 'KEY=VALUE' or 'KEY:VALUE'")
 		}
 	}
 
@@ -83,10 +83,10 @@ func (ev *environmentVariable) UnmarshalYAML(node yaml.Node) error {
 		return nil
 	}
 
-	return yamlErrorf(node, "expected yaml scalar or mapping for environment variable")
+	return yamlErrorf(*node, "expected yaml scalar or mapping for environment variable")
 }
 
-func (e *Environment) UnmarshalYAML(node yaml.Node) error {
+func (e *Environment) UnmarshalYAML(node *yaml.Node) error {
 	if e == nil {
 		e = &Environment{}
 	}
@@ -206,7 +206,7 @@ func (e *Environment) UnmarshalYAML(node yaml.Node) error {
 		return nil
 	}
 
-	return yamlErrorf(node, "expected yaml sequence for environment")
+	return yamlErrorf(*node, "expected yaml sequence for environment")
 }
 
 func NewEnv() *Environment {
@@ -233,7 +233,6 @@ func NewEnvFromMap(omap om.OrderedMap[string, string]) *Environment {
 }
 
 func (e *Environment) IsSecret(key string) bool {
-	e.init()
 
 	for _, k := range e.secrets {
 		if k == key {
@@ -244,9 +243,8 @@ func (e *Environment) IsSecret(key string) bool {
 }
 
 func (e *Environment) Secrets() []string {
-	e.init()
 	if e.secrets == nil {
-		e.secrets = []string{}
+		return []string{}
 	}
 
 	return e.secrets
@@ -263,13 +261,11 @@ func (e *Environment) Set(key, value string) {
 }
 
 func (e *Environment) Get(key string) (string, bool) {
-	e.init()
 	val, ok := e.values[key]
 	return val, ok
 }
 
 func (e *Environment) Has(key string) bool {
-	e.init()
 	_, ok := e.values[key]
 	return ok
 }
@@ -321,7 +317,6 @@ func (e *Environment) AppendPath(path string) error {
 }
 
 func (e *Environment) HasPath(path string) bool {
-	e.init()
 	paths := e.SplitPath()
 	if runtime.GOOS == "windows" {
 		for _, p := range paths {
@@ -341,7 +336,6 @@ func (e *Environment) HasPath(path string) bool {
 }
 
 func (e *Environment) SplitPath() []string {
-	e.init()
 	if e.GetPath() == "" {
 		return []string{}
 	}
@@ -349,7 +343,6 @@ func (e *Environment) SplitPath() []string {
 }
 
 func (e *Environment) GetPath() string {
-	e.init()
 	if runtime.GOOS == "windows" {
 		if val, ok := e.values["Path"]; ok {
 			return val
@@ -377,7 +370,6 @@ func (e *Environment) SetPath(value string) error {
 }
 
 func (e *Environment) GetString(key string) string {
-	e.init()
 	if val, ok := e.values[key]; ok {
 		return val
 	}
@@ -396,7 +388,6 @@ func (e *Environment) Delete(key string) {
 }
 
 func (e *Environment) Clone() *Environment {
-	e.init()
 	clone := NewEnv()
 
 	for k, v := range e.values {
@@ -408,7 +399,6 @@ func (e *Environment) Clone() *Environment {
 }
 
 func (e *Environment) ToOrderedMap() om.OrderedMap[string, string] {
-	e.init()
 	om.New[string, string]()
 	omap := om.New[string, string]()
 	for _, k := range e.keys {
@@ -418,14 +408,12 @@ func (e *Environment) ToOrderedMap() om.OrderedMap[string, string] {
 }
 
 func (e *Environment) ToMap() map[string]string {
-	e.init()
 	m := make(map[string]string, len(e.values))
 	maps.Copy(m, e.values)
 	return m
 }
 
 func (e *Environment) Keys() []string {
-	e.init()
 	keys := make([]string, 0, len(e.values))
 	for k := range e.values {
 		keys = append(keys, k)
@@ -434,7 +422,6 @@ func (e *Environment) Keys() []string {
 }
 
 func (e *Environment) Values() []string {
-	e.init()
 	values := make([]string, 0, len(e.values))
 	for _, k := range e.keys {
 		values = append(values, e.values[k])
@@ -443,13 +430,11 @@ func (e *Environment) Values() []string {
 }
 
 func (e *Environment) Len() int {
-	e.init()
 	return len(e.values)
 }
 
 // return iter.Seq
 func (e *Environment) Iter() iter.Seq2[string, string] {
-	e.init()
 	return func(yield func(string, string) bool) {
 		for _, k := range e.keys {
 			if !yield(k, e.values[k]) {
@@ -459,6 +444,8 @@ func (e *Environment) Iter() iter.Seq2[string, string] {
 	}
 }
 
+// init allocates the maps of a zero environment. Only methods that
+// write call it, so environments can be read in parallel.
 func (e *Environment) init() {
 	if e == nil {
 		e = NewEnv()
@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Executable adds an executable to the ones run knows how to find, or
+// puts search paths in front of the ones of a built-in executable.
+type Executable struct {
+	Id string
+
+	// Exe is the name of the executable, e.g. java. Defaults to the
+	// id.
+	Exe string
+
+	// Path is the path of the executable, searched before the search
+	// paths.
+	Path string
+
+	// Windows, Linux and Darwin are the paths searched for the
+	// executable on each platform. They may be globs, whose matches
+	// are searched from the highest version to the lowest.
+	Windows []string
+	Linux   []string
+	Darwin  []string
+
+	// Arch holds the paths searched on an architecture, keyed by
+	// architecture, e.g. arm64, or by platform and architecture, e.g.
+	// linux/arm64.
+	Arch map[string][]string
+}
+
+type Executables struct {
+	entries map[string]Executable
+	keys    []string
+}
+
+func (e *Executable) UnmarshalYAML(value *yaml.Node) error {
+	if e == nil {
+		e = &Executable{}
+	}
+
+	if value.Kind == yaml.ScalarNode {
+		e.Path = value.Value
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml scalar or mapping for executable")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		key := keyNode.Value
+		var list *[]string
+		switch key {
+		case "id":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'id' field")
+			}
+			e.Id = valueNode.Value
+			continue
+		case "exe", "executable":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'exe' field")
+			}
+			e.Exe = valueNode.Value
+			continue
+		case "path":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'path' field")
+			}
+			e.Path = valueNode.Value
+			continue
+		case "arch":
+			if valueNode.Kind != yaml.MappingNode {
+				return yamlErrorf(*valueNode, "expected yaml mapping for 'arch' field")
+			}
+			e.Arch = map[string][]string{}
+			for j := 0; j < len(valueNode.Content); j += 2 {
+				archKey, ok := archKey(valueNode.Content[j].Value)
+				if !ok {
+					return yamlErrorf(*valueNode.Content[j], "unsupported platform in arch '%s'", valueNode.Content[j].Value)
+				}
+
+				paths, err := stringList(valueNode.Content[j+1], "arch")
+				if err != nil {
+					return err
+				}
+				e.Arch[archKey] = paths
+			}
+			continue
+		case "windows", "win", "win32":
+			list = &e.Windows
+		case "linux":
+			list = &e.Linux
+		case "darwin", "mac", "macos", "osx":
+			list = &e.Darwin
+		default:
+			return yamlErrorf(*keyNode, "unexpected field '%s' in executable", key)
+		}
+
+		paths, err := stringList(valueNode, key)
+		if err != nil {
+			return err
+		}
+		*list = paths
+	}
+
+	return nil
+}
+
+// archKey normalizes the platform of keys such as macos/arm64.
+func archKey(key string) (string, bool) {
+	platform, arch, found := strings.Cut(strings.ToLower(key), "/")
+	if !found {
+		return platform, true
+	}
+
+	platform, ok := NormalizePlatform(platform)
+	return platform + "/" + arch, ok
+}
+
+// stringList decodes a sequence of scalars, or a single scalar.
+func stringList(node *yaml.Node, key string) ([]string, error) {
+	if node.Kind == yaml.ScalarNode {
+		return []string{node.Value}, nil
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return nil, yamlErrorf(*node, "expected yaml sequence for '%s' field", key)
+	}
+
+	list := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return nil, yamlErrorf(*item, "expected yaml scalar in '%s' list", key)
+		}
+		list = append(list, item.Value)
+	}
+
+	return list, nil
+}
+
+func (e *Executables) UnmarshalYAML(value *yaml.Node) error {
+	e.init()
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml mapping for executables")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		var exe Executable
+		if err := valueNode.Decode(&exe); err != nil {
+			return err
+		}
+
+		if exe.Id == "" {
+			exe.Id = keyNode.Value
+		}
+
+		if exe.Exe == "" {
+			exe.Exe = exe.Id
+		}
+
+		e.Set(&exe)
+	}
+
+	return nil
+}
+
+// Get returns the executable with the given id, ignoring case.
+func (e *Executables) Get(id string) (Executable, bool) {
+	if e == nil || e.entries == nil {
+		return Executable{}, false
+	}
+
+	if entry, ok := e.entries[id]; ok {
+		return entry, true
+	}
+
+	for _, k := range e.keys {
+		if strings.EqualFold(k, id) {
+			return e.entries[k], true
+		}
+	}
+
+	return Executable{}, false
+}
+
+// Set adds the executable or replaces the one with the same id.
+func (e *Executables) Set(entry *Executable) {
+	e.init()
+
+	if entry == nil || entry.Id == "" {
+		return
+	}
+
+	for _, k := range e.keys {
+		if strings.EqualFold(k, entry.Id) {
+			e.entries[k] = *entry
+			return
+		}
+	}
+
+	e.entries[entry.Id] = *entry
+	e.keys = append(e.keys, entry.Id)
+}
+
+func (e *Executables) Keys() []string {
+	if e == nil || e.entries == nil {
+		return []string{}
+	}
+
+	return e.keys
+}
+
+func (e *Executables) Len() int {
+	if e == nil || e.entries == nil {
+		return 0
+	}
+
+	return len(e.entries)
+}
+
+func (e *Executables) init() {
+	if e.entries == nil {
+		e.entries = map[string]Executable{}
+	}
+
+	if e.keys == nil {
+		e.keys = []string{}
+	}
+}
@@ -1,5 +1,11 @@
 package schema
 
+import (
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
 type Input struct {
 	Id        string
 	Name      *string
@@ -9,3 +15,111 @@ type Input struct {
 	Required  *bool
 	Selection []string
 }
+
+func (i *Input) UnmarshalYAML(value *yaml.Node) error {
+	if i == nil {
+		i = &Input{}
+	}
+
+	if value.Kind == yaml.ScalarNode {
+		i.Id = value.Value
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml scalar or mapping for input")
+	}
+
+	for j := 0; j < len(value.Content); j += 2 {
+		keyNode := value.Content[j]
+		valueNode := value.Content[j+1]
+
+		key := keyNode.Value
+		switch key {
+		case "id":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'id' field")
+			}
+			i.Id = valueNode.Value
+		case "name":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'name' field")
+			}
+			i.Name = &valueNode.Value
+		case "desc", "description":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'desc' field")
+			}
+			i.Desc = &valueNode.Value
+		case "type":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'type' field")
+			}
+			i.Type = &valueNode.Value
+		case "default":
+			var v interface{}
+			if err := valueNode.Decode(&v); err != nil {
+				return yamlErrorf(*valueNode, "failed to decode 'default' field: %v", err)
+			}
+			i.Default = v
+		case "required":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'required' field")
+			}
+			b, err := strconv.ParseBool(valueNode.Value)
+			if err != nil {
+				return yamlErrorf(*valueNode, "expected 'true' or 'false' for 'required' field")
+			}
+			i.Required = &b
+		case "selection", "options", "choices":
+			if valueNode.Kind != yaml.SequenceNode {
+				return yamlErrorf(*valueNode, "expected yaml sequence for 'selection' field")
+			}
+			i.Selection = make([]string, 0)
+			for _, item := range valueNode.Content {
+				if item.Kind != yaml.ScalarNode {
+					return yamlErrorf(*item, "expected yaml scalar in 'selection' list")
+				}
+				i.Selection = append(i.Selection, item.Value)
+			}
+		default:
+			return yamlErrorf(*keyNode, "unexpected field '%s' in input", key)
+		}
+	}
+
+	if i.Id == "" && i.Name != nil {
+		i.Id = *i.Name
+	}
+
+	if i.Id == "" {
+		return yamlErrorf(*value, "input requires an 'id' or 'name' field")
+	}
+
+	return nil
+}
+
+// IsRequired reports whether a value must be given for the input.
+func (i *Input) IsRequired() bool {
+	return i.Required != nil && *i.Required
+}
+
+// DefaultString returns the default value as a string and whether
+// a default was declared.
+func (i *Input) DefaultString() (string, bool) {
+	if i.Default == nil {
+		return "", false
+	}
+
+	switch v := i.Default.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
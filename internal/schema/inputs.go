@@ -226,11 +226,8 @@ func (e *Inputs) TryGetMap(key ...string) (map[string]interface{}, bool) {
 }
 
 func (e *Inputs) init() {
-	if e == nil {
-		e = &Inputs{
-			entries: map[string]interface{}{},
-			keys:    []string{},
-		}
+	if e.entries == nil {
+		e.entries = map[string]interface{}{}
+		e.keys = []string{}
 	}
-
 }
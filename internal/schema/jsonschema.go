@@ -0,0 +1,268 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// JSONSchemaID is the $id of the runfile JSON schema.
+const JSONSchemaID = "https://github.com/hyprxlabs/run/runfile.schema.json"
+
+type jsonSchema map[string]interface{}
+
+func ref(name string) jsonSchema {
+	return jsonSchema{"$ref": "#/$defs/" + name}
+}
+
+func str(desc string) jsonSchema {
+	return jsonSchema{"type": "string", "description": desc}
+}
+
+func strList(desc string) jsonSchema {
+	return jsonSchema{
+		"type":        "array",
+		"description": desc,
+		"items":       jsonSchema{"type": "string"},
+	}
+}
+
+func boolean(desc string) jsonSchema {
+	return jsonSchema{
+		"description": desc,
+		"oneOf": []jsonSchema{
+			{"type": "boolean"},
+			{"type": "string", "enum": []string{"true", "false"}},
+		},
+	}
+}
+
+func oneOf(desc string, schemas ...jsonSchema) jsonSchema {
+	schema := jsonSchema{"oneOf": schemas}
+	if desc != "" {
+		schema["description"] = desc
+	}
+	return schema
+}
+
+func object(props jsonSchema) jsonSchema {
+	return jsonSchema{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// alias adds the same schema to props under each name. The decoders
+// accept every name, so the schema must as well.
+func alias(props jsonSchema, schema jsonSchema, names ...string) {
+	for _, name := range names {
+		props[name] = schema
+	}
+}
+
+// perPlatform accepts the schema or a mapping of platforms, and
+// 'default', to the schema, see Task.ForPlatform.
+func perPlatform(desc string, schema jsonSchema) jsonSchema {
+	props := jsonSchema{}
+	alias(props, schema, append(append([]string{}, platforms...), DefaultPlatform)...)
+	return oneOf(desc, schema, object(props))
+}
+
+var platforms = []string{"windows", "win", "win32", "linux", "darwin", "macos", "mac", "osx"}
+
+// JSONSchema returns a JSON schema describing the runfile format,
+// including every alias the YAML decoders accept.
+func JSONSchema() ([]byte, error) {
+	defs := jsonSchema{}
+
+	envVar := object(jsonSchema{
+		"name":   str("The variable name. Defaults to the mapping key."),
+		"value":  str("The variable value."),
+		"secret": boolean("Marks the value as secret."),
+	})
+	defs["environment"] = oneOf("Environment variables as a mapping of names to values or a list of NAME=VALUE (NAME:VALUE marks a secret).",
+		jsonSchema{
+			"type": "object",
+			"additionalProperties": oneOf("",
+				jsonSchema{"type": []string{"string", "number", "boolean"}},
+				envVar),
+		},
+		jsonSchema{
+			"type": "array",
+			"items": oneOf("",
+				jsonSchema{"type": "string", "pattern": "^[^=:]+[=:]"},
+				envVar),
+		})
+
+	pathProps := jsonSchema{
+		"path":   str("The directory to add to PATH."),
+		"os":     str("Only add the path on this platform."),
+		"append": boolean("Append instead of prepend the path."),
+	}
+	alias(pathProps, str("The path on windows."), "windows", "win", "win32")
+	alias(pathProps, str("The path on linux."), "linux")
+	alias(pathProps, str("The path on macOS."), "darwin", "macos", "mac", "osx")
+	defs["paths"] = jsonSchema{
+		"type":        "array",
+		"description": "Directories added to PATH.",
+		"items":       oneOf("", jsonSchema{"type": "string"}, object(pathProps)),
+	}
+
+	defs["dirs"] = object(jsonSchema{
+		"etc":      str("The configuration directory."),
+		"projects": strList("Project directories."),
+		"scripts":  str("The scripts directory."),
+		"bin":      str("The binaries directory."),
+	})
+
+	osProps := jsonSchema{
+		"platform": jsonSchema{"type": "string", "enum": platforms},
+		"arch":     str("The CPU architecture."),
+		"variant":  str("The distribution, e.g. ubuntu."),
+		"family":   str("The distribution family, e.g. debian."),
+		"codename": str("The release codename."),
+		"version":  str("The release version."),
+	}
+	alias(osProps, str("The build version."), "build_version", "buildVersion", "build-version")
+	defs["os"] = oneOf("The operating system of a host.",
+		jsonSchema{"type": "string", "enum": platforms},
+		object(osProps))
+
+	hostProps := jsonSchema{
+		"host":     str("The host name or address. Must match the mapping key."),
+		"port":     jsonSchema{"type": []string{"integer", "string"}, "description": "The SSH port."},
+		"user":     str("The SSH user."),
+		"groups":   strList("The groups the host belongs to."),
+		"meta":     jsonSchema{"type": "object", "description": "Arbitrary metadata."},
+		"os":       ref("os"),
+		"defaults": str("The name of the host to take defaults from."),
+	}
+	alias(hostProps, str("The SSH identity file."), "identity", "identity-file", "identityfile", "identityFile")
+	alias(hostProps, str("The SSH password or the variable holding it."), "password", "pass", "password-variable")
+	defs["host"] = oneOf("A host tasks can run on.", jsonSchema{"type": "string"}, object(hostProps))
+	defs["hosts"] = oneOf("The hosts tasks can run on.",
+		jsonSchema{"type": "object", "additionalProperties": ref("host")},
+		jsonSchema{"type": "array", "items": ref("host")})
+
+	defs["input"] = oneOf("An input the task accepts, available as INPUT_<ID>.",
+		jsonSchema{"type": "string"},
+		object(jsonSchema{
+			"id":          str("The input id."),
+			"name":        str("The input name. Used as id when no id is given."),
+			"desc":        str("The input description."),
+			"description": str("The input description."),
+			"type":        str("The input type."),
+			"default":     jsonSchema{"description": "The value used when none is given."},
+			"required":    boolean("Whether a value must be given."),
+			"selection":   strList("The allowed values."),
+			"options":     strList("The allowed values."),
+			"choices":     strList("The allowed values."),
+		}))
+
+	defs["permission"] = oneOf("Grants access to everything of its kind with true, or to the listed values.",
+		jsonSchema{"type": "boolean"},
+		jsonSchema{"type": "string"},
+		jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}})
+	defs["permissions"] = oneOf("What the script of a task that uses deno may access. Allows everything when not set.",
+		jsonSchema{"type": "string", "enum": []string{"all", "none"}},
+		jsonSchema{"type": "boolean"},
+		object(jsonSchema{
+			"read":  ref("permission"),
+			"write": ref("permission"),
+			"net":   ref("permission"),
+			"env":   ref("permission"),
+			"run":   ref("permission"),
+		}))
+
+	taskProps := jsonSchema{
+		"id":      str("The task id. Defaults to the mapping key."),
+		"name":    str("The display name of the task."),
+		"desc":    str("A short description shown by --list."),
+		"help":    str("The help shown by 'run help <task>'."),
+		"env":     perPlatform("The task environment, or one per platform.", ref("environment")),
+		"cwd":     perPlatform("The working directory, relative to the runfile, or one per platform.", jsonSchema{"type": "string"}),
+		"timeout": jsonSchema{"type": "string", "description": "The maximum duration, e.g. 30s or 5m.", "pattern": "^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"},
+		"tty":     boolean("Runs the task under a pseudo-terminal, for programs that need one."),
+		"run":     perPlatform("The script or command to run, or one per platform. Tasks without one for the platform or a default one do not run.", jsonSchema{"type": "string"}),
+		"uses":    perPlatform("The runtime or shell that runs the script, e.g. bash, python or run-shell, the built-in portable shell, or one per platform.", jsonSchema{"type": "string"}),
+		"args":    strList("Arguments passed to the script."),
+		"with":    jsonSchema{"type": "object", "description": "Values for the runtime in 'uses', e.g. 'requires', the modules an inline go script needs, or 'type', the type of an inline node or bun script: module, commonjs or typescript."},
+		"hosts":   strList("The hosts or groups the task runs on."),
+		"sources": strList("Files whose changes make the task run again."),
+	}
+	alias(taskProps, strList("Dotenv files loaded for the task, relative to the runfile."), "dotenv", "envfile", "env-file")
+	alias(taskProps, strList("The tasks that must run first."), "needs", "deps", "dependencies")
+	alias(taskProps, oneOf("The inputs the task accepts, or values for the runtime in 'uses'.",
+		jsonSchema{"type": "array", "items": ref("input")},
+		jsonSchema{"type": "object"}), "input", "inputs")
+	alias(taskProps, strList("Files the task creates."), "generates", "outputs")
+	alias(taskProps, ref("permissions"), "permissions", "perms")
+	alias(taskProps, str("A condition that must hold for the task to run."), "if", "condition")
+	defs["task"] = oneOf("A task, or the script it runs.", jsonSchema{"type": "string"}, object(taskProps))
+
+	runtimeProps := jsonSchema{
+		"id":         str("The runtime id. Defaults to the mapping key."),
+		"exe":        str("The name of the executable, searched for on the PATH after the search paths. Defaults to the id."),
+		"executable": str("The name of the executable. Same as exe."),
+		"variable":   str("The variable that overrides the path of the executable. Defaults to RUN_<ID>_EXE."),
+		"linux":      strList("The paths searched for the executable on linux."),
+		"extensions": strList("The extensions of script files, e.g. .pl."),
+		"ext":        str("The extension of the file inline scripts are written to when the executable cannot run them inline."),
+	}
+	alias(runtimeProps, strList("The paths searched for the executable on windows."), "windows", "win", "win32")
+	alias(runtimeProps, strList("The paths searched for the executable on macos."), "darwin", "mac", "macos", "osx")
+	alias(runtimeProps, strList("The arguments passed before an inline script, e.g. -e."), "inline-args", "inlineArgs", "inline_args")
+	alias(runtimeProps, strList("The arguments passed before the path of a script file."), "file-args", "fileArgs", "file_args")
+	defs["runtime"] = oneOf("A runtime tasks can use, or the name of its executable.", jsonSchema{"type": "string"}, object(runtimeProps))
+
+	paths := func(desc string) jsonSchema {
+		return oneOf(desc, jsonSchema{"type": "string"}, jsonSchema{"type": "array", "items": jsonSchema{"type": "string"}})
+	}
+	executableProps := jsonSchema{
+		"id":         str("The executable id. Defaults to the mapping key."),
+		"exe":        str("The name of the executable. Defaults to the id."),
+		"executable": str("The name of the executable. Same as exe."),
+		"path":       str("The path of the executable, searched before the search paths."),
+		"linux":      paths("The paths searched for the executable on linux. Globs match from the highest version to the lowest."),
+		"arch": jsonSchema{
+			"type":                 "object",
+			"description":          "The paths searched first on an architecture, keyed by architecture, e.g. arm64, or platform and architecture, e.g. linux/arm64.",
+			"additionalProperties": paths(""),
+		},
+	}
+	alias(executableProps, paths("The paths searched for the executable on windows."), "windows", "win", "win32")
+	alias(executableProps, paths("The paths searched for the executable on macos, before the ones of linux."), "darwin", "mac", "macos", "osx")
+	defs["executable"] = oneOf("An executable, or its path. Its paths are searched before the ones of the built-in executable of the same name, after RUN_<ID>_EXE.",
+		jsonSchema{"type": "string"}, object(executableProps))
+
+	defs["config"] = object(jsonSchema{
+		"paths":        ref("paths"),
+		"dirs":         ref("dirs"),
+		"env":          ref("environment"),
+		"substitution": boolean("Enables variable substitution."),
+		"context":      str("The default context."),
+		"shell":        str("The shell used by tasks without 'uses'."),
+	})
+
+	root := object(jsonSchema{
+		"name":   str("The project name. Tasks can be run as <name>:<task>."),
+		"config": ref("config"),
+		"tasks":  jsonSchema{"type": "object", "additionalProperties": ref("task")},
+		"hosts":  ref("hosts"),
+		"runtimes": jsonSchema{
+			"type":                 "object",
+			"description":          "Runtimes tasks can use through 'uses', in addition to the built-in ones.",
+			"additionalProperties": ref("runtime"),
+		},
+		"executables": jsonSchema{
+			"type":                 "object",
+			"description":          "Executables run looks for, in addition to or in front of the built-in ones. See 'run which <name>'.",
+			"additionalProperties": ref("executable"),
+		},
+	})
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = JSONSchemaID
+	root["title"] = "runfile"
+	root["$defs"] = defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
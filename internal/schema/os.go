@@ -1,32 +1,28 @@
 package schema
 
 import (
-	"strings"
-
 	"go.yaml.in/yaml/v4"
 )
 
 type OS struct {
-	Platform     string `yaml:"platform"`
-	Arch         string `yaml:"arch"`
-	Variant      string `yaml:"variant,omitempty"`
-	Family       string `yaml:"family,omitempty"`
-	Codename     string `yaml:"codename,omitempty"`
-	Version      string `yaml:"version,omitempty"`
-	BuildVersion string `yaml:"build_version,omitempty"`
+	Platform     string `json:"platform" yaml:"platform"`
+	Arch         string `json:"arch" yaml:"arch"`
+	Variant      string `json:"variant,omitempty" yaml:"variant,omitempty"`
+	Family       string `json:"family,omitempty" yaml:"family,omitempty"`
+	Codename     string `json:"codename,omitempty" yaml:"codename,omitempty"`
+	Version      string `json:"version,omitempty" yaml:"version,omitempty"`
+	BuildVersion string `json:"build_version,omitempty" yaml:"build_version,omitempty"`
 }
 
-func (o *OS) UnmarshalYAML(node yaml.Node) error {
+func (o *OS) UnmarshalYAML(node *yaml.Node) error {
 	if o == nil {
 		o = &OS{}
 	}
 
-	if node.Kind == yaml.ScalarNode {
+	switch node.Kind {
+	case yaml.ScalarNode:
 		o.Platform = node.Value
-		return nil
-	}
-
-	if node.Kind == yaml.MappingNode {
+	case yaml.MappingNode:
 		for i := 0; i < len(node.Content); i += 2 {
 			keyNode := node.Content[i]
 			valueNode := node.Content[i+1]
@@ -77,28 +73,19 @@ func (o *OS) UnmarshalYAML(node yaml.Node) error {
 				return yamlErrorf(*keyNode, "unexpected field '%s' in os", key)
 			}
 		}
+	default:
+		return yamlErrorf(*node, "expected yaml scalar or mapping for 'os' node")
 	}
 
-	switch strings.ToLower(o.Platform) {
-	case "windows":
-		fallthrough
-	case "win":
-		fallthrough
-	case "win32":
-		o.Platform = "windows"
-	case "linux":
-		o.Platform = "linux"
-	case "darwin":
-		fallthrough
-	case "macos":
-		fallthrough
-	case "mac":
-		fallthrough
-	case "osx":
-		o.Platform = "darwin"
-	default:
-		return yamlErrorf(node, "unsupported platform '%s'", o.Platform)
+	if o.Platform == "" {
+		return nil
+	}
+
+	platform, ok := NormalizePlatform(o.Platform)
+	if !ok {
+		return yamlErrorf(*node, "unsupported platform '%s'", o.Platform)
 	}
+	o.Platform = platform
 
-	return yamlError(node, "expected yaml scalar or mapping for 'os' node")
+	return nil
 }
@@ -0,0 +1,22 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestOS_UnmarshalYAML(t *testing.T) {
+	var host schema.HostEntry
+	require.NoError(t, yaml.Unmarshal([]byte("{host: db1, os: macos}"), &host))
+	assert.Equal(t, "darwin", host.OS.Platform)
+
+	require.NoError(t, yaml.Unmarshal([]byte("{host: db1, os: {platform: win, arch: amd64, build-version: '26100'}}"), &host))
+	assert.Equal(t, &schema.OS{Platform: "windows", Arch: "amd64", BuildVersion: "26100"}, host.OS)
+
+	err := yaml.Unmarshal([]byte("{host: db1, os: [linux]}"), &host)
+	assert.ErrorContains(t, err, "expected yaml scalar or mapping for 'os' node")
+}
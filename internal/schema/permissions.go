@@ -0,0 +1,100 @@
+package schema
+
+import "go.yaml.in/yaml/v4"
+
+// Permissions limit what the script of a task that uses deno may
+// access. Tasks without permissions may access everything.
+type Permissions struct {
+	// All grants every permission, like a task without permissions.
+	All   bool
+	Read  *Permission
+	Write *Permission
+	Net   *Permission
+	Env   *Permission
+	Run   *Permission
+}
+
+// Permission grants access to every resource of its kind, or to the
+// listed paths, hosts, variables or programs.
+type Permission struct {
+	All    bool
+	Values []string
+}
+
+func (p *Permissions) UnmarshalYAML(value *yaml.Node) error {
+	if p == nil {
+		p = &Permissions{}
+	}
+
+	if value.Kind == yaml.ScalarNode {
+		switch value.Value {
+		case "all", "true":
+			p.All = true
+		case "none", "false":
+			p.All = false
+		default:
+			return yamlErrorf(*value, "expected 'all', 'none' or a mapping for permissions")
+		}
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml scalar or mapping for permissions")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		var target **Permission
+		key := keyNode.Value
+		switch key {
+		case "read":
+			target = &p.Read
+		case "write":
+			target = &p.Write
+		case "net":
+			target = &p.Net
+		case "env":
+			target = &p.Env
+		case "run":
+			target = &p.Run
+		default:
+			return yamlErrorf(*keyNode, "unexpected field '%s' in permissions", key)
+		}
+
+		permission, err := decodePermission(key, valueNode)
+		if err != nil {
+			return err
+		}
+		*target = permission
+	}
+
+	return nil
+}
+
+// decodePermission reads true, false, a single value or a list of
+// values.
+func decodePermission(key string, node *yaml.Node) (*Permission, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.Value {
+		case "true":
+			return &Permission{All: true}, nil
+		case "false":
+			return nil, nil
+		}
+		return &Permission{Values: []string{node.Value}}, nil
+	case yaml.SequenceNode:
+		permission := &Permission{Values: make([]string, 0)}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, yamlErrorf(*item, "expected yaml scalar in '%s' permission", key)
+			}
+			permission.Values = append(permission.Values, item.Value)
+		}
+		return permission, nil
+	default:
+		return nil, yamlErrorf(*node, "expected true, false or a yaml sequence for '%s' permission", key)
+	}
+}
@@ -0,0 +1,202 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// DefaultPlatform is the key of the variant of a task field used on
+// platforms without a variant of their own.
+const DefaultPlatform = "default"
+
+// TaskVariant holds the values of the fields of a task that differ
+// by platform.
+type TaskVariant struct {
+	Run  *string
+	Uses *string
+	Cwd  *string
+	Env  *Environment
+}
+
+// NormalizePlatform returns the name run uses for the platform:
+// windows, linux or darwin. It accepts the aliases of the os field of
+// hosts, e.g. win or macos.
+func NormalizePlatform(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "windows", "win", "win32":
+		return "windows", true
+	case "linux":
+		return "linux", true
+	case "darwin", "macos", "mac", "osx":
+		return "darwin", true
+	default:
+		return "", false
+	}
+}
+
+// platformKey returns the normalized platform or 'default'.
+func platformKey(name string) (string, bool) {
+	if strings.EqualFold(name, DefaultPlatform) {
+		return DefaultPlatform, true
+	}
+
+	return NormalizePlatform(name)
+}
+
+// variant returns the variant of the task for the platform, creating
+// it when the task has none.
+func (t *Task) variant(platform string) *TaskVariant {
+	if t.Variants == nil {
+		t.Variants = map[string]*TaskVariant{}
+	}
+
+	v, ok := t.Variants[platform]
+	if !ok {
+		v = &TaskVariant{}
+		t.Variants[platform] = v
+	}
+
+	return v
+}
+
+// decodeVariants reads the per-platform form of a scalar field, e.g.
+// run: {linux: ..., windows: ..., default: ...}, and calls set with
+// each platform and value.
+func decodeVariants(node *yaml.Node, field string, set func(platform string, value *string)) error {
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		platform, ok := platformKey(keyNode.Value)
+		if !ok {
+			return yamlErrorf(*keyNode, "unsupported platform '%s' in '%s' field", keyNode.Value, field)
+		}
+
+		if valueNode.Kind != yaml.ScalarNode {
+			return yamlErrorf(*valueNode, "expected yaml scalar for '%s' on %s", field, platform)
+		}
+
+		set(platform, &valueNode.Value)
+	}
+
+	return nil
+}
+
+// isPlatformEnv reports whether the env mapping has the per-platform
+// form: every key is a platform and every value is an environment,
+// not a variable such as {value: x, secret: true}.
+func isPlatformEnv(node *yaml.Node) bool {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if _, ok := platformKey(node.Content[i].Value); !ok {
+			return false
+		}
+
+		value := node.Content[i+1]
+		switch value.Kind {
+		case yaml.SequenceNode:
+		case yaml.MappingNode:
+			if _, ok := mappingValue(value, "value"); ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1], true
+		}
+	}
+
+	return nil, false
+}
+
+// ForPlatform returns the task as it runs on the platform: run, uses,
+// cwd and env take the values of the variant for the platform, or of
+// the default variant. A task that does not support the platform, see
+// Supports, has nothing to run.
+func (t *Task) ForPlatform(platform string) *Task {
+	task := *t
+	if len(t.Variants) == 0 {
+		return &task
+	}
+
+	pick := func(get func(v *TaskVariant) bool) *TaskVariant {
+		for _, key := range []string{platform, DefaultPlatform} {
+			if v, ok := t.Variants[key]; ok && get(v) {
+				return v
+			}
+		}
+		return nil
+	}
+
+	if v := pick(func(v *TaskVariant) bool { return v.Run != nil }); v != nil {
+		task.Run = v.Run
+	}
+
+	if v := pick(func(v *TaskVariant) bool { return v.Uses != nil }); v != nil {
+		task.Uses = v.Uses
+	}
+
+	if v := pick(func(v *TaskVariant) bool { return v.Cwd != nil }); v != nil {
+		task.Cwd = v.Cwd
+	}
+
+	if v := pick(func(v *TaskVariant) bool { return v.Env != nil }); v != nil {
+		task.Env = v.Env
+	}
+
+	return &task
+}
+
+// Supports reports whether the task runs on the platform: it has no
+// per-platform run, or one for the platform or a default one.
+func (t *Task) Supports(platform string) bool {
+	hasRun := func(v *TaskVariant) bool { return v.Run != nil }
+	if !t.hasVariant(hasRun) {
+		return true
+	}
+
+	for _, key := range []string{platform, DefaultPlatform} {
+		if v, ok := t.Variants[key]; ok && hasRun(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Platforms returns the platforms the task has a run variant for,
+// sorted.
+func (t *Task) Platforms() []string {
+	platforms := []string{}
+	for key, v := range t.Variants {
+		if key != DefaultPlatform && v.Run != nil {
+			platforms = append(platforms, key)
+		}
+	}
+	sort.Strings(platforms)
+
+	return platforms
+}
+
+func (t *Task) hasVariant(has func(v *TaskVariant) bool) bool {
+	for _, v := range t.Variants {
+		if has(v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,9 +1,149 @@
 package schema
 
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hyprxlabs/run/internal/errors"
+	"go.yaml.in/yaml/v4"
+)
+
+// RunfileNames are the file names searched for, in order, when
+// looking for a runfile in a directory.
+var RunfileNames = []string{
+	"runfile.yaml",
+	"runfile.yml",
+	".runfile.yaml",
+	".runfile.yml",
+}
+
 type Runfile struct {
 	Name string
 
 	Config RunfileConfig
 
-	
+	Tasks Tasks
+
+	Hosts Hosts
+
+	Runtimes Runtimes
+
+	Executables Executables
+
+	// Path is the absolute path of the file the runfile was read from.
+	Path string
+}
+
+func (rf *Runfile) UnmarshalYAML(value *yaml.Node) error {
+	if rf == nil {
+		rf = &Runfile{}
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml mapping for runfile")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		key := keyNode.Value
+		switch key {
+		case "name":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'name' field")
+			}
+			rf.Name = valueNode.Value
+		case "config":
+			var config RunfileConfig
+			if err := valueNode.Decode(&config); err != nil {
+				return err
+			}
+			rf.Config = config
+		case "tasks":
+			var tasks Tasks
+			if err := valueNode.Decode(&tasks); err != nil {
+				return err
+			}
+			rf.Tasks = tasks
+		case "hosts":
+			var hosts Hosts
+			if err := valueNode.Decode(&hosts); err != nil {
+				return err
+			}
+			rf.Hosts = hosts
+		case "runtimes":
+			var runtimes Runtimes
+			if err := valueNode.Decode(&runtimes); err != nil {
+				return err
+			}
+			rf.Runtimes = runtimes
+		case "executables":
+			var executables Executables
+			if err := valueNode.Decode(&executables); err != nil {
+				return err
+			}
+			rf.Executables = executables
+		default:
+			return yamlErrorf(*keyNode, "unexpected field '%s' in runfile", key)
+		}
+	}
+
+	return nil
+}
+
+// Dir returns the directory that contains the runfile.
+func (rf *Runfile) Dir() string {
+	if rf == nil || rf.Path == "" {
+		return ""
+	}
+
+	return filepath.Dir(rf.Path)
+}
+
+// ReadRunfile reads and decodes the runfile at the given path.
+func ReadRunfile(path string) (*Runfile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rf Runfile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, errors.WithCause(errors.New("failed to read runfile "+abs+": "+err.Error()), err)
+	}
+
+	rf.Path = abs
+	return &rf, nil
+}
+
+// FindRunfile searches dir and its parents for a runfile and
+// returns the path of the first one found.
+func FindRunfile(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		for _, name := range RunfileNames {
+			path := filepath.Join(abs, name)
+			fi, err := os.Stat(path)
+			if err == nil && !fi.IsDir() {
+				return path, true
+			}
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+
+		abs = parent
+	}
 }
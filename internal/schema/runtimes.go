@@ -0,0 +1,222 @@
+package schema
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Runtime is a runtime defined in the runfile, which tasks use through
+// 'uses' like the built-in runtimes.
+type Runtime struct {
+	Id string
+
+	// Exe is the name of the executable, searched for on the PATH
+	// after the search paths. Defaults to the id.
+	Exe string
+
+	// Variable names the environment variable that overrides the
+	// path of the executable. Defaults to RUN_<ID>_EXE.
+	Variable string
+
+	// Windows, Linux and Darwin are the paths searched for the
+	// executable on each platform.
+	Windows []string
+	Linux   []string
+	Darwin  []string
+
+	// Extensions are the extensions of script files. Scripts that
+	// are a path ending with one of them run as files.
+	Extensions []string
+
+	// FileArgs are passed to the executable before the path of a
+	// script file.
+	FileArgs []string
+
+	// InlineArgs are passed to the executable before an inline
+	// script, e.g. '-e'.
+	InlineArgs []string
+
+	// Ext is the extension of the file inline scripts are written to
+	// when the executable cannot run them inline. Inline scripts run
+	// as files when Ext is set or InlineArgs is empty.
+	Ext string
+}
+
+type Runtimes struct {
+	entries map[string]Runtime
+	keys    []string
+}
+
+func (r *Runtime) UnmarshalYAML(value *yaml.Node) error {
+	if r == nil {
+		r = &Runtime{}
+	}
+
+	if value.Kind == yaml.ScalarNode {
+		r.Exe = value.Value
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml scalar or mapping for runtime")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		key := keyNode.Value
+		var list *[]string
+		switch key {
+		case "id":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'id' field")
+			}
+			r.Id = valueNode.Value
+			continue
+		case "exe", "executable":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'exe' field")
+			}
+			r.Exe = valueNode.Value
+			continue
+		case "variable":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'variable' field")
+			}
+			r.Variable = valueNode.Value
+			continue
+		case "ext":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'ext' field")
+			}
+			r.Ext = valueNode.Value
+			if r.Ext != "" && !strings.HasPrefix(r.Ext, ".") {
+				r.Ext = "." + r.Ext
+			}
+			continue
+		case "windows", "win", "win32":
+			list = &r.Windows
+		case "linux":
+			list = &r.Linux
+		case "darwin", "mac", "macos", "osx":
+			list = &r.Darwin
+		case "extensions":
+			list = &r.Extensions
+		case "file-args", "fileArgs", "file_args":
+			list = &r.FileArgs
+		case "inline-args", "inlineArgs", "inline_args":
+			list = &r.InlineArgs
+		default:
+			return yamlErrorf(*keyNode, "unexpected field '%s' in runtime", key)
+		}
+
+		if valueNode.Kind != yaml.SequenceNode {
+			return yamlErrorf(*valueNode, "expected yaml sequence for '%s' field", key)
+		}
+		*list = make([]string, 0)
+		for _, item := range valueNode.Content {
+			if item.Kind != yaml.ScalarNode {
+				return yamlErrorf(*item, "expected yaml scalar in '%s' list", key)
+			}
+			*list = append(*list, item.Value)
+		}
+	}
+
+	return nil
+}
+
+func (r *Runtimes) UnmarshalYAML(value *yaml.Node) error {
+	r.init()
+
+	if value.Kind != yaml.MappingNode {
+		return yamlErrorf(*value, "expected yaml mapping for runtimes")
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		keyNode := value.Content[i]
+		valueNode := value.Content[i+1]
+
+		var runtime Runtime
+		if err := valueNode.Decode(&runtime); err != nil {
+			return err
+		}
+
+		if runtime.Id == "" {
+			runtime.Id = keyNode.Value
+		}
+
+		if runtime.Exe == "" {
+			runtime.Exe = runtime.Id
+		}
+
+		r.Set(&runtime)
+	}
+
+	return nil
+}
+
+// Get returns the runtime with the given id, ignoring case.
+func (r *Runtimes) Get(id string) (Runtime, bool) {
+	if r == nil || r.entries == nil {
+		return Runtime{}, false
+	}
+
+	if entry, ok := r.entries[id]; ok {
+		return entry, true
+	}
+
+	for _, k := range r.keys {
+		if strings.EqualFold(k, id) {
+			return r.entries[k], true
+		}
+	}
+
+	return Runtime{}, false
+}
+
+// Set adds the runtime or replaces the one with the same id.
+func (r *Runtimes) Set(entry *Runtime) {
+	r.init()
+
+	if entry == nil || entry.Id == "" {
+		return
+	}
+
+	for _, k := range r.keys {
+		if strings.EqualFold(k, entry.Id) {
+			r.entries[k] = *entry
+			return
+		}
+	}
+
+	r.entries[entry.Id] = *entry
+	r.keys = append(r.keys, entry.Id)
+}
+
+func (r *Runtimes) Keys() []string {
+	if r == nil || r.entries == nil {
+		return []string{}
+	}
+
+	return r.keys
+}
+
+func (r *Runtimes) Len() int {
+	if r == nil || r.entries == nil {
+		return 0
+	}
+
+	return len(r.entries)
+}
+
+func (r *Runtimes) init() {
+	if r.entries == nil {
+		r.entries = map[string]Runtime{}
+	}
+
+	if r.keys == nil {
+		r.keys = []string{}
+	}
+}
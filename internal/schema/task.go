@@ -10,21 +10,30 @@ import (
 type With map[string]interface{}
 
 type Task struct {
-	Id        string
-	Desc      *string
-	Help      *string
-	Name      *string
-	Env       *Environment
-	DotEnv    []string
-	Cwd       *string
-	Timeout   *string
-	Run       *string
-	Uses      *string
-	Args      []string
-	Needs     []string
-	With      With
-	Hosts     []string
-	Condition *string
+	Id          string
+	Desc        *string
+	Help        *string
+	Name        *string
+	Env         *Environment
+	DotEnv      []string
+	Cwd         *string
+	Timeout     *string
+	TTY         bool
+	Run         *string
+	Uses        *string
+	Args        []string
+	Needs       []string
+	With        With
+	Permissions *Permissions
+	Hosts       []string
+	Condition   *string
+	Sources     []string
+	Generates   []string
+	Inputs      []Input
+
+	// Variants holds the per-platform values of run, uses, cwd and
+	// env, keyed by platform or DefaultPlatform, see ForPlatform.
+	Variants map[string]*TaskVariant
 }
 
 func (t *Task) UnmarshalYAML(value *yaml.Node) error {
@@ -68,6 +77,18 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 			}
 			t.Name = &valueNode.Value
 		case "env":
+			if isPlatformEnv(valueNode) {
+				for j := 0; j < len(valueNode.Content); j += 2 {
+					platform, _ := platformKey(valueNode.Content[j].Value)
+					var env Environment
+					if err := valueNode.Content[j+1].Decode(&env); err != nil {
+						return err
+					}
+					t.variant(platform).Env = &env
+				}
+				continue
+			}
+
 			var env Environment
 			if err := valueNode.Decode(&env); err != nil {
 				return err
@@ -85,8 +106,18 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 				t.DotEnv = append(t.DotEnv, item.Value)
 			}
 		case "cwd":
+			if valueNode.Kind == yaml.MappingNode {
+				err := decodeVariants(valueNode, "cwd", func(platform string, value *string) {
+					t.variant(platform).Cwd = value
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
 			if valueNode.Kind != yaml.ScalarNode {
-				return yamlErrorf(*valueNode, "expected yaml scalar for 'cwd' field")
+				return yamlErrorf(*valueNode, "expected yaml scalar or mapping for 'cwd' field")
 			}
 			t.Cwd = &valueNode.Value
 		case "timeout":
@@ -94,14 +125,46 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 				return yamlErrorf(*valueNode, "expected yaml scalar for 'timeout' field")
 			}
 			t.Timeout = &valueNode.Value
+		case "tty":
+			if valueNode.Kind != yaml.ScalarNode {
+				return yamlErrorf(*valueNode, "expected yaml scalar for 'tty' field")
+			}
+			switch valueNode.Value {
+			case "true":
+				t.TTY = true
+			case "false":
+				t.TTY = false
+			default:
+				return yamlErrorf(*valueNode, "expected 'true' or 'false' for 'tty' field")
+			}
 		case "run":
+			if valueNode.Kind == yaml.MappingNode {
+				err := decodeVariants(valueNode, "run", func(platform string, value *string) {
+					t.variant(platform).Run = value
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
 			if valueNode.Kind != yaml.ScalarNode {
-				return yamlErrorf(*valueNode, "expected yaml scalar for 'run' field")
+				return yamlErrorf(*valueNode, "expected yaml scalar or mapping for 'run' field")
 			}
 			t.Run = &valueNode.Value
 		case "uses":
+			if valueNode.Kind == yaml.MappingNode {
+				err := decodeVariants(valueNode, "uses", func(platform string, value *string) {
+					t.variant(platform).Uses = value
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
 			if valueNode.Kind != yaml.ScalarNode {
-				return yamlErrorf(*valueNode, "expected yaml scalar for 'uses' field")
+				return yamlErrorf(*valueNode, "expected yaml scalar or mapping for 'uses' field")
 			}
 			t.Uses = &valueNode.Value
 		case "args":
@@ -127,11 +190,31 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 				t.Needs = append(t.Needs, item.Value)
 			}
 		case "with", "input", "inputs":
+			// a sequence declares the inputs the task accepts, while
+			// a mapping provides values for the runtime in 'uses'.
+			if key != "with" && valueNode.Kind == yaml.SequenceNode {
+				t.Inputs = make([]Input, 0)
+				for _, item := range valueNode.Content {
+					var input Input
+					if err := item.Decode(&input); err != nil {
+						return err
+					}
+					t.Inputs = append(t.Inputs, input)
+				}
+				continue
+			}
+
 			var with With
 			if err := valueNode.Decode(&with); err != nil {
 				return err
 			}
 			t.With = with
+		case "permissions", "perms":
+			var perms Permissions
+			if err := valueNode.Decode(&perms); err != nil {
+				return err
+			}
+			t.Permissions = &perms
 		case "hosts":
 			if valueNode.Kind != yaml.SequenceNode {
 				return yamlErrorf(*valueNode, "expected yaml sequence for 'hosts' field")
@@ -143,6 +226,28 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 				}
 				t.Hosts = append(t.Hosts, item.Value)
 			}
+		case "sources":
+			if valueNode.Kind != yaml.SequenceNode {
+				return yamlErrorf(*valueNode, "expected yaml sequence for 'sources' field")
+			}
+			t.Sources = make([]string, 0)
+			for _, item := range valueNode.Content {
+				if item.Kind != yaml.ScalarNode {
+					return yamlErrorf(*item, "expected yaml scalar in 'sources' list")
+				}
+				t.Sources = append(t.Sources, item.Value)
+			}
+		case "generates", "outputs":
+			if valueNode.Kind != yaml.SequenceNode {
+				return yamlErrorf(*valueNode, "expected yaml sequence for 'generates' field")
+			}
+			t.Generates = make([]string, 0)
+			for _, item := range valueNode.Content {
+				if item.Kind != yaml.ScalarNode {
+					return yamlErrorf(*item, "expected yaml scalar in 'generates' list")
+				}
+				t.Generates = append(t.Generates, item.Value)
+			}
 		case "if", "condition":
 			if valueNode.Kind != yaml.ScalarNode {
 				return yamlErrorf(*valueNode, "expected yaml scalar for 'condition' field")
@@ -156,6 +261,28 @@ func (t *Task) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+// Namespace returns the part of the task id before the last ':',
+// or an empty string for tasks without a namespace.
+func (t *Task) Namespace() string {
+	i := strings.LastIndex(t.Id, ":")
+	if i == -1 {
+		return ""
+	}
+
+	return t.Id[:i]
+}
+
+// Input returns the declared input with the given id.
+func (t *Task) Input(id string) (*Input, bool) {
+	for i := range t.Inputs {
+		if strings.EqualFold(t.Inputs[i].Id, id) {
+			return &t.Inputs[i], true
+		}
+	}
+
+	return nil, false
+}
+
 func (w With) TryGetValue(key ...string) (interface{}, bool) {
 	if w == nil {
 		return nil, false
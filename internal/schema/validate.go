@@ -0,0 +1,248 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/dotenv"
+	"go.yaml.in/yaml/v4"
+)
+
+// Validate reads the runfile at path and checks it beyond what the
+// decoders enforce: needs that name unknown tasks, dependency
+// cycles, hosts and groups that are not declared, invalid timeouts
+// and dotenv files that cannot be read or that are invalid in strict
+// mode. Every problem is reported with the line and column of the
+// offending YAML node. The error is
+// only set when the file cannot be read.
+func Validate(path string) ([]error, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []error{err}, nil
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return []error{yamlError(doc, "runfile is empty")}, nil
+	}
+
+	var rf Runfile
+	if err := doc.Content[0].Decode(&rf); err != nil {
+		return []error{err}, nil
+	}
+
+	rf.Path = abs
+	return validate(&rf, doc.Content[0]), nil
+}
+
+// field returns the key and value nodes of the first of the keys
+// found in the mapping node.
+func field(node *yaml.Node, keys ...string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		for _, key := range keys {
+			if node.Content[i].Value == key {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// items returns the scalar items of a sequence node.
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	return node.Content
+}
+
+func validate(rf *Runfile, root *yaml.Node) []error {
+	problems := []error{}
+
+	_, tasksNode := field(root, "tasks")
+	taskNodes := map[string]*yaml.Node{}
+	taskKeys := map[string]*yaml.Node{}
+	if tasksNode != nil && tasksNode.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(tasksNode.Content); i += 2 {
+			task, ok := rf.Tasks.Get(tasksNode.Content[i].Value)
+			if !ok {
+				continue
+			}
+			taskKeys[task.Id] = tasksNode.Content[i]
+			taskNodes[task.Id] = tasksNode.Content[i+1]
+		}
+	}
+
+	lookup := func(name string) (Task, bool) {
+		task, ok := rf.Tasks.Get(name)
+		if !ok && rf.Name != "" {
+			if rest, found := strings.CutPrefix(name, rf.Name+":"); found {
+				task, ok = rf.Tasks.Get(rest)
+			}
+		}
+		return task, ok
+	}
+
+	for _, id := range rf.Tasks.Keys() {
+		task, _ := rf.Tasks.Get(id)
+		node := taskNodes[task.Id]
+		if node == nil {
+			continue
+		}
+
+		_, needsNode := field(node, "needs", "deps", "dependencies")
+		for _, item := range items(needsNode) {
+			if _, ok := lookup(item.Value); !ok {
+				problems = append(problems, yamlErrorf(*item, "task '%s' needs unknown task '%s'", task.Id, item.Value))
+			}
+		}
+
+		_, hostsNode := field(node, "hosts")
+		for _, item := range items(hostsNode) {
+			if _, ok := rf.Hosts.FindAll(item.Value); !ok {
+				problems = append(problems, yamlErrorf(*item, "task '%s' targets unknown host or group '%s'", task.Id, item.Value))
+			}
+		}
+
+		if _, timeoutNode := field(node, "timeout"); timeoutNode != nil && timeoutNode.Value != "" {
+			timeout, err := time.ParseDuration(timeoutNode.Value)
+			if err != nil {
+				problems = append(problems, yamlErrorf(*timeoutNode, "invalid timeout '%s' for task '%s'", timeoutNode.Value, task.Id))
+			} else if timeout <= 0 {
+				problems = append(problems, yamlErrorf(*timeoutNode, "timeout for task '%s' must be greater than zero", task.Id))
+			}
+		}
+
+		_, dotenvNode := field(node, "dotenv", "envfile", "env-file")
+		for _, item := range items(dotenvNode) {
+			// paths with variables can only be resolved when the task
+			// runs.
+			if strings.Contains(item.Value, "$") {
+				continue
+			}
+
+			path := item.Value
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(rf.Dir(), path)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				problems = append(problems, yamlErrorf(*item, "dotenv file '%s' of task '%s' cannot be read: %v", item.Value, task.Id, unwrapPathError(err)))
+				continue
+			}
+
+			if _, err := dotenv.ParseWithOptions(string(data), &dotenv.ParseOptions{Strict: true}); err != nil {
+				problems = append(problems, yamlErrorf(*item, "dotenv file '%s' of task '%s' is invalid: %v", item.Value, task.Id, err))
+			}
+		}
+
+		if permsKey, _ := field(node, "permissions", "perms"); permsKey != nil {
+			usesDeno := task.Uses != nil && strings.EqualFold(*task.Uses, "deno")
+			usesDeno = usesDeno || task.hasVariant(func(v *TaskVariant) bool {
+				return v.Uses != nil && strings.EqualFold(*v.Uses, "deno")
+			})
+			if !usesDeno {
+				problems = append(problems, yamlErrorf(*permsKey, "permissions of task '%s' only apply to tasks that use deno", task.Id))
+			}
+		}
+	}
+
+	for _, cycle := range cycles(rf, lookup) {
+		node := taskKeys[cycle[0]]
+		if node == nil {
+			continue
+		}
+		problems = append(problems, yamlErrorf(*node, "dependency cycle detected: %s", strings.Join(cycle, " -> ")))
+	}
+
+	return problems
+}
+
+// cycles returns each dependency cycle once, starting and ending
+// with the task where the cycle was entered.
+func cycles(rf *Runfile, lookup func(string) (Task, bool)) [][]string {
+	found := [][]string{}
+	done := map[string]bool{}
+	inCycle := map[string]bool{}
+	visiting := map[string]bool{}
+	stack := []string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		if done[id] {
+			return
+		}
+
+		if visiting[id] {
+			start := 0
+			for i, s := range stack {
+				if s == id {
+					start = i
+					break
+				}
+			}
+
+			cycle := append(append([]string{}, stack[start:]...), id)
+			for _, s := range cycle {
+				if inCycle[s] {
+					return
+				}
+			}
+
+			for _, s := range cycle {
+				inCycle[s] = true
+			}
+			found = append(found, cycle)
+			return
+		}
+
+		task, ok := lookup(id)
+		if !ok {
+			return
+		}
+
+		visiting[id] = true
+		stack = append(stack, task.Id)
+		for _, need := range task.Needs {
+			if t, ok := lookup(need); ok {
+				visit(t.Id)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		visiting[id] = false
+		done[id] = true
+	}
+
+	for _, id := range rf.Tasks.Keys() {
+		task, _ := rf.Tasks.Get(id)
+		visit(task.Id)
+	}
+
+	return found
+}
+
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,214 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRunfile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runfile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func messages(problems []error) []string {
+	list := []string{}
+	for _, p := range problems {
+		list = append(list, p.Error())
+	}
+	return list
+}
+
+func TestValidate_Valid(t *testing.T) {
+	path := writeRunfile(t, `
+hosts:
+  db1:
+    groups: [dbs]
+tasks:
+  a:
+    run: echo a
+    timeout: 1m
+  b:
+    needs: [a]
+    hosts: [dbs]
+    run: echo b
+`)
+
+	problems, err := schema.Validate(path)
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestValidate_Problems(t *testing.T) {
+	path := writeRunfile(t, `tasks:
+  a:
+    needs: [b, missing]
+    timeout: 5x
+  b:
+    deps: [a]
+    hosts: [nowhere]
+    dotenv: [missing.env, "${HOME}/.env", twice.env]
+    permissions: {read: [src]}
+`)
+	assert.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "twice.env"), []byte("A=1\nA=2\n"), 0644))
+
+	problems, err := schema.Validate(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"task 'a' needs unknown task 'missing' on line 3, at column 16",
+		"invalid timeout '5x' for task 'a' on line 4, at column 14",
+		"task 'b' targets unknown host or group 'nowhere' on line 7, at column 13",
+		"dotenv file 'missing.env' of task 'b' cannot be read: no such file or directory on line 8, at column 14",
+		"dotenv file 'twice.env' of task 'b' is invalid: duplicate key 'A' on line 2, column 1, first set on line 1 on line 8, at column 43",
+		"permissions of task 'b' only apply to tasks that use deno on line 9, at column 5",
+		"dependency cycle detected: a -> b -> a on line 2, at column 3",
+	}, messages(problems))
+}
+
+func TestValidate_DecodeError(t *testing.T) {
+	path := writeRunfile(t, "tasks:\n  a:\n    bogus: 1\n")
+
+	problems, err := schema.Validate(path)
+	assert.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0].Error(), "unexpected field 'bogus' in task on line 3, at column 5")
+}
+
+// decoderKeys returns the keys each UnmarshalYAML accepts, by
+// receiver type, read from the cases of its switch on key.
+func decoderKeys(t *testing.T) map[string][]string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	keys := map[string][]string{}
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "UnmarshalYAML" || fn.Recv == nil {
+				continue
+			}
+
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			typ := recv.(*ast.Ident).Name
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				sw, ok := n.(*ast.SwitchStmt)
+				if !ok {
+					return true
+				}
+				if tag, ok := sw.Tag.(*ast.Ident); !ok || tag.Name != "key" {
+					return true
+				}
+
+				for _, stmt := range sw.Body.List {
+					for _, expr := range stmt.(*ast.CaseClause).List {
+						if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							key, err := strconv.Unquote(lit.Value)
+							require.NoError(t, err)
+							keys[typ] = append(keys[typ], key)
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return keys
+}
+
+// properties returns the first properties found in the schema,
+// searching its oneOf, items and additionalProperties.
+func properties(schema interface{}) map[string]interface{} {
+	queue := []interface{}{schema}
+	for len(queue) > 0 {
+		m, ok := queue[0].(map[string]interface{})
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		if props, ok := m["properties"].(map[string]interface{}); ok {
+			return props
+		}
+		if list, ok := m["oneOf"].([]interface{}); ok {
+			queue = append(queue, list...)
+		}
+		queue = append(queue, m["items"], m["additionalProperties"])
+	}
+
+	return nil
+}
+
+func TestJSONSchema_Aliases(t *testing.T) {
+	data, err := schema.JSONSchema()
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	defs := doc["$defs"].(map[string]interface{})
+
+	schemas := map[string]interface{}{
+		"Runfile":             doc,
+		"RunfileConfig":       defs["config"],
+		"Dirs":                defs["dirs"],
+		"Paths":               defs["paths"],
+		"OS":                  defs["os"],
+		"environmentVariable": defs["environment"],
+		"HostEntry":           defs["host"],
+		"Input":               defs["input"],
+		"Permissions":         defs["permissions"],
+		"Task":                defs["task"],
+		"Runtime":             defs["runtime"],
+		"Executable":          defs["executable"],
+	}
+
+	keys := decoderKeys(t)
+	// Task decodes needs as a list of names, so Need is never used.
+	delete(keys, "Need")
+
+	for typ, accepted := range keys {
+		t.Run(typ, func(t *testing.T) {
+			def, ok := schemas[typ]
+			require.True(t, ok, "no schema for %s", typ)
+
+			props := properties(def)
+			require.NotNil(t, props)
+
+			names := []string{}
+			for name := range props {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, accepted, names)
+		})
+	}
+
+	for typ := range schemas {
+		assert.Contains(t, keys, typ)
+	}
+}
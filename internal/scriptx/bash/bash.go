@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/hyprxlabs/run/internal/cache"
 	"github.com/hyprxlabs/run/internal/exec"
 )
 
@@ -67,13 +68,42 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 	return NewContext(ctx, splat...)
 }
 
+// Inline runs the script with -c. Where the command line does not
+// carry scripts reliably, see inlineFile, the script is written to a
+// .sh file in the script cache and the file is run instead.
 func Inline(script string, args ...string) *exec.Cmd {
+	if inlineFile {
+		tmpFile, err := cache.Default().Write(".sh", []byte(script))
+		if err != nil {
+			cmd := New(args...)
+			cmd.Err = err
+			return cmd
+		}
+
+		cmd := File(tmpFile, args...)
+		cmd.TempFile = &tmpFile
+		return cmd
+	}
+
 	splat := append(ScriptArgs, "-c", script)
 	splat = append(splat, args...)
 	return New(splat...)
 }
 
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
+	if inlineFile {
+		tmpFile, err := cache.Default().Write(".sh", []byte(script))
+		if err != nil {
+			cmd := NewContext(ctx, args...)
+			cmd.Err = err
+			return cmd
+		}
+
+		cmd := FileContext(ctx, tmpFile, args...)
+		cmd.TempFile = &tmpFile
+		return cmd
+	}
+
 	splat := append(ScriptArgs, "-c", script)
 	splat = append(splat, args...)
 	return NewContext(ctx, splat...)
@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("bash", &exec.Executable{
-		Name:     "bash",
-		Variable: "RUN_BASH_EXE",
+		Name: "bash",
 		Linux: []string{
 			"/bin/bash",
 			"/usr/bin/bash",
@@ -15,6 +14,9 @@ func init() {
 	})
 }
 
+// inlineFile is false: inline scripts are passed to bash with -c.
+const inlineFile = false
+
 func resolveScriptFile(script string) string {
 	return script
 }
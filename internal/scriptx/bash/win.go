@@ -11,8 +11,7 @@ import (
 
 func init() {
 	exec.Register("bash", &exec.Executable{
-		Name:     "bash",
-		Variable: "RUN_WIN_BASH_EXE",
+		Name: "bash",
 		Windows: []string{
 			"${ProgramFiles}\\Git\\bin\\bash.exe",
 			"%ProgramFiles(x86)%\\Git\\bin\\bash.exe",
@@ -21,6 +20,10 @@ func init() {
 	})
 }
 
+// inlineFile is true: multi-line scripts with quotes do not survive
+// the Windows command line, so inline scripts are run from a file.
+const inlineFile = true
+
 func resolveScriptFile(script string) string {
 	if !filepath.IsAbs(script) {
 		file, err := filepath.Abs(script)
@@ -43,16 +43,16 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 	return NewContext(ctx, allArgs...)
 }
 
+// Inline runs the script from a file in the cache, see
+// InlineOptionsContext.
 func Inline(script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-e", script)
-	allArgs := append(splat, args...)
-	return New(allArgs...)
+	return InlineOptions(script, nil, args...)
 }
 
+// InlineContext runs the script from a file in the cache, see
+// InlineOptionsContext.
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-e", script)
-	allArgs := append(splat, args...)
-	return NewContext(ctx, allArgs...)
+	return InlineOptionsContext(ctx, script, nil, args...)
 }
 
 func Script(script string, args ...string) *exec.Cmd {
@@ -0,0 +1,75 @@
+package bun
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/scriptx/js"
+)
+
+// Options configures how scripts are run.
+type Options struct {
+	// Type overrides the type detected for inline scripts, see
+	// js.Detect.
+	Type js.Type
+
+	// Dir is the directory the packages of inline scripts resolve
+	// from, the working directory of the task.
+	Dir string
+
+	// Cache holds the files of inline scripts, defaults to
+	// cache.Default().
+	Cache *cache.Cache
+}
+
+// InlineOptions runs the script, see InlineOptionsContext.
+func InlineOptions(script string, options *Options, args ...string) *exec.Cmd {
+	return InlineOptionsContext(context.Background(), script, options, args...)
+}
+
+// InlineOptionsContext writes the script to a file in the cache whose
+// extension matches its type and runs it. Bun runs modules, commonjs
+// scripts and typescript as they are.
+func InlineOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if options == nil {
+		options = &Options{}
+	}
+
+	t := options.Type
+	if t == "" {
+		t = js.Detect(script)
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	path, err := js.Write(c, options.Dir, js.Ext(t, script), script)
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := FileContext(ctx, path, args...)
+	cmd.TempFile = &path
+	return cmd
+}
+
+// ScriptOptionsContext runs the script file or the inline script with
+// the options.
+func ScriptOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return FileContext(ctx, trimmed, args...)
+			}
+		}
+	}
+
+	return InlineOptionsContext(ctx, script, options, args...)
+}
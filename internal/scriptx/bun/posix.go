@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("bun", &exec.Executable{
-		Name:     "bun",
-		Variable: "RUN_BUN_EXE",
+		Name: "bun",
 		Linux: []string{
 			"${HOME}/.bun/bin/bun",
 			"${HOME}/.local/bin/bun",
@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("bun", &exec.Executable{
-		Name:     "bun",
-		Variable: "RUN_WIN_BUN_EXE",
+		Name: "bun",
 		Windows: []string{
 			"${USERPROFILE}\\.bun\\bin\\bun.exe",
 			"${LOCALAPPDATA}\\Programs\\bin\\bun.exe",
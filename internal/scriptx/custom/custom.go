@@ -0,0 +1,119 @@
+// Package custom runs scripts with runtimes that are defined in the
+// runfile instead of in a package of their own.
+package custom
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+// Runtime runs scripts with the executable of a runtime defined in
+// the runfile, with the same script, file and inline semantics as the
+// built-in runtimes.
+type Runtime struct {
+	schema.Runtime
+
+	// Cache holds the files of inline scripts, defaults to
+	// cache.Default().
+	Cache *cache.Cache
+
+	registry *exec.ExecutableRegistry
+}
+
+// New returns the runtime for the definition. Its executable is kept
+// in a registry of its own, so it does not replace the executable of
+// a built-in runtime with the same name for the rest of the process.
+func New(def schema.Runtime) *Runtime {
+	if def.Exe == "" {
+		def.Exe = def.Id
+	}
+
+	if def.Variable == "" {
+		def.Variable = Variable(def.Id)
+	}
+
+	// the executable is searched for on the PATH last.
+	registry := exec.NewRegistry()
+	registry.Set(def.Id, &exec.Executable{
+		Name:     def.Exe,
+		Variable: def.Variable,
+		Windows:  append(append([]string{}, def.Windows...), def.Exe),
+		Linux:    append(append([]string{}, def.Linux...), def.Exe),
+		Darwin:   def.Darwin,
+	})
+
+	return &Runtime{Runtime: def, registry: registry}
+}
+
+// Variable returns the name of the variable that overrides the
+// executable of the runtime, RUN_<ID>_EXE like the variables of the
+// built-in executables.
+func Variable(id string) string {
+	return exec.Variable(id)
+}
+
+// NewContext returns the command that runs the executable with args.
+func (r *Runtime) NewContext(ctx context.Context, args ...string) *exec.Cmd {
+	exe, _ := r.registry.Find(r.Id, nil)
+	if exe == "" {
+		exe = r.Exe
+	}
+
+	return exec.NewContext(ctx, exe, args...)
+}
+
+// FileContext runs the script file.
+func (r *Runtime) FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
+	splat := append(append([]string{}, r.FileArgs...), path)
+	return r.NewContext(ctx, append(splat, args...)...)
+}
+
+// InlineContext runs the inline script with InlineArgs, or from a
+// file in the cache when the runtime has an extension for inline
+// scripts or no inline args.
+func (r *Runtime) InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
+	ext := r.Ext
+	if ext == "" && len(r.InlineArgs) == 0 && len(r.Extensions) > 0 {
+		ext = r.Extensions[0]
+	}
+
+	if ext == "" && len(r.InlineArgs) > 0 {
+		splat := append(append([]string{}, r.InlineArgs...), script)
+		return r.NewContext(ctx, append(splat, args...)...)
+	}
+
+	c := r.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	path, err := c.Write(ext, []byte(script))
+	if err != nil {
+		cmd := r.NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := r.FileContext(ctx, path, args...)
+	cmd.TempFile = &path
+	return cmd
+}
+
+// ScriptContext runs the script as a file when it is a path ending
+// with one of the extensions of the runtime, and inline otherwise.
+func (r *Runtime) ScriptContext(ctx context.Context, script string, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range r.Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return r.FileContext(ctx, trimmed, args...)
+			}
+		}
+	}
+
+	return r.InlineContext(ctx, script, args...)
+}
@@ -0,0 +1,88 @@
+package custom
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/schema"
+)
+
+func TestVariable(t *testing.T) {
+	if v := Variable("perl"); v != "RUN_PERL_EXE" {
+		t.Errorf("expected RUN_PERL_EXE, got %s", v)
+	}
+
+	if v := Variable("node-lts"); v != "RUN_NODE_LTS_EXE" {
+		t.Errorf("expected RUN_NODE_LTS_EXE, got %s", v)
+	}
+}
+
+func TestScript(t *testing.T) {
+	ctx := context.Background()
+	rt := New(schema.Runtime{
+		Id:         "perl",
+		Extensions: []string{".pl"},
+		FileArgs:   []string{"-w"},
+		InlineArgs: []string{"-e"},
+	})
+
+	cmd := rt.ScriptContext(ctx, "script.pl", "a")
+	expected := []string{"-w", "script.pl", "a"}
+	if !reflect.DeepEqual(cmd.Args[1:], expected) {
+		t.Errorf("expected %v, got %v", expected, cmd.Args[1:])
+	}
+
+	cmd = rt.ScriptContext(ctx, "print 1", "a")
+	expected = []string{"-e", "print 1", "a"}
+	if !reflect.DeepEqual(cmd.Args[1:], expected) {
+		t.Errorf("expected %v, got %v", expected, cmd.Args[1:])
+	}
+
+	if rt.Variable != "RUN_PERL_EXE" {
+		t.Errorf("expected the default variable, got %s", rt.Variable)
+	}
+}
+
+func TestScript_TempFile(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir()}
+	rt := New(schema.Runtime{Id: "lua", Extensions: []string{".lua"}})
+	rt.Cache = c
+
+	cmd := rt.InlineContext(context.Background(), "print(1)", "a")
+	if cmd.TempFile == nil || !strings.HasSuffix(*cmd.TempFile, ".lua") {
+		t.Fatalf("expected the script to be written to a .lua file, got %v", cmd.TempFile)
+	}
+
+	expected := []string{*cmd.TempFile, "a"}
+	if !reflect.DeepEqual(cmd.Args[1:], expected) {
+		t.Errorf("expected %v, got %v", expected, cmd.Args[1:])
+	}
+
+	data, _ := os.ReadFile(*cmd.TempFile)
+	if string(data) != "print(1)" {
+		t.Errorf("expected the script in %s, got %q", *cmd.TempFile, data)
+	}
+}
+
+func TestNew_Override(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+
+	t.Setenv("RUN_SHX_EXE", "sh")
+	rt := New(schema.Runtime{Id: "shx", Exe: "missing-shell", InlineArgs: []string{"-c"}})
+
+	out, err := rt.InlineContext(context.Background(), "echo $0", "x").Output()
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if string(out.Stdout) != "x\n" {
+		t.Errorf("expected the script to run with sh, got %q", out.Stdout)
+	}
+}
@@ -1,9 +1,13 @@
 package deno
 
 import (
+	"context"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
 )
 
 func TestNew(t *testing.T) {
@@ -44,4 +48,42 @@ func TestScript_Inline(t *testing.T) {
 	if !strings.HasSuffix(cmd.Path, "deno") || !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
 		t.Errorf("expected path ending with 'deno' and args %v, got path '%s' and args %v", expectedArgs, cmd.Path, cmd.Args[1:])
 	}
-}
\ No newline at end of file
+}
+func TestScriptOptions(t *testing.T) {
+	ctx := context.Background()
+	cmd := ScriptOptionsContext(ctx, "main.ts", nil, "arg1")
+	expectedArgs := []string{"run", "-A", "main.ts", "arg1"}
+	if !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, cmd.Args[1:])
+	}
+
+	options := &Options{Allow: []string{"--allow-read=/src"}, Cache: &cache.Cache{Dir: t.TempDir()}}
+	cmd = ScriptOptionsContext(ctx, "main.ts", options, "arg1")
+	expectedArgs = []string{"run", "--allow-read=/src", "main.ts", "arg1"}
+	if !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, cmd.Args[1:])
+	}
+
+	// no permissions at all.
+	options.Allow = []string{}
+	cmd = ScriptOptionsContext(ctx, "console.log('hello')", options, "arg1")
+	if cmd.TempFile == nil {
+		t.Fatalf("expected the inline script to be written to a file")
+	}
+
+	expectedArgs = []string{"run", *cmd.TempFile, "arg1"}
+	if !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, cmd.Args[1:])
+	}
+
+	data, _ := os.ReadFile(*cmd.TempFile)
+	if string(data) != "console.log('hello')" {
+		t.Errorf("expected the script in %s, got %q", *cmd.TempFile, data)
+	}
+
+	cmd = ScriptOptionsContext(ctx, "console.log('hello')", nil, "arg1")
+	expectedArgs = []string{"eval", "console.log('hello')", "arg1"}
+	if !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, cmd.Args[1:])
+	}
+}
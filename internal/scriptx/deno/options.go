@@ -0,0 +1,79 @@
+package deno
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+)
+
+// Options configures how scripts are run.
+type Options struct {
+	// Allow are the permission flags scripts run with, e.g.
+	// '--allow-read=/src'. Nil means ScriptArgs, which allow
+	// everything, while an empty slice allows nothing.
+	Allow []string
+
+	// Cache holds the files of inline scripts that run with limited
+	// permissions, defaults to cache.Default().
+	Cache *cache.Cache
+}
+
+// allow returns the permission flags of the options.
+func (o *Options) allow() []string {
+	if o == nil || o.Allow == nil {
+		return ScriptArgs
+	}
+
+	return o.Allow
+}
+
+// FileOptionsContext runs the script file with the permissions of the
+// options.
+func FileOptionsContext(ctx context.Context, path string, options *Options, args ...string) *exec.Cmd {
+	splat := append([]string{"run"}, options.allow()...)
+	splat = append(splat, path)
+	return NewContext(ctx, append(splat, args...)...)
+}
+
+// InlineOptionsContext runs the inline script with the permissions of
+// the options. 'deno eval' always allows everything, so a script with
+// limited permissions is written to a file in the cache and run with
+// 'deno run' instead.
+func InlineOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if options == nil || options.Allow == nil {
+		return InlineContext(ctx, script, args...)
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	path, err := c.Write(".ts", []byte(script))
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := FileOptionsContext(ctx, path, options, args...)
+	cmd.TempFile = &path
+	return cmd
+}
+
+// ScriptOptionsContext runs the script file or the inline script with
+// the permissions of the options.
+func ScriptOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return FileOptionsContext(ctx, trimmed, options, args...)
+			}
+		}
+	}
+
+	return InlineOptionsContext(ctx, script, options, args...)
+}
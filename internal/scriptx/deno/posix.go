@@ -6,11 +6,10 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("deno", &exec.Executable{
-		Name:     "deno",
-		Variable: "RUN_DENO_EXE",
+		Name: "deno",
 		Linux: []string{
 			"/usr/bin/deno",
 			"/usr/local/bin/deno",
 		},
 	})
-}
\ No newline at end of file
+}
@@ -2,12 +2,10 @@ package dotnet
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/hyprxlabs/run/internal/cache"
 	"github.com/hyprxlabs/run/internal/exec"
 )
 
@@ -123,20 +121,11 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 }
 
 func Inline(script string, args ...string) *exec.Cmd {
-	tmpDir := os.TempDir()
-	data := []byte(script)
-
-	hash := sha256.Sum256(data)
-	hexHash := hex.EncodeToString(hash[:])
-	tmpFile := filepath.Join(tmpDir, "run-"+hexHash+".cs")
-
-	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
-		err := os.WriteFile(tmpFile, data, 0744)
-		if err != nil {
-			cmd := New(args...)
-			cmd.Err = err
-			return cmd
-		}
+	tmpFile, err := cache.Default().Write(".cs", []byte(script))
+	if err != nil {
+		cmd := New(args...)
+		cmd.Err = err
+		return cmd
 	}
 
 	cmd := File(tmpFile, args...)
@@ -145,20 +134,11 @@ func Inline(script string, args ...string) *exec.Cmd {
 }
 
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
-	tmpDir := os.TempDir()
-	data := []byte(script)
-
-	hash := sha256.Sum256(data)
-	hexHash := hex.EncodeToString(hash[:])
-	tmpFile := filepath.Join(tmpDir, "run-"+hexHash+".cs")
-
-	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
-		err := os.WriteFile(tmpFile, data, 0744)
-		if err != nil {
-			cmd := NewContext(ctx, args...)
-			cmd.Err = err
-			return cmd
-		}
+	tmpFile, err := cache.Default().Write(".cs", []byte(script))
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
 	}
 
 	cmd := FileContext(ctx, tmpFile, args...)
@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("dotnet", &exec.Executable{
-		Name:     "dotnet",
-		Variable: "RUN_DOTNET_EXE",
+		Name: "dotnet",
 		Linux: []string{
 			"$HOME/.dotnet/dotnet",
 			"$HOME/.local/share/dotnet/dotnet",
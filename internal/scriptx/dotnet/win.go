@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("dotnet", &exec.Executable{
-		Name:     "dotnet",
-		Variable: "RUN_WIN_DOTNET_EXE",
+		Name: "dotnet",
 		Windows: []string{
 			"${HOME}\\.dotnet\\dotnet.exe",
 			"${LOCALAPPDATA}\\dotnet\\dotnet.exe",
@@ -15,4 +14,4 @@ func init() {
 			"%ProgramFiles(x86)%\\dotnet\\dotnet.exe",
 		},
 	})
-}
\ No newline at end of file
+}
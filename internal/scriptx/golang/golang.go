@@ -2,10 +2,6 @@ package golang
 
 import (
 	"context"
-	"crypto/sha256"
-	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/hyprxlabs/run/internal/exec"
@@ -47,48 +43,16 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 	return NewContext(ctx, allArgs...)
 }
 
+// Inline builds the script in a module of its own and runs the
+// binary, see Build.
 func Inline(script string, args ...string) *exec.Cmd {
-	tmpDir := os.TempDir()
-
-	data := []byte(script)
-
-	hash := sha256.Sum256(data)
-	hashStr := fmt.Sprintf("%x", hash[:])
-	tmpFile := filepath.Join(tmpDir, "run-"+hashStr+".go")
-
-	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
-		if err := os.WriteFile(tmpFile, data, 0744); err != nil {
-			cmd := New(args...)
-			cmd.Err = err
-			return cmd
-		}
-	}
-
-	cmd := File(tmpFile, args...)
-	cmd.TempFile = &tmpFile
-	return cmd
+	return InlineOptions(script, nil, args...)
 }
 
+// InlineContext builds the script in a module of its own and runs
+// the binary, see Build.
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
-	tmpDir := os.TempDir()
-
-	data := []byte(script)
-
-	hash := sha256.Sum256(data)
-	hashStr := fmt.Sprintf("%x", hash[:])
-	tmpFile := filepath.Join(tmpDir, "run-"+hashStr+".go")
-
-	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
-		if err := os.WriteFile(tmpFile, data, 0744); err != nil {
-			cmd := NewContext(ctx, args...)
-			cmd.Err = err
-			return cmd
-		}
-	}
-
-	cmd := FileContext(ctx, tmpFile, args...)
-	cmd.TempFile = &tmpFile
-	return cmd
+	return InlineOptionsContext(ctx, script, nil, args...)
 }
 
 func Script(script string, args ...string) *exec.Cmd {
@@ -116,3 +80,18 @@ func ScriptContext(ctx context.Context, script string, args ...string) *exec.Cmd
 
 	return InlineContext(ctx, script, args...)
 }
+
+// ScriptOptionsContext runs the script file, or builds and runs the
+// inline script with the options, see Build.
+func ScriptOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return FileContext(ctx, trimmed, args...)
+			}
+		}
+	}
+
+	return InlineOptionsContext(ctx, script, options, args...)
+}
@@ -0,0 +1,269 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+)
+
+// Options configures how inline scripts are built.
+type Options struct {
+	// Requires lists the modules the script needs as path@version,
+	// or as path for the latest version, in addition to the ones
+	// named by //go:require comments.
+	Requires []string
+
+	// Cache holds the modules of the scripts and their binaries,
+	// defaults to cache.Default().
+	Cache *cache.Cache
+}
+
+// ModulePath is the module path of the module an inline script is
+// built in.
+const ModulePath = "run.local/script"
+
+var requireComment = regexp.MustCompile(`(?m)^\s*//go:require\s+(.+?)\s*$`)
+
+// stdPackages maps the names of common standard packages to their
+// import paths. Snippets that only hold statements get the packages
+// they use imported.
+var stdPackages = map[string]string{
+	"atomic":   "sync/atomic",
+	"base64":   "encoding/base64",
+	"bufio":    "bufio",
+	"bytes":    "bytes",
+	"context":  "context",
+	"errors":   "errors",
+	"exec":     "os/exec",
+	"filepath": "path/filepath",
+	"flag":     "flag",
+	"fmt":      "fmt",
+	"hex":      "encoding/hex",
+	"http":     "net/http",
+	"io":       "io",
+	"json":     "encoding/json",
+	"log":      "log",
+	"maps":     "maps",
+	"math":     "math",
+	"os":       "os",
+	"path":     "path",
+	"rand":     "math/rand",
+	"regexp":   "regexp",
+	"runtime":  "runtime",
+	"signal":   "os/signal",
+	"slices":   "slices",
+	"sort":     "sort",
+	"strconv":  "strconv",
+	"strings":  "strings",
+	"sync":     "sync",
+	"time":     "time",
+	"unicode":  "unicode",
+	"url":      "net/url",
+	"utf8":     "unicode/utf8",
+}
+
+// Requires returns the modules named by //go:require comments in the
+// script. A comment may name several modules separated by blanks.
+func Requires(script string) []string {
+	requires := []string{}
+	for _, match := range requireComment.FindAllStringSubmatch(script, -1) {
+		requires = append(requires, strings.Fields(match[1])...)
+	}
+
+	return requires
+}
+
+// Source turns the script into the source of a main package. A
+// script without a package clause is put in package main, and a
+// script that only holds statements is wrapped in func main, keeping
+// its imports in front and importing the standard packages it uses.
+func Source(script string) string {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "main.go", script, parser.PackageClauseOnly); err == nil {
+		return script
+	}
+
+	src := "package main\n\n" + script
+	if _, err := parser.ParseFile(fset, "main.go", src, parser.SkipObjectResolution); err == nil {
+		return src
+	}
+
+	// the imports end where the statements start.
+	header := "package main\n\n"
+	imports, err := parser.ParseFile(fset, "main.go", header+script, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+
+	body := script
+	if n := len(imports.Decls); n > 0 {
+		end := fset.Position(imports.Decls[n-1].End()).Offset - len(header)
+		header += script[:end] + "\n"
+		body = script[end:]
+	}
+
+	src = header + "func main() {\n" + strings.Trim(body, "\n") + "\n}\n"
+	missing := missingImports(fset, src)
+	if len(missing) == 0 {
+		return src
+	}
+
+	lines := []string{}
+	for _, path := range missing {
+		lines = append(lines, "import "+strconv.Quote(path)+"\n")
+	}
+
+	return "package main\n\n" + strings.Join(lines, "") + strings.TrimPrefix(src, "package main\n\n")
+}
+
+// missingImports returns the standard packages the source uses but
+// does not import.
+func missingImports(fset *token.FileSet, src string) []string {
+	file, err := parser.ParseFile(fset, "main.go", src, 0)
+	if err != nil {
+		return nil
+	}
+
+	imported := map[string]bool{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imported[name] = true
+	}
+
+	missing := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		// an identifier that is declared in the snippet, such as a
+		// variable named 'path', resolves to its declaration.
+		ident, ok := sel.X.(*ast.Ident)
+		if ok && ident.Obj == nil && !imported[ident.Name] {
+			if path, found := stdPackages[ident.Name]; found {
+				missing[path] = true
+			}
+		}
+
+		return true
+	})
+
+	paths := make([]string, 0, len(missing))
+	for path := range missing {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// Build builds the script as a main package of a module of its own
+// in the cache and returns the path of the binary. The module and the
+// binary are keyed by the source, the required modules and the go
+// toolchain, so running the same script again does not build it.
+func Build(ctx context.Context, script string, options *Options) (string, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	goExe, _ := exec.Find(NAME, nil)
+	if goExe == "" {
+		goExe = "go"
+	}
+
+	src := Source(script)
+	requires := append(Requires(script), options.Requires...)
+	toolchain := goExe
+	if info, err := os.Stat(goExe); err == nil {
+		toolchain += "@" + info.ModTime().String()
+	}
+
+	key := cache.Key(
+		[]byte(src),
+		[]byte(strings.Join(requires, " ")),
+		[]byte(toolchain),
+		[]byte(runtime.GOOS+"/"+runtime.GOARCH))
+
+	binary := "main"
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	dir, err := c.MakeDir("go-"+key, func(dir string) error {
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0600); err != nil {
+			return err
+		}
+
+		steps := [][]string{{"mod", "init", ModulePath}}
+		if len(requires) > 0 {
+			steps = append(steps, append([]string{"get"}, requires...))
+		}
+		steps = append(steps, []string{"mod", "tidy"}, []string{"build", "-o", binary, "."})
+
+		for _, step := range steps {
+			cmd := exec.NewContext(ctx, goExe, step...)
+			cmd.Dir = dir
+			// the cache may sit below a go.work file.
+			cmd.Env = append(os.Environ(), "GOWORK=off")
+			cmd.DisableLogger()
+			if out, err := cmd.Output(); err != nil {
+				msg := strings.TrimSpace(string(out.Stderr))
+				if msg == "" {
+					msg = err.Error()
+				}
+				return fmt.Errorf("go %s failed: %s", step[0], msg)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, binary), nil
+}
+
+// InlineOptions builds the inline script, see Build, and returns the
+// command that runs the binary.
+func InlineOptions(script string, options *Options, args ...string) *exec.Cmd {
+	return InlineOptionsContext(context.Background(), script, options, args...)
+}
+
+// InlineOptionsContext builds the inline script, see Build, and
+// returns the command that runs the binary.
+func InlineOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	binary, err := Build(ctx, script, options)
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := exec.NewContext(ctx, binary, args...)
+	src := filepath.Join(filepath.Dir(binary), "main.go")
+	cmd.TempFile = &src
+	return cmd
+}
@@ -0,0 +1,105 @@
+package golang
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
+)
+
+func TestRequires(t *testing.T) {
+	script := "//go:require github.com/google/uuid@v1.6.0\n// go:require ignored\n  //go:require a.com/b c.com/d@v2.0.0  \nfmt.Println()"
+	expected := []string{"github.com/google/uuid@v1.6.0", "a.com/b", "c.com/d@v2.0.0"}
+	if got := Requires(script); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			name:     "package",
+			script:   "package main\n\nfunc main() {}\n",
+			expected: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:     "declarations",
+			script:   "import \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+			expected: "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name:     "statements",
+			script:   "name := \"run\"\nfmt.Println(strings.ToUpper(name))\n",
+			expected: "package main\n\nimport \"fmt\"\nimport \"strings\"\nfunc main() {\nname := \"run\"\nfmt.Println(strings.ToUpper(name))\n}\n",
+		},
+		{
+			name:     "statements with imports",
+			script:   "import (\n\t\"fmt\"\n\tstr \"strings\"\n)\n\nfmt.Println(str.ToUpper(\"run\"))\n",
+			expected: "package main\n\nimport (\n\t\"fmt\"\n\tstr \"strings\"\n)\nfunc main() {\nfmt.Println(str.ToUpper(\"run\"))\n}\n",
+		},
+		{
+			name:     "shadowed package",
+			script:   "path := struct{ Base string }{\"x\"}\nprintln(path.Base)\n",
+			expected: "package main\n\nfunc main() {\npath := struct{ Base string }{\"x\"}\nprintln(path.Base)\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Source(test.script); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+
+	c := &cache.Cache{Dir: t.TempDir()}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOPROXY", "off")
+
+	script := "for i, arg := range os.Args[1:] {\n\tfmt.Println(i, strings.ToUpper(arg))\n}"
+	binary, err := Build(context.Background(), script, &Options{Cache: c})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	out, err := exec.Command(binary, "a", "b").Output()
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if string(out) != "0 A\n1 B\n" {
+		t.Errorf("expected output %q, got %q", "0 A\n1 B\n", out)
+	}
+
+	again, err := Build(context.Background(), script, &Options{Cache: c})
+	if err != nil || again != binary {
+		t.Errorf("expected the cached binary %s, got %s (%v)", binary, again, err)
+	}
+
+	entries, _ := c.List()
+	if len(entries) != 1 {
+		t.Errorf("expected 1 cache entry, got %d", len(entries))
+	}
+
+	_, err = Build(context.Background(), "undefined()", &Options{Cache: c})
+	if err == nil || !strings.Contains(err.Error(), "undefined") {
+		t.Errorf("expected a build error, got %v", err)
+	}
+
+	entries, _ = c.List()
+	if len(entries) != 1 {
+		t.Errorf("expected the failed build to leave no entry, got %d entries", len(entries))
+	}
+}
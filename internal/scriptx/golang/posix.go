@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("go", &exec.Executable{
-		Name:     "go",
-		Variable: "RUN_GO_EXE",
+		Name: "go",
 		Linux: []string{
 			"${HOME}/.local/shared/go/bin/go",
 			"/usr/local/go/bin/go",
@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("go", &exec.Executable{
-		Name:     "go",
-		Variable: "RUN_WIN_GO_EXE",
+		Name: "go",
 		Windows: []string{
 			"${ProgramFiles}\\Go\\bin\\go.exe",
 			"${ChocolateyInstall}\\lib\\go\\tools\\go\\bin\\go.exe",
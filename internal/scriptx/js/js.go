@@ -0,0 +1,137 @@
+// Package js holds what the javascript runtimes share to run inline
+// scripts: telling modules, commonjs and typescript apart and writing
+// scripts to files that resolve the packages of the project.
+package js
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+)
+
+// Type is the kind of an inline script.
+type Type string
+
+const (
+	// Module is an ECMAScript module, which may use import, export
+	// and top-level await. Scripts are modules unless detected
+	// otherwise.
+	Module Type = "module"
+
+	// CommonJS is a script that loads packages with require.
+	CommonJS Type = "commonjs"
+
+	// TypeScript is a module or a commonjs script with types.
+	TypeScript Type = "typescript"
+)
+
+var (
+	moduleSyntax   = regexp.MustCompile(`(?m)^\s*(import(\s+[\w*{]|\s*['"{*])|export\s)|\bimport\.meta\b`)
+	commonJSSyntax = regexp.MustCompile(`\brequire\s*\(|\bmodule\.exports\b|\bexports\.\w+\s*=|\b__dirname\b|\b__filename\b`)
+	typeSyntax     = regexp.MustCompile(`(?m)^\s*(export\s+)?(interface\s+\w+|type\s+\w+(<[^>]*>)?\s*=|enum\s+\w+|declare\s)|^\s*import\s+type\s|` +
+		`\)\s*:\s*[\w<>\[\]|]+\s*(=>|\{)|` +
+		`\b(const|let|var)\s+\w+\s*:\s*[\w<>\[\]|]+\s*=|` +
+		`[(,]\s*\w+\??\s*:\s*((string|number|boolean|any|unknown|void|never|object|bigint)\b|Record<|Array<|Promise<|\w+\[\])|` +
+		`\bas\s+(const|string|number|boolean|any|unknown)\b`)
+)
+
+// ParseType returns the type named by name, one of 'module' ('esm'),
+// 'commonjs' ('cjs') and 'typescript' ('ts').
+func ParseType(name string) (Type, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "module", "esm", "mjs":
+		return Module, nil
+	case "commonjs", "cjs":
+		return CommonJS, nil
+	case "typescript", "ts":
+		return TypeScript, nil
+	default:
+		return "", fmt.Errorf("invalid script type '%s', expected one of module, commonjs, typescript", name)
+	}
+}
+
+// Detect guesses the type of the script: typescript when it declares
+// types, commonjs when it uses require and no module syntax, and a
+// module otherwise.
+func Detect(script string) Type {
+	if typeSyntax.MatchString(script) {
+		return TypeScript
+	}
+
+	if isCommonJS(script) {
+		return CommonJS
+	}
+
+	return Module
+}
+
+// Ext returns the extension of the file the script of the given type
+// is written to, which tells the runtimes how to load it.
+func Ext(t Type, script string) string {
+	switch t {
+	case CommonJS:
+		return ".cjs"
+	case TypeScript:
+		if isCommonJS(script) {
+			return ".cts"
+		}
+		return ".mts"
+	default:
+		return ".mjs"
+	}
+}
+
+// Write stores the script in the cache and returns the path of the
+// file. When dir or one of its parents holds a node_modules directory,
+// the file is put next to a link to it, so the packages of the
+// project resolve as they would from the script in dir.
+func Write(c *cache.Cache, dir string, ext string, script string) (string, error) {
+	modules, ok := NodeModules(dir)
+	if !ok {
+		return c.Write(ext, []byte(script))
+	}
+
+	linked, err := c.MakeDir("js-"+cache.Key([]byte(modules)), func(tmp string) error {
+		return os.Symlink(modules, filepath.Join(tmp, "node_modules"))
+	})
+	if err != nil {
+		// links may need privileges, e.g. on windows.
+		return c.Write(ext, []byte(script))
+	}
+
+	return (&cache.Cache{Dir: linked}).Write(ext, []byte(script))
+}
+
+// NodeModules returns the node_modules directory in dir or its
+// nearest parent.
+func NodeModules(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		modules := filepath.Join(dir, "node_modules")
+		if info, err := os.Stat(modules); err == nil && info.IsDir() {
+			return modules, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func isCommonJS(script string) bool {
+	return commonJSSyntax.MatchString(script) && !moduleSyntax.MatchString(script)
+}
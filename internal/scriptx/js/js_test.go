@@ -0,0 +1,111 @@
+package js
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected Type
+	}{
+		{"plain", "console.log('hello')", Module},
+		{"import", "import fs from 'node:fs'\nconsole.log(fs)", Module},
+		{"await", "const r = await fetch(url)\nconsole.log(r.status)", Module},
+		{"require", "const fs = require('fs')\nconsole.log(fs)", CommonJS},
+		{"require and import", "import { createRequire } from 'node:module'\nconst require = createRequire(import.meta.url)\nrequire('x')", Module},
+		{"dynamic import", "const fs = require('fs')\nimport('x')", CommonJS},
+		{"interface", "interface User { name: string }\nconsole.log(1)", TypeScript},
+		{"type alias", "type Id = string | number", TypeScript},
+		{"annotated variable", "const n: number = 1", TypeScript},
+		{"annotated parameter", "function greet(name: string) { return name }", TypeScript},
+		{"return type", "function one(): number { return 1 }", TypeScript},
+		{"as const", "const xs = [1, 2] as const", TypeScript},
+		{"object literal", "const o = { name: 'x', n: 1 }\nconsole.log(o.name)", Module},
+		{"ternary", "const x = a ? b : c", Module},
+		{"value named like a type", "console.log({a: 1, b: objectValue})", Module},
+		{"argument named like a type", "f(x, y: numbers)", Module},
+		{"annotated object parameter", "function f(a, o: object) {}", TypeScript},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Detect(test.script); got != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestExt(t *testing.T) {
+	if ext := Ext(Module, ""); ext != ".mjs" {
+		t.Errorf("expected .mjs, got %s", ext)
+	}
+
+	if ext := Ext(CommonJS, ""); ext != ".cjs" {
+		t.Errorf("expected .cjs, got %s", ext)
+	}
+
+	if ext := Ext(TypeScript, "const n: number = 1"); ext != ".mts" {
+		t.Errorf("expected .mts, got %s", ext)
+	}
+
+	if ext := Ext(TypeScript, "const fs = require('fs')\nconst n: number = 1"); ext != ".cts" {
+		t.Errorf("expected .cts, got %s", ext)
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for name, expected := range map[string]Type{"module": Module, "ESM": Module, "commonjs": CommonJS, "cjs": CommonJS, "ts": TypeScript} {
+		if got, err := ParseType(name); err != nil || got != expected {
+			t.Errorf("expected %s for %s, got %s (%v)", expected, name, got, err)
+		}
+	}
+
+	if _, err := ParseType("jsx"); err == nil {
+		t.Errorf("expected an error for an unknown type")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir()}
+	project := t.TempDir()
+
+	path, err := Write(c, project, ".mjs", "console.log(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != c.Dir {
+		t.Errorf("expected the script in the cache, got %s", path)
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("links need privileges on windows")
+	}
+
+	modules := filepath.Join(project, "node_modules")
+	os.Mkdir(modules, 0755)
+	sub := filepath.Join(project, "src")
+	os.Mkdir(sub, 0755)
+
+	path, err = Write(c, sub, ".mjs", "console.log(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(filepath.Dir(path), "node_modules")
+	if target, err := os.Readlink(link); err != nil || target != modules {
+		t.Errorf("expected %s to link to %s, got %s (%v)", link, modules, target, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "console.log(1)" {
+		t.Errorf("expected the script in %s, got %q", path, data)
+	}
+}
@@ -1,9 +1,14 @@
 package node
 
 import (
+	"context"
+	"os/exec"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/scriptx/js"
 )
 
 func TestNew(t *testing.T) {
@@ -24,7 +29,11 @@ func TestFile(t *testing.T) {
 
 func TestInline(t *testing.T) {
 	cmd := Inline("console.log('hello')", "arg1")
-	expectedArgs := []string{"-e", "console.log('hello')", "arg1"}
+	if cmd.TempFile == nil || !strings.HasSuffix(*cmd.TempFile, ".mjs") {
+		t.Fatalf("expected the script to be written to a .mjs file, got %v", cmd.TempFile)
+	}
+
+	expectedArgs := []string{*cmd.TempFile, "arg1"}
 	if !strings.HasSuffix(cmd.Path, "node") || !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
 		t.Errorf("expected path ending with 'node' and args %v, got path '%s' and args %v", expectedArgs, cmd.Path, cmd.Args[1:])
 	}
@@ -40,8 +49,72 @@ func TestScript_File(t *testing.T) {
 
 func TestScript_Inline(t *testing.T) {
 	cmd := Script("console.log('hello')", "arg1")
-	expectedArgs := []string{"-e", "console.log('hello')", "arg1"}
+	if cmd.TempFile == nil || !strings.HasSuffix(*cmd.TempFile, ".mjs") {
+		t.Fatalf("expected the script to be written to a .mjs file, got %v", cmd.TempFile)
+	}
+
+	expectedArgs := []string{*cmd.TempFile, "arg1"}
 	if !strings.HasSuffix(cmd.Path, "node") || !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
 		t.Errorf("expected path ending with 'node' and args %v, got path '%s' and args %v", expectedArgs, cmd.Path, cmd.Args[1:])
 	}
-}
\ No newline at end of file
+}
+func TestInlineOptions_Module(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not found")
+	}
+
+	options := &Options{Cache: &cache.Cache{Dir: t.TempDir()}}
+	script := "import { basename } from 'node:path'\nconst name = await Promise.resolve(basename('/a/b.txt'))\nconsole.log(name, process.argv.slice(2).join(','))"
+	out, err := InlineOptions(script, options, "x", "y").Output()
+	if err != nil {
+		t.Fatalf("run failed: %v %s", err, out.Stderr)
+	}
+
+	if string(out.Stdout) != "b.txt x,y\n" {
+		t.Errorf("expected %q, got %q", "b.txt x,y\n", out.Stdout)
+	}
+
+	options.Type = js.CommonJS
+	out, err = InlineOptions("console.log(typeof require)", options).Output()
+	if err != nil || string(out.Stdout) != "function\n" {
+		t.Errorf("expected a commonjs script, got %q (%v)", out.Stdout, err)
+	}
+}
+
+func TestInlineOptions_TypeScript(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not found")
+	}
+
+	options := &Options{Cache: &cache.Cache{Dir: t.TempDir()}, Dir: t.TempDir()}
+	cmd := InlineOptions("const n: number = 1\nconsole.log(n + 1)", options)
+	if cmd.TempFile == nil || !strings.HasSuffix(*cmd.TempFile, ".mts") {
+		t.Fatalf("expected the script to be written to a .mts file, got %v", cmd.TempFile)
+	}
+
+	if _, ok := stripTypes(context.Background(), node); !ok {
+		if _, ok := findTsx(options.Dir); !ok {
+			if cmd.Err == nil || !strings.Contains(cmd.Err.Error(), "22.6") {
+				t.Errorf("expected an error about the node version, got %v", cmd.Err)
+			}
+			return
+		}
+	}
+
+	out, err := cmd.Output()
+	if err != nil || string(out.Stdout) != "2\n" {
+		t.Errorf("expected %q, got %q (%v %s)", "2\n", out.Stdout, err, out.Stderr)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	major, minor, ok := parseVersion("v22.6.0")
+	if !ok || major != 22 || minor != 6 {
+		t.Errorf("expected 22.6, got %d.%d (%v)", major, minor, ok)
+	}
+
+	if _, _, ok := parseVersion("unknown"); ok {
+		t.Errorf("expected an invalid version")
+	}
+}
@@ -0,0 +1,188 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+	"github.com/hyprxlabs/run/internal/scriptx/js"
+)
+
+// Options configures how scripts are run.
+type Options struct {
+	// Type overrides the type detected for inline scripts, see
+	// js.Detect.
+	Type js.Type
+
+	// Dir is the directory the packages of inline scripts resolve
+	// from, the working directory of the task.
+	Dir string
+
+	// Cache holds the files of inline scripts, defaults to
+	// cache.Default().
+	Cache *cache.Cache
+}
+
+// versions caches the versions of the node executables, keyed by
+// path.
+var versions sync.Map
+
+// InlineOptions runs the script, see InlineOptionsContext.
+func InlineOptions(script string, options *Options, args ...string) *exec.Cmd {
+	return InlineOptionsContext(context.Background(), script, options, args...)
+}
+
+// InlineOptionsContext writes the script to a file in the cache whose
+// extension matches its type, so node loads modules and commonjs
+// scripts as such, and runs it. Typescript is run by node when it
+// strips types, from version 22.6, and by tsx otherwise.
+func InlineOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if options == nil {
+		options = &Options{}
+	}
+
+	t := options.Type
+	if t == "" {
+		t = js.Detect(script)
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	path, err := js.Write(c, options.Dir, js.Ext(t, script), script)
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := FileOptionsContext(ctx, path, options, args...)
+	cmd.TempFile = &path
+	return cmd
+}
+
+// FileOptionsContext runs the script file, passing node the flags it
+// needs to run typescript files.
+func FileOptionsContext(ctx context.Context, path string, options *Options, args ...string) *exec.Cmd {
+	if options == nil {
+		options = &Options{}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ts", ".mts", ".cts":
+	default:
+		return FileContext(ctx, path, args...)
+	}
+
+	exe, _ := exec.Find(NAME, nil)
+	if exe == "" {
+		exe = "node"
+	}
+
+	flags, ok := stripTypes(ctx, exe)
+	if ok {
+		splat := append(append(append([]string{}, ScriptArgs...), flags...), path)
+		return NewContext(ctx, append(splat, args...)...)
+	}
+
+	tsx, found := findTsx(options.Dir)
+	if !found {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = errors.New("node runs typescript from version 22.6, install a newer node or tsx")
+		return cmd
+	}
+
+	return exec.NewContext(ctx, tsx, append([]string{path}, args...)...)
+}
+
+// ScriptOptionsContext runs the script file or the inline script with
+// the options.
+func ScriptOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return FileOptionsContext(ctx, trimmed, options, args...)
+			}
+		}
+	}
+
+	return InlineOptionsContext(ctx, script, options, args...)
+}
+
+// stripTypes returns the flags node needs to run typescript and
+// reports whether it can.
+func stripTypes(ctx context.Context, exe string) ([]string, bool) {
+	major, minor, ok := parseVersion(version(ctx, exe))
+	switch {
+	case !ok:
+		return nil, false
+	case major > 23 || major == 23 && minor >= 6:
+		return []string{}, true
+	case major > 22 || major == 22 && minor >= 6:
+		return []string{"--experimental-strip-types", "--disable-warning=ExperimentalWarning"}, true
+	default:
+		return nil, false
+	}
+}
+
+// version returns the output of 'node --version', e.g. 'v22.6.0'.
+func version(ctx context.Context, exe string) string {
+	if v, ok := versions.Load(exe); ok {
+		return v.(string)
+	}
+
+	cmd := exec.NewContext(ctx, exe, "--version")
+	cmd.DisableLogger()
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	v := strings.TrimSpace(string(out.Stdout))
+	versions.Store(exe, v)
+	return v
+}
+
+func parseVersion(v string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return major, minor, true
+}
+
+// findTsx returns tsx of the project in dir or the one on the PATH.
+func findTsx(dir string) (string, bool) {
+	name := "tsx"
+	if runtime.GOOS == "windows" {
+		name += ".cmd"
+	}
+
+	if modules, ok := js.NodeModules(dir); ok {
+		if tsx, ok := exec.Which(filepath.Join(modules, ".bin", name)); ok {
+			return tsx, true
+		}
+	}
+
+	return exec.Which("tsx")
+}
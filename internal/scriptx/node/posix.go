@@ -6,11 +6,10 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("node", &exec.Executable{
-		Name:     "node",
-		Variable: "RUN_NODE_EXE",
+		Name: "node",
 		Linux: []string{
 			"/usr/bin/node",
 			"/usr/local/bin/node",
 		},
 	})
-}
\ No newline at end of file
+}
@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("pwsh", &exec.Executable{
-		Name:     "pwsh",
-		Variable: "RUN_PWSH_EXE",
+		Name: "pwsh",
 		Linux: []string{
 			"/bin/pwsh",
 			"/usr/bin/pwsh",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/hyprxlabs/run/internal/cache"
 	"github.com/hyprxlabs/run/internal/exec"
 )
 
@@ -41,16 +42,33 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 	return NewContext(ctx, splat...)
 }
 
+// Inline writes the script to a .ps1 file in the script cache and
+// runs the file, so the args reach the script as $args, which they
+// do not with -Command.
 func Inline(script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-Command", script)
-	splat = append(splat, args...)
-	return New(splat...)
+	tmpFile, err := cache.Default().Write(".ps1", []byte(script))
+	if err != nil {
+		cmd := New(args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := File(tmpFile, args...)
+	cmd.TempFile = &tmpFile
+	return cmd
 }
 
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-Command", script)
-	splat = append(splat, args...)
-	return NewContext(ctx, splat...)
+	tmpFile, err := cache.Default().Write(".ps1", []byte(script))
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	cmd := FileContext(ctx, tmpFile, args...)
+	cmd.TempFile = &tmpFile
+	return cmd
 }
 
 func Script(script string, args ...string) *exec.Cmd {
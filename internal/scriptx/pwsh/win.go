@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("pwsh", &exec.Executable{
-		Name:     "pwsh",
-		Variable: "RUN_WIN_PWSH_EXE",
+		Name: "pwsh",
 		Windows: []string{
 			"${ProgramFiles}\\PowerShell\\7\\pwsh.exe",
 			"%ProgramFiles(x86)%\\PowerShell\\7\\pwsh.exe",
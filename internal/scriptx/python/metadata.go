@@ -0,0 +1,89 @@
+package python
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Metadata is the inline script metadata of a script, the '# /// script'
+// block defined by PEP 723.
+type Metadata struct {
+	// RequiresPython is the version specifier of the python the script
+	// needs, e.g. '>=3.11'.
+	RequiresPython string
+
+	// Dependencies are the requirements of the script, e.g. 'rich' or
+	// 'requests<3'.
+	Dependencies []string
+}
+
+// metadataBlock matches the metadata blocks of a script as given by
+// the reference implementation of PEP 723.
+var metadataBlock = regexp.MustCompile(`(?m)^# /// (?P<type>[a-zA-Z0-9-]+)$\s(?P<content>(^#(| .*)$\s)+)^# ///$`)
+
+var (
+	requiresPythonKey = regexp.MustCompile(`(?m)^requires-python\s*=\s*(?:"([^"]*)"|'([^']*)')\s*(?:#.*)?$`)
+	dependenciesKey   = regexp.MustCompile(`(?ms)^dependencies\s*=\s*\[(.*?)\]`)
+	tomlString        = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'([^']*)'`)
+	tomlComment       = regexp.MustCompile(`(?m)#[^"'\n]*$`)
+)
+
+// ParseMetadata reads the '# /// script' block of the script. It
+// reports false when the script has none, and returns an error when
+// it has more than one. Only the keys run needs are read from the
+// toml of the block, 'requires-python' and 'dependencies'.
+func ParseMetadata(script string) (*Metadata, bool, error) {
+	// the block must end with a newline, which the last line of a
+	// script may lack.
+	if !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+	script = strings.ReplaceAll(script, "\r\n", "\n")
+
+	var content string
+	found := false
+	for _, match := range metadataBlock.FindAllStringSubmatch(script, -1) {
+		if match[1] != "script" {
+			continue
+		}
+
+		if found {
+			return nil, false, fmt.Errorf("python script has more than one '# /// script' block")
+		}
+
+		found = true
+		content = match[2]
+	}
+
+	if !found {
+		return nil, false, nil
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		line = strings.TrimPrefix(line, "#")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	toml := strings.Join(lines, "\n")
+
+	meta := &Metadata{Dependencies: []string{}}
+	if m := requiresPythonKey.FindStringSubmatch(toml); m != nil {
+		meta.RequiresPython = m[1] + m[2]
+	}
+
+	if m := dependenciesKey.FindStringSubmatch(tomlComment.ReplaceAllString(toml, "")); m != nil {
+		for _, s := range tomlString.FindAllStringSubmatch(m[1], -1) {
+			dep := s[2]
+			if s[1] != "" {
+				dep = strings.ReplaceAll(s[1], `\"`, `"`)
+			}
+
+			if dep = strings.TrimSpace(dep); dep != "" {
+				meta.Dependencies = append(meta.Dependencies, dep)
+			}
+		}
+	}
+
+	return meta, true, nil
+}
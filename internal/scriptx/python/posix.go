@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("python", &exec.Executable{
-		Name:     "python",
-		Variable: "RUN_PYTHON_EXE",
+		Name: "python",
 		Linux: []string{
 			"/usr/bin/python",
 			"/usr/bin/python3",
@@ -15,4 +14,14 @@ func init() {
 			"/usr/local/bin/python3",
 		},
 	})
+
+	exec.Register("uv", &exec.Executable{
+		Name: "uv",
+		Linux: []string{
+			"${HOME}/.local/bin/uv",
+			"${HOME}/.cargo/bin/uv",
+			"/usr/local/bin/uv",
+			"/usr/bin/uv",
+		},
+	})
 }
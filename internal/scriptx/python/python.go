@@ -2,8 +2,11 @@ package python
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/hyprxlabs/run/internal/cache"
 	"github.com/hyprxlabs/run/internal/exec"
 )
 
@@ -13,6 +16,18 @@ var ScriptArgs = []string{}
 
 var Extensions = []string{".py"}
 
+// Options configures how scripts are run.
+type Options struct {
+	// Dirs are searched in order for a project virtualenv, see
+	// FindVenv, whose python then runs the script. Relative script
+	// files are read from the first directory.
+	Dirs []string
+
+	// Cache holds the files of inline scripts and the virtualenvs of
+	// scripts with inline metadata, defaults to cache.Default().
+	Cache *cache.Cache
+}
+
 func New(args ...string) *exec.Cmd {
 	exe, _ := exec.Find(NAME, nil)
 	if exe == "" {
@@ -43,16 +58,84 @@ func FileContext(ctx context.Context, path string, args ...string) *exec.Cmd {
 	return NewContext(ctx, allArgs...)
 }
 
+// Inline runs the script, see InlineOptions.
 func Inline(script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-c", script)
-	allArgs := append(splat, args...)
-	return New(allArgs...)
+	return InlineOptions(script, nil, args...)
 }
 
+// InlineContext runs the script, see InlineOptions.
 func InlineContext(ctx context.Context, script string, args ...string) *exec.Cmd {
-	splat := append(ScriptArgs, "-c", script)
-	allArgs := append(splat, args...)
-	return NewContext(ctx, allArgs...)
+	return InlineOptionsContext(ctx, script, nil, args...)
+}
+
+// InlineOptions runs the script, see InlineOptionsContext.
+func InlineOptions(script string, options *Options, args ...string) *exec.Cmd {
+	return InlineOptionsContext(context.Background(), script, options, args...)
+}
+
+// InlineOptionsContext runs the script with the python of the project
+// virtualenv, or with the python of a cached virtualenv that holds
+// the dependencies of its inline metadata, see Venv. Scripts of more
+// than one line are run from a file in the cache, so tracebacks point
+// at their lines.
+func InlineOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if options == nil {
+		options = &Options{}
+	}
+
+	python, err := interpreter(ctx, script, options)
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	if !strings.ContainsAny(strings.TrimSpace(script), "\n\r") {
+		splat := append(ScriptArgs, "-c", script)
+		return exec.NewContext(ctx, python, append(splat, args...)...)
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	path, err := c.Write(".py", []byte(script))
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	splat := append(ScriptArgs, path)
+	cmd := exec.NewContext(ctx, python, append(splat, args...)...)
+	cmd.TempFile = &path
+	return cmd
+}
+
+// FileOptionsContext runs the script file like InlineOptionsContext
+// runs inline scripts, with the python of a virtualenv.
+func FileOptionsContext(ctx context.Context, path string, options *Options, args ...string) *exec.Cmd {
+	if options == nil {
+		options = &Options{}
+	}
+
+	file := path
+	if !filepath.IsAbs(file) && len(options.Dirs) > 0 {
+		file = filepath.Join(options.Dirs[0], file)
+	}
+
+	// a file that cannot be read fails when python runs it.
+	script, _ := os.ReadFile(file)
+	python, err := interpreter(ctx, string(script), options)
+	if err != nil {
+		cmd := NewContext(ctx, args...)
+		cmd.Err = err
+		return cmd
+	}
+
+	splat := append(ScriptArgs, path)
+	return exec.NewContext(ctx, python, append(splat, args...)...)
 }
 
 func Script(script string, args ...string) *exec.Cmd {
@@ -78,3 +161,43 @@ func ScriptContext(ctx context.Context, script string, args ...string) *exec.Cmd
 	}
 	return InlineContext(ctx, script, args...)
 }
+
+// ScriptOptionsContext runs the script file or the inline script with
+// the options, see InlineOptionsContext.
+func ScriptOptionsContext(ctx context.Context, script string, options *Options, args ...string) *exec.Cmd {
+	if !strings.ContainsAny(script, "\n\r") {
+		trimmed := strings.TrimSpace(script)
+		for _, ext := range Extensions {
+			if strings.HasSuffix(trimmed, ext) {
+				return FileOptionsContext(ctx, trimmed, options, args...)
+			}
+		}
+	}
+
+	return InlineOptionsContext(ctx, script, options, args...)
+}
+
+// interpreter returns the python that runs the script: the python of
+// a virtualenv with the dependencies of its inline metadata, the
+// python of the project virtualenv or the python found on the system.
+func interpreter(ctx context.Context, script string, options *Options) (string, error) {
+	meta, ok, err := ParseMetadata(script)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return Venv(ctx, meta, options)
+	}
+
+	if venv, ok := FindVenv(options.Dirs...); ok {
+		return VenvPython(venv), nil
+	}
+
+	exe, _ := exec.Find(NAME, nil)
+	if exe == "" {
+		exe = "python"
+	}
+
+	return exe, nil
+}
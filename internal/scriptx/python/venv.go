@@ -0,0 +1,164 @@
+package python
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/cache"
+	"github.com/hyprxlabs/run/internal/exec"
+)
+
+// VenvNames are the names of the project virtualenv directories, in
+// the order they are looked for.
+var VenvNames = []string{".venv", "venv"}
+
+// FindVenv returns the first virtualenv named by VenvNames in the
+// directories. A directory is a virtualenv when it holds the
+// pyvenv.cfg file and a python executable.
+func FindVenv(dirs ...string) (string, bool) {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		for _, name := range VenvNames {
+			venv := filepath.Join(dir, name)
+			if _, err := os.Stat(filepath.Join(venv, "pyvenv.cfg")); err != nil {
+				continue
+			}
+
+			if _, err := os.Stat(VenvPython(venv)); err == nil {
+				return venv, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// VenvPython returns the path of the python executable of the
+// virtualenv.
+func VenvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+
+	return filepath.Join(venv, "bin", "python")
+}
+
+// Venv returns the python of a virtualenv in the cache that holds the
+// dependencies of the metadata. The virtualenv is keyed by the
+// metadata and the python it is created from, so scripts with the
+// same metadata share it. It is created with uv when uv is found,
+// which also picks a python that satisfies 'requires-python', and with
+// 'python -m venv' and pip otherwise.
+func Venv(ctx context.Context, meta *Metadata, options *Options) (string, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	c := options.Cache
+	if c == nil {
+		c = cache.Default()
+	}
+
+	base := pythonExe()
+	uv := uvExe()
+
+	deps := append([]string{}, meta.Dependencies...)
+	sort.Strings(deps)
+	interpreter := base
+	if info, err := os.Stat(base); err == nil {
+		interpreter += "@" + info.ModTime().String()
+	}
+
+	key := cache.Key(
+		[]byte(strings.Join(deps, "\n")),
+		[]byte(meta.RequiresPython),
+		[]byte(interpreter),
+		[]byte(runtime.GOOS+"/"+runtime.GOARCH))
+
+	// a virtualenv can be moved as long as its python is run by path,
+	// which is all run does with it.
+	dir, err := c.MakeDir("py-"+key, func(dir string) error {
+		var steps [][]string
+		if uv != "" {
+			python := base
+			if meta.RequiresPython != "" {
+				python = meta.RequiresPython
+			}
+
+			steps = append(steps, []string{uv, "venv", "--quiet", "--python", python, dir})
+			if len(deps) > 0 {
+				install := []string{uv, "pip", "install", "--quiet", "--python", VenvPython(dir)}
+				steps = append(steps, append(install, deps...))
+			}
+		} else if len(deps) == 0 {
+			// pip is only needed to install dependencies.
+			steps = append(steps, []string{base, "-m", "venv", "--without-pip", dir})
+		} else {
+			steps = append(steps, []string{base, "-m", "venv", dir})
+			install := []string{VenvPython(dir), "-m", "pip", "install", "--quiet", "--disable-pip-version-check"}
+			steps = append(steps, append(install, deps...))
+		}
+
+		for _, step := range steps {
+			cmd := exec.NewContext(ctx, step[0], step[1:]...)
+			cmd.DisableLogger()
+			if out, err := cmd.Output(); err != nil {
+				msg := strings.TrimSpace(string(out.Stderr))
+				if msg == "" {
+					msg = err.Error()
+				}
+
+				name := filepath.Base(step[0])
+				if step[1] == "-m" {
+					name = "python -m " + step[2]
+				} else if name != "python" {
+					name += " " + step[1]
+				}
+				return fmt.Errorf("%s failed: %s", name, msg)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return VenvPython(dir), nil
+}
+
+// pythonExe returns the path of the python that creates virtualenvs.
+func pythonExe() string {
+	exe, _ := exec.Find(NAME, nil)
+	if exe != "" {
+		return exe
+	}
+
+	for _, name := range []string{"python3", "python"} {
+		if exe, ok := exec.Which(name); ok {
+			return exe
+		}
+	}
+
+	return "python"
+}
+
+// uvExe returns the path of uv, or an empty string when it is not
+// installed.
+func uvExe() string {
+	exe, _ := exec.Find("uv", nil)
+	if exe != "" {
+		return exe
+	}
+
+	exe, _ = exec.Which("uv")
+	return exe
+}
@@ -0,0 +1,181 @@
+package python
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyprxlabs/run/internal/cache"
+)
+
+func TestParseMetadata(t *testing.T) {
+	script := `# /// script
+# requires-python = ">=3.11"  # any recent python
+# dependencies = [
+#   "requests<3",
+#   'rich',  # pretty output
+# ]
+#
+# [tool.run]
+# ignored = ["x"]
+# ///
+
+import rich
+`
+	meta, ok, err := ParseMetadata(script)
+	if err != nil || !ok {
+		t.Fatalf("expected metadata, got %v %v", ok, err)
+	}
+
+	if meta.RequiresPython != ">=3.11" {
+		t.Errorf("expected requires-python >=3.11, got %q", meta.RequiresPython)
+	}
+
+	expected := []string{"requests<3", "rich"}
+	if !reflect.DeepEqual(meta.Dependencies, expected) {
+		t.Errorf("expected dependencies %v, got %v", expected, meta.Dependencies)
+	}
+
+	if _, ok, err := ParseMetadata("# /// pyproject\n# x = 1\n# ///\nprint(1)"); ok || err != nil {
+		t.Errorf("expected no script metadata, got %v %v", ok, err)
+	}
+
+	if _, ok, _ := ParseMetadata("# /// script\n# dependencies = []\nprint(1)"); ok {
+		t.Errorf("expected an unterminated block to be ignored")
+	}
+
+	twice := "# /// script\n# dependencies = []\n# ///\nprint(1)\n# /// script\n# dependencies = []\n# ///"
+	if _, _, err := ParseMetadata(twice); err == nil {
+		t.Errorf("expected an error for two script blocks")
+	}
+
+	meta, ok, err = ParseMetadata("# /// script\r\n# dependencies = []\r\n# ///")
+	if err != nil || !ok || len(meta.Dependencies) != 0 {
+		t.Errorf("expected empty metadata, got %v %v %v", meta, ok, err)
+	}
+}
+
+func TestFindVenv(t *testing.T) {
+	cwd := t.TempDir()
+	root := t.TempDir()
+
+	if _, ok := FindVenv(cwd, root); ok {
+		t.Fatalf("expected no virtualenv")
+	}
+
+	makeVenv(t, filepath.Join(root, "venv"))
+	if venv, ok := FindVenv(cwd, root); !ok || venv != filepath.Join(root, "venv") {
+		t.Errorf("expected the virtualenv next to the runfile, got %q", venv)
+	}
+
+	makeVenv(t, filepath.Join(cwd, ".venv"))
+	if venv, ok := FindVenv(cwd, root); !ok || venv != filepath.Join(cwd, ".venv") {
+		t.Errorf("expected the virtualenv in the working directory, got %q", venv)
+	}
+
+	// a directory without pyvenv.cfg is not a virtualenv.
+	other := t.TempDir()
+	os.MkdirAll(filepath.Join(other, ".venv", "bin"), 0755)
+	if _, ok := FindVenv(other); ok {
+		t.Errorf("expected a directory without pyvenv.cfg to be skipped")
+	}
+}
+
+func TestInlineOptions(t *testing.T) {
+	c := &cache.Cache{Dir: t.TempDir()}
+	dir := t.TempDir()
+	makeVenv(t, filepath.Join(dir, ".venv"))
+	options := &Options{Dirs: []string{dir}, Cache: c}
+
+	cmd := InlineOptions("print(1)", options, "a")
+	expected := []string{VenvPython(filepath.Join(dir, ".venv")), "-c", "print(1)", "a"}
+	if !reflect.DeepEqual(cmd.Args, expected) {
+		t.Errorf("expected %v, got %v", expected, cmd.Args)
+	}
+
+	cmd = InlineOptions("import sys\nprint(sys.argv)\n", options, "a")
+	if cmd.Err != nil {
+		t.Fatalf("unexpected error: %v", cmd.Err)
+	}
+
+	if cmd.TempFile == nil || filepath.Dir(*cmd.TempFile) != c.Dir || !strings.HasSuffix(*cmd.TempFile, ".py") {
+		t.Fatalf("expected the script to be written to the cache, got %v", cmd.TempFile)
+	}
+
+	expected = []string{VenvPython(filepath.Join(dir, ".venv")), *cmd.TempFile, "a"}
+	if !reflect.DeepEqual(cmd.Args, expected) {
+		t.Errorf("expected %v, got %v", expected, cmd.Args)
+	}
+
+	cmd = InlineOptions("# /// script\n# dependencies = []\n# ///\nprint(1)\n# /// script\n# dependencies = []\n# ///\n", options)
+	if cmd.Err == nil {
+		t.Errorf("expected an error for invalid metadata")
+	}
+}
+
+func TestInlineOptions_Traceback(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not found")
+	}
+
+	t.Setenv("RUN_PYTHON_EXE", "python3")
+	options := &Options{Cache: &cache.Cache{Dir: t.TempDir()}}
+	cmd := InlineOptions("x = 1\n\nraise ValueError(x)\n", options)
+	out, _ := cmd.Output()
+	if !strings.Contains(string(out.Stderr), "line 3") {
+		t.Errorf("expected the traceback to point at line 3, got %q", out.Stderr)
+	}
+}
+
+func TestVenv(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+
+	if exec.Command(python, "-c", "import venv, ensurepip").Run() != nil {
+		t.Skip("python3 cannot create virtualenvs")
+	}
+
+	// the virtualenv without dependencies is created offline.
+	t.Setenv("RUN_PYTHON_EXE", python)
+	c := &cache.Cache{Dir: t.TempDir()}
+
+	script := "# /// script\n# dependencies = []\n# ///\nimport sys\nprint(sys.prefix != sys.base_prefix)\n"
+	cmd := InlineOptionsContext(context.Background(), script, &Options{Cache: c})
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run failed: %v %s", err, out.Stderr)
+	}
+
+	if strings.TrimSpace(string(out.Stdout)) != "True" {
+		t.Errorf("expected the script to run in a virtualenv, got %q", out.Stdout)
+	}
+
+	entries, _ := c.List()
+	venvs := 0
+	for _, entry := range entries {
+		if entry.IsDir && strings.HasPrefix(entry.Name, "py-") {
+			venvs++
+		}
+	}
+
+	if venvs != 1 {
+		t.Errorf("expected 1 cached virtualenv, got %d", venvs)
+	}
+}
+
+func makeVenv(t *testing.T, venv string) {
+	t.Helper()
+	python := VenvPython(venv)
+	if err := os.MkdirAll(filepath.Dir(python), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(venv, "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0644)
+	os.WriteFile(python, []byte{}, 0755)
+}
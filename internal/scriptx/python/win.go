@@ -6,11 +6,18 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("python", &exec.Executable{
-		Name:     "python",
-		Variable: "RUN_WIN_PYTHON_EXE",
+		Name: "python",
 		Windows: []string{
 			"${ProgramFiles}\\Python\\Python.exe",
 			"${ProgramFiles(x86)}\\Python\\Python.exe",
 		},
 	})
+
+	exec.Register("uv", &exec.Executable{
+		Name: "uv",
+		Windows: []string{
+			"${USERPROFILE}\\.local\\bin\\uv.exe",
+			"${USERPROFILE}\\.cargo\\bin\\uv.exe",
+		},
+	})
 }
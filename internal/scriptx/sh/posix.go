@@ -1,13 +1,12 @@
 //go:build !windows
 
-package sh	
+package sh
 
 import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("sh", &exec.Executable{
-		Name:     "sh",
-		Variable: "RUN_SH_EXE",
+		Name: "sh",
 		Linux: []string{
 			"/bin/sh",
 			"/usr/bin/sh",
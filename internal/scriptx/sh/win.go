@@ -6,8 +6,7 @@ import "github.com/hyprxlabs/run/internal/exec"
 
 func init() {
 	exec.Register("sh", &exec.Executable{
-		Name:     "sh",
-		Variable: "RUN_WIN_SH_EXE",
+		Name: "sh",
 		Windows: []string{
 			"${ProgramFiles}\\Git\\bin\\sh.exe",
 			"${ProgramFiles(x86)}\\Git\\bin\\sh.exe",
@@ -0,0 +1,205 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type builtin func(s *Shell, st *stage) int
+
+var builtins map[string]builtin
+
+// pure lists the builtins that only write output and may therefore
+// start a pipeline.
+var pure = map[string]bool{
+	"echo":  true,
+	"pwd":   true,
+	"true":  true,
+	"false": true,
+	":":     true,
+}
+
+func init() {
+	builtins = map[string]builtin{
+		"cd":     cd,
+		"echo":   echo,
+		"exit":   exit,
+		"export": export,
+		"false":  func(*Shell, *stage) int { return 1 },
+		"pwd":    pwd,
+		"set":    set,
+		"true":   func(*Shell, *stage) int { return 0 },
+		"unset":  unset,
+		":":      func(*Shell, *stage) int { return 0 },
+	}
+}
+
+func (st *stage) errorf(format string, args ...any) int {
+	fmt.Fprintf(st.stderr, "%s: line %d: %s\n", Name, st.cmd.line, fmt.Sprintf(format, args...))
+	return 1
+}
+
+func cd(s *Shell, st *stage) int {
+	dir := ""
+	switch len(st.args) {
+	case 1:
+		dir = s.tilde("~")
+		if dir == "~" {
+			return st.errorf("cd: HOME not set")
+		}
+	case 2:
+		dir = st.args[1]
+		if dir == "-" {
+			dir = s.Env["OLDPWD"]
+			if dir == "" {
+				return st.errorf("cd: OLDPWD not set")
+			}
+		}
+	default:
+		return st.errorf("cd: too many arguments")
+	}
+
+	dir = s.abs(dir)
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return st.errorf("cd: %s: %v", st.args[len(st.args)-1], unwrapPathError(err))
+	}
+
+	if !fi.IsDir() {
+		return st.errorf("cd: %s: not a directory", st.args[len(st.args)-1])
+	}
+
+	s.Env["OLDPWD"] = s.Dir
+	s.Env["PWD"] = dir
+	s.Dir = filepath.Clean(dir)
+	return 0
+}
+
+func echo(s *Shell, st *stage) int {
+	args := st.args[1:]
+	newline := true
+	if len(args) > 0 && args[0] == "-n" {
+		newline = false
+		args = args[1:]
+	}
+
+	out := strings.Join(args, " ")
+	if newline {
+		out += "\n"
+	}
+
+	if st.stdout != nil {
+		fmt.Fprint(st.stdout, out)
+	}
+
+	return 0
+}
+
+func exit(s *Shell, st *stage) int {
+	code := s.status
+	if len(st.args) > 1 {
+		n, err := strconv.Atoi(st.args[1])
+		if err != nil {
+			st.errorf("exit: %s: numeric argument required", st.args[1])
+			n = 2
+		}
+		code = n
+	}
+
+	s.exited = true
+	return code
+}
+
+// export sets variables for the commands that follow. Every variable
+// of the shell is exported, so 'export NAME' without a value only
+// makes sure the variable exists.
+func export(s *Shell, st *stage) int {
+	for k, v := range st.environ {
+		s.Env[k] = v
+	}
+
+	for _, arg := range st.args[1:] {
+		name, value, ok := strings.Cut(arg, "=")
+		if !validName(name) {
+			return st.errorf("export: '%s': not a valid identifier", arg)
+		}
+
+		if ok {
+			s.Env[name] = value
+		} else if _, set := s.Env[name]; !set {
+			s.Env[name] = ""
+		}
+	}
+
+	return 0
+}
+
+func pwd(s *Shell, st *stage) int {
+	if st.stdout != nil {
+		fmt.Fprintln(st.stdout, s.Dir)
+	}
+
+	return 0
+}
+
+// set supports the options that change how failures are handled:
+// -e, +e, -o errexit and -o pipefail.
+func set(s *Shell, st *stage) int {
+	args := st.args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		on := strings.HasPrefix(arg, "-")
+		if !on && !strings.HasPrefix(arg, "+") {
+			return st.errorf("set: %s: invalid option", arg)
+		}
+
+		for _, c := range arg[1:] {
+			switch c {
+			case 'e':
+				s.ErrExit = on
+			case 'o':
+				if i+1 >= len(args) {
+					return st.errorf("set: %s: option requires an argument", arg)
+				}
+				i++
+				switch args[i] {
+				case "errexit":
+					s.ErrExit = on
+				case "pipefail":
+					s.Pipefail = on
+				default:
+					return st.errorf("set: %s: invalid option name", args[i])
+				}
+			default:
+				return st.errorf("set: %s: invalid option", arg)
+			}
+		}
+	}
+
+	return 0
+}
+
+func unset(s *Shell, st *stage) int {
+	for _, name := range st.args[1:] {
+		delete(s.Env, name)
+	}
+
+	return 0
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,301 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/env"
+	"github.com/hyprxlabs/run/internal/globs"
+)
+
+// expandWord expands the variables of a word and, when unquoted
+// parts contain glob characters, the matching paths. A glob without
+// matches is kept as is. Words are not split on blanks, except for
+// "$@", which expands to one field per positional argument.
+func (s *Shell) expandWord(ctx context.Context, w word) ([]string, error) {
+	if len(w) == 1 && w[0].quote != singleQuoted && w[0].text == "$@" {
+		return append([]string{}, s.Args...), nil
+	}
+
+	value := strings.Builder{}
+	pattern := strings.Builder{}
+	glob := false
+	for i, p := range w {
+		text := p.text
+		if p.quote != singleQuoted {
+			expanded, err := s.expand(ctx, text)
+			if err != nil {
+				return nil, err
+			}
+
+			if i == 0 && p.quote == unquoted {
+				expanded = s.tilde(expanded)
+			}
+			text = expanded
+		}
+
+		value.WriteString(text)
+		if p.quote == unquoted && globs.HasMeta(text) {
+			glob = true
+			pattern.WriteString(text)
+		} else {
+			pattern.WriteString(escapeGlob(text))
+		}
+	}
+
+	if glob {
+		if matches := s.glob(pattern.String()); len(matches) > 0 {
+			return matches, nil
+		}
+	}
+
+	return []string{value.String()}, nil
+}
+
+// expandString expands a word that is never split or globbed, such
+// as the value of an assignment or the target of a redirect.
+func (s *Shell) expandString(ctx context.Context, w word) (string, error) {
+	sb := strings.Builder{}
+	for i, p := range w {
+		if p.quote == singleQuoted {
+			sb.WriteString(p.text)
+			continue
+		}
+
+		expanded, err := s.expand(ctx, p.text)
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 && p.quote == unquoted {
+			expanded = s.tilde(expanded)
+		}
+		sb.WriteString(expanded)
+	}
+
+	return sb.String(), nil
+}
+
+// expand replaces the special parameters, then the variables and
+// command substitutions of the text.
+func (s *Shell) expand(ctx context.Context, text string) (string, error) {
+	if !strings.ContainsRune(text, '$') {
+		return text, nil
+	}
+
+	text = s.specials(text)
+	o := &env.ExpandOptions{
+		Lookup: func(key string) (string, bool) {
+			value, ok := s.Env[key]
+			return value, ok
+		},
+		Set: func(key, value string) error {
+			s.Env[key] = value
+			return nil
+		},
+		CommandSubstitution: true,
+		Command: func(expression string) (string, error) {
+			return s.substitute(ctx, expression)
+		},
+	}
+	o.Get = func(key string) string {
+		return s.Env[key]
+	}
+
+	return env.ExpandWithOptions(text, o)
+}
+
+// substitute runs the script of a command substitution in a copy of
+// the shell and returns its output without trailing newlines.
+// Changes to the directory and variables do not leak out.
+func (s *Shell) substitute(ctx context.Context, script string) (string, error) {
+	var out bytes.Buffer
+	sub := *s
+	sub.Env = maps.Clone(s.Env)
+	sub.Stdout = &out
+
+	code, err := sub.Run(ctx, script)
+	if err != nil {
+		return "", err
+	}
+
+	if code != 0 {
+		return "", fmt.Errorf("command substitution '%s' failed with exit status %d", script, code)
+	}
+
+	return strings.TrimRight(out.String(), "\n"), nil
+}
+
+// specials replaces the positional parameters $0-$9 and ${N}, and
+// $@, $*, $# and $?, which the env package does not know about. The
+// values are escaped so they are not expanded again.
+func (s *Shell) specials(text string) string {
+	runes := []rune(text)
+	sb := strings.Builder{}
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if c != '$' || i+1 >= len(runes) {
+			sb.WriteRune(c)
+			continue
+		}
+
+		next := runes[i+1]
+		value, n, ok := "", 0, true
+		switch {
+		case next == '$':
+			sb.WriteString("$$")
+			i++
+			continue
+		case next >= '0' && next <= '9':
+			value, n = s.arg(int(next-'0')), 1
+		case next == '@' || next == '*':
+			value, n = strings.Join(s.Args, " "), 1
+		case next == '#':
+			value, n = strconv.Itoa(len(s.Args)), 1
+		case next == '?':
+			value, n = strconv.Itoa(s.status), 1
+		case next == '{':
+			end := i + 2
+			for end < len(runes) && runes[end] >= '0' && runes[end] <= '9' {
+				end++
+			}
+			if end > i+2 && end < len(runes) && runes[end] == '}' {
+				index, _ := strconv.Atoi(string(runes[i+2 : end]))
+				value, n = s.arg(index), end-i
+			} else {
+				ok = false
+			}
+		default:
+			ok = false
+		}
+
+		if !ok {
+			sb.WriteRune(c)
+			continue
+		}
+
+		sb.WriteString(strings.ReplaceAll(value, "$", "\\$"))
+		i += n
+	}
+
+	return sb.String()
+}
+
+func (s *Shell) arg(i int) string {
+	if i == 0 {
+		return Name
+	}
+
+	if i <= len(s.Args) {
+		return s.Args[i-1]
+	}
+
+	return ""
+}
+
+// tilde replaces a leading ~ with the home directory.
+func (s *Shell) tilde(text string) string {
+	if text != "~" && !strings.HasPrefix(text, "~/") {
+		return text
+	}
+
+	home := s.Env["HOME"]
+	if home == "" && runtime.GOOS == "windows" {
+		home = s.Env["USERPROFILE"]
+	}
+
+	if home == "" {
+		return text
+	}
+
+	return home + text[1:]
+}
+
+// glob returns the sorted paths matching the pattern. Relative
+// patterns match relative to the shell directory and return relative
+// paths.
+func (s *Shell) glob(pattern string) []string {
+	if filepath.IsAbs(pattern) {
+		matches, _ := filepath.Glob(pattern)
+		return matches
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(s.Dir, pattern))
+	for i, m := range matches {
+		if rel, err := filepath.Rel(s.Dir, m); err == nil {
+			matches[i] = rel
+		}
+	}
+
+	return matches
+}
+
+// escapeGlob quotes the glob characters of text with brackets,
+// which works on every platform unlike backslashes.
+func escapeGlob(text string) string {
+	if !globs.HasMeta(text) {
+		return text
+	}
+
+	sb := strings.Builder{}
+	for _, c := range text {
+		switch c {
+		case '*', '?', '[':
+			sb.WriteRune('[')
+			sb.WriteRune(c)
+			sb.WriteRune(']')
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// abs resolves a path against the shell directory.
+func (s *Shell) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(s.Dir, path)
+}
+
+// lookPath finds an executable the way a shell does: names with a
+// path separator are resolved against the shell directory and other
+// names are searched in the PATH of the shell.
+func (s *Shell) lookPath(name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		path, err := exec.LookPath(s.abs(name))
+		return path, err == nil
+	}
+
+	path := s.Env["PATH"]
+	if path == "" && runtime.GOOS == "windows" {
+		path = s.Env["Path"]
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+
+		found, err := exec.LookPath(filepath.Join(s.abs(dir), name))
+		if err == nil {
+			return found, true
+		}
+	}
+
+	return "", false
+}
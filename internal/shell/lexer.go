@@ -0,0 +1,343 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPipe
+	tokAnd
+	tokOr
+	tokSemi
+	tokNewline
+	tokRedirect
+	tokEOF
+)
+
+type quote int
+
+const (
+	unquoted quote = iota
+	singleQuoted
+	doubleQuoted
+)
+
+// part is a piece of a word with the quoting it was written with.
+// Escaped characters are stored as single quoted parts.
+type part struct {
+	text  string
+	quote quote
+}
+
+// word is a command argument made of quoted and unquoted parts,
+// e.g. foo"$BAR"'baz'.
+type word []part
+
+// literal returns the word without its quotes and unexpanded.
+func (w word) literal() string {
+	sb := strings.Builder{}
+	for _, p := range w {
+		sb.WriteString(p.text)
+	}
+
+	return sb.String()
+}
+
+func (w word) quoted() bool {
+	for _, p := range w {
+		if p.quote != unquoted {
+			return true
+		}
+	}
+
+	return false
+}
+
+type token struct {
+	kind tokenKind
+	word word
+	op   string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokWord:
+		return t.word.literal()
+	case tokNewline:
+		return "newline"
+	case tokEOF:
+		return "end of script"
+	default:
+		return t.op
+	}
+}
+
+// SyntaxError is returned for scripts that cannot be parsed.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error on line %d: %s", e.Line, e.Msg)
+}
+
+// redirects lists the redirect operators, longest first.
+var redirects = []string{"&>>", "2>&1", "1>&2", "2>>", "1>>", "&>", ">&2", ">>", "2>", "1>", "0<", ">", "<"}
+
+type lexer struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func lex(script string) ([]token, error) {
+	l := &lexer{src: []rune(script), line: 1}
+	tokens := []token{}
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+		if t.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) peek(offset int) rune {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+
+	return 0
+}
+
+func (l *lexer) hasPrefix(s string) bool {
+	for i, r := range s {
+		if l.peek(i) != r {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\r' {
+			l.pos++
+			continue
+		}
+
+		// line continuation
+		if c == '\\' && l.peek(1) == '\n' {
+			l.pos += 2
+			l.line++
+			continue
+		}
+
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+
+		break
+	}
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, line: l.line}, nil
+	}
+
+	line := l.line
+	switch {
+	case l.hasPrefix("\n"):
+		l.pos++
+		l.line++
+		return token{kind: tokNewline, op: "\n", line: line}, nil
+	case l.hasPrefix("&&"):
+		l.pos += 2
+		return token{kind: tokAnd, op: "&&", line: line}, nil
+	case l.hasPrefix("||"):
+		l.pos += 2
+		return token{kind: tokOr, op: "||", line: line}, nil
+	case l.hasPrefix("|"):
+		l.pos++
+		return token{kind: tokPipe, op: "|", line: line}, nil
+	case l.hasPrefix(";"):
+		l.pos++
+		return token{kind: tokSemi, op: ";", line: line}, nil
+	}
+
+	for _, op := range redirects {
+		if l.hasPrefix(op) {
+			l.pos += len([]rune(op))
+			return token{kind: tokRedirect, op: op, line: line}, nil
+		}
+	}
+
+	switch l.src[l.pos] {
+	case '&':
+		return token{}, &SyntaxError{Line: line, Msg: "background jobs ('&') are not supported"}
+	case '(', ')':
+		return token{}, &SyntaxError{Line: line, Msg: "subshells are not supported"}
+	}
+
+	return l.word()
+}
+
+// word reads a word up to the next unquoted blank or operator.
+func (l *lexer) word() (token, error) {
+	line := l.line
+	w := word{}
+	buf := strings.Builder{}
+	flush := func(q quote) {
+		if buf.Len() > 0 || q != unquoted {
+			w = append(w, part{text: buf.String(), quote: q})
+		}
+		buf.Reset()
+	}
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if strings.ContainsRune(" \t\r\n|&;<>()", c) {
+			break
+		}
+
+		switch c {
+		case '\\':
+			if l.peek(1) == '\n' {
+				l.pos += 2
+				l.line++
+				continue
+			}
+			flush(unquoted)
+			l.pos++
+			if l.pos < len(l.src) {
+				w = append(w, part{text: string(l.src[l.pos]), quote: singleQuoted})
+				l.pos++
+			}
+
+		case '\'':
+			flush(unquoted)
+			l.pos++
+			start := l.pos
+			for l.pos < len(l.src) && l.src[l.pos] != '\'' {
+				if l.src[l.pos] == '\n' {
+					l.line++
+				}
+				l.pos++
+			}
+			if l.pos >= len(l.src) {
+				return token{}, &SyntaxError{Line: line, Msg: "unterminated single quote"}
+			}
+			buf.WriteString(string(l.src[start:l.pos]))
+			l.pos++
+			flush(singleQuoted)
+
+		case '"':
+			flush(unquoted)
+			l.pos++
+			closed := false
+			for l.pos < len(l.src) {
+				c := l.src[l.pos]
+				if c == '"' {
+					closed = true
+					l.pos++
+					break
+				}
+
+				if c == '\\' && strings.ContainsRune("\"\\$`\n", l.peek(1)) {
+					if l.peek(1) == '\n' {
+						l.pos += 2
+						l.line++
+						continue
+					}
+
+					if buf.Len() > 0 {
+						flush(doubleQuoted)
+					}
+					w = append(w, part{text: string(l.peek(1)), quote: singleQuoted})
+					l.pos += 2
+					continue
+				}
+
+				if c == '$' && (l.peek(1) == '{' || l.peek(1) == '(') {
+					if err := l.expansion(&buf); err != nil {
+						return token{}, err
+					}
+					continue
+				}
+
+				if c == '\n' {
+					l.line++
+				}
+				buf.WriteRune(c)
+				l.pos++
+			}
+			if !closed {
+				return token{}, &SyntaxError{Line: line, Msg: "unterminated double quote"}
+			}
+			flush(doubleQuoted)
+
+		case '$':
+			if l.peek(1) == '{' || l.peek(1) == '(' {
+				if err := l.expansion(&buf); err != nil {
+					return token{}, err
+				}
+				continue
+			}
+			buf.WriteRune(c)
+			l.pos++
+
+		default:
+			buf.WriteRune(c)
+			l.pos++
+		}
+	}
+
+	flush(unquoted)
+	return token{kind: tokWord, word: w, line: line}, nil
+}
+
+// expansion copies a ${...} or $(...) expression, which may contain
+// blanks, operators and nested expressions, into buf.
+func (l *lexer) expansion(buf *strings.Builder) error {
+	line := l.line
+	open := l.src[l.pos+1]
+	close := '}'
+	if open == '(' {
+		close = ')'
+	}
+
+	depth := 0
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		buf.WriteRune(c)
+		l.pos++
+
+		switch c {
+		case '\n':
+			l.line++
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+
+	return &SyntaxError{Line: line, Msg: fmt.Sprintf("missing '%c'", close)}
+}
@@ -0,0 +1,209 @@
+package shell
+
+import "fmt"
+
+// list is a sequence of and-or lists separated by ';' or newlines.
+type list []*andOr
+
+// andOr is a pipeline followed by pipelines that only run when the
+// previous one succeeded ('&&') or failed ('||').
+type andOr struct {
+	first *pipeline
+	rest  []andOrItem
+}
+
+type andOrItem struct {
+	op       string
+	pipeline *pipeline
+}
+
+type pipeline struct {
+	cmds []*command
+	line int
+}
+
+// command is a simple command: leading assignments, the arguments
+// and the redirects in the order they were written.
+type command struct {
+	assigns   []assign
+	args      []word
+	redirects []redirect
+	line      int
+}
+
+type assign struct {
+	name  string
+	value word
+}
+
+type redirect struct {
+	op     string
+	target word
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(script string) (list, error) {
+	tokens, err := lex(script)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.list()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) unexpected(t token) error {
+	return &SyntaxError{Line: t.line, Msg: fmt.Sprintf("unexpected '%s'", t)}
+}
+
+func (p *parser) list() (list, error) {
+	l := list{}
+	for {
+		for p.peek().kind == tokNewline || p.peek().kind == tokSemi {
+			p.advance()
+		}
+
+		if p.peek().kind == tokEOF {
+			return l, nil
+		}
+
+		ao, err := p.andOr()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, ao)
+
+		switch t := p.peek(); t.kind {
+		case tokNewline, tokSemi, tokEOF:
+		default:
+			return nil, p.unexpected(t)
+		}
+	}
+}
+
+func (p *parser) andOr() (*andOr, error) {
+	first, err := p.pipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	ao := &andOr{first: first}
+	for p.peek().kind == tokAnd || p.peek().kind == tokOr {
+		op := p.advance().op
+
+		// like sh, a newline may follow the operator.
+		for p.peek().kind == tokNewline {
+			p.advance()
+		}
+
+		next, err := p.pipeline()
+		if err != nil {
+			return nil, err
+		}
+		ao.rest = append(ao.rest, andOrItem{op: op, pipeline: next})
+	}
+
+	return ao, nil
+}
+
+func (p *parser) pipeline() (*pipeline, error) {
+	pl := &pipeline{line: p.peek().line}
+	for {
+		cmd, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		pl.cmds = append(pl.cmds, cmd)
+
+		if p.peek().kind != tokPipe {
+			return pl, nil
+		}
+		p.advance()
+		for p.peek().kind == tokNewline {
+			p.advance()
+		}
+	}
+}
+
+func (p *parser) command() (*command, error) {
+	cmd := &command{line: p.peek().line}
+	for {
+		t := p.peek()
+		switch t.kind {
+		case tokWord:
+			p.advance()
+			if len(cmd.args) == 0 {
+				if name, value, ok := assignment(t.word); ok {
+					cmd.assigns = append(cmd.assigns, assign{name: name, value: value})
+					continue
+				}
+			}
+			cmd.args = append(cmd.args, t.word)
+
+		case tokRedirect:
+			p.advance()
+			r := redirect{op: t.op}
+			if t.op != "2>&1" && t.op != "1>&2" && t.op != ">&2" {
+				target := p.advance()
+				if target.kind != tokWord {
+					return nil, &SyntaxError{Line: target.line, Msg: fmt.Sprintf("expected a file name after '%s', found '%s'", t.op, target)}
+				}
+				r.target = target.word
+			}
+			cmd.redirects = append(cmd.redirects, r)
+
+		default:
+			if len(cmd.args) == 0 && len(cmd.assigns) == 0 && len(cmd.redirects) == 0 {
+				return nil, p.unexpected(t)
+			}
+			return cmd, nil
+		}
+	}
+}
+
+// assignment splits a word of the form NAME=value. The name must be
+// unquoted.
+func assignment(w word) (string, word, bool) {
+	if len(w) == 0 || w[0].quote != unquoted {
+		return "", nil, false
+	}
+
+	text := w[0].text
+	for i, c := range text {
+		if c == '=' {
+			if i == 0 {
+				return "", nil, false
+			}
+
+			value := word{}
+			if rest := text[i+1:]; rest != "" {
+				value = append(value, part{text: rest, quote: unquoted})
+			}
+			value = append(value, w[1:]...)
+			return text[:i], value, true
+		}
+
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return "", nil, false
+		}
+	}
+
+	return "", nil, false
+}
@@ -0,0 +1,389 @@
+// Package shell implements run-shell, a small interpreter for a
+// portable subset of the POSIX shell language. It runs simple
+// commands, pipelines, redirects, '&&' and '||' lists, variable
+// assignments and globs without a system shell, so tasks behave the
+// same way on every platform.
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/env"
+	"github.com/hyprxlabs/run/internal/exec"
+)
+
+// Name is the runtime name that selects the interpreter through
+// 'uses'.
+const Name = "run-shell"
+
+// Shell holds the state of a script run: the working directory, the
+// variables and the positional arguments. Builtins such as cd and
+// export change the state for the commands that follow.
+type Shell struct {
+	// Dir is the working directory.
+	Dir string
+
+	// Env holds the variables, which are all exported to commands.
+	Env map[string]string
+
+	// Args are the positional arguments $1, $2, ...
+	Args []string
+
+	// ErrExit stops the script at the first command that fails,
+	// like 'set -e'. It is enabled by default.
+	ErrExit bool
+
+	// Pipefail makes a pipeline fail when any stage fails, like
+	// 'set -o pipefail'.
+	Pipefail bool
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	status int
+	exited bool
+}
+
+// New creates a shell in the current directory with the process
+// environment and stdio.
+func New() *Shell {
+	dir, _ := os.Getwd()
+	return &Shell{
+		Dir:     dir,
+		Env:     env.All(),
+		ErrExit: true,
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
+}
+
+// Run parses and runs the script. It returns the exit status of the
+// script. The error is set when the script cannot be parsed or the
+// context is done.
+func (s *Shell) Run(ctx context.Context, script string) (int, error) {
+	l, err := parse(script)
+	if err != nil {
+		return 2, err
+	}
+
+	s.exited = false
+	for _, ao := range l {
+		failed, err := s.andOr(ctx, ao)
+		if err != nil {
+			return s.status, err
+		}
+
+		if s.exited || (failed && s.ErrExit) {
+			return s.status, nil
+		}
+	}
+
+	return s.status, nil
+}
+
+// andOr runs the pipelines of the list. It reports whether the list
+// failed in a way that stops the script under errexit: only the
+// last pipeline of a list can do that.
+func (s *Shell) andOr(ctx context.Context, ao *andOr) (bool, error) {
+	if err := s.pipeline(ctx, ao.first); err != nil {
+		return false, err
+	}
+
+	ran := 0
+	for i, item := range ao.rest {
+		if s.exited {
+			return false, nil
+		}
+
+		if (item.op == "&&") != (s.status == 0) {
+			continue
+		}
+
+		if err := s.pipeline(ctx, item.pipeline); err != nil {
+			return false, err
+		}
+		ran = i + 1
+	}
+
+	return s.status != 0 && ran == len(ao.rest), nil
+}
+
+// stage is a command of a pipeline with its arguments expanded and
+// its redirects applied.
+type stage struct {
+	cmd     *command
+	args    []string
+	environ map[string]string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	merged  bool
+	files   []*os.File
+}
+
+func (st *stage) close() {
+	for _, f := range st.files {
+		f.Close()
+	}
+}
+
+func (s *Shell) pipeline(ctx context.Context, pl *pipeline) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stages := make([]*stage, 0, len(pl.cmds))
+	defer func() {
+		for _, st := range stages {
+			st.close()
+		}
+	}()
+
+	for i, cmd := range pl.cmds {
+		st, err := s.prepare(ctx, cmd, i == 0, i == len(pl.cmds)-1)
+		if st != nil {
+			stages = append(stages, st)
+		}
+		if err != nil {
+			s.errorf(cmd.line, "%v", err)
+			s.status = 1
+			return nil
+		}
+	}
+
+	if len(stages) == 1 {
+		st := stages[0]
+		if len(st.args) == 0 {
+			// assignments without a command set shell variables.
+			for k, v := range st.environ {
+				s.Env[k] = v
+			}
+			s.status = 0
+			return nil
+		}
+
+		if f, ok := builtins[st.args[0]]; ok {
+			s.status = f(s, st)
+			return nil
+		}
+	}
+
+	// a builtin may produce the input of the pipeline; any other
+	// use needs the state of this shell in another process.
+	var input io.Reader
+	inputCode := 0
+	if f, ok := builtins[stages[0].args0()]; ok && pure[stages[0].args0()] {
+		st := stages[0]
+		var buf bytes.Buffer
+		if st.stdout == nil {
+			st.stdout = &buf
+			if st.merged {
+				st.stderr = &buf
+			}
+		}
+		inputCode = f(s, st)
+		input = &buf
+		stages = stages[1:]
+	}
+
+	cmds := make([]*exec.Cmd, 0, len(stages))
+	p := exec.NewPipeline().WithContext(ctx).WithPipefail(s.Pipefail)
+	for i, st := range stages {
+		name := st.args0()
+		if _, ok := builtins[name]; ok {
+			s.errorf(st.cmd.line, "%s: builtin cannot be used in a pipeline", name)
+			s.status = 1
+			return nil
+		}
+
+		path, ok := s.lookPath(name)
+		if !ok {
+			s.errorf(st.cmd.line, "%s: command not found", name)
+			s.status = 127
+			return nil
+		}
+
+		cmd := exec.NewContext(ctx, path, st.args[1:]...)
+		cmd.Args[0] = name
+		cmd.Dir = s.Dir
+		cmd.Env = s.environ(st.environ)
+		cmd.Stderr = st.stderr
+		cmds = append(cmds, cmd)
+
+		// redirects of the stages in the middle replace the pipes.
+		if i > 0 && st.stdin != nil {
+			cmd.Stdin = st.stdin
+		}
+		if i < len(stages)-1 && st.stdout != nil {
+			cmd.Stdout = st.stdout
+		}
+
+		if i == 0 {
+			if input != nil {
+				p.WithStdin(input)
+			} else {
+				p.WithStdin(st.stdin)
+			}
+		}
+
+		if st.merged {
+			p.MergeStderr(i)
+		}
+	}
+
+	last := stages[len(stages)-1]
+	res, err := p.Pipe(cmds...).Stream(last.stdout)
+	if res == nil {
+		return err
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	var startErr *exec.StartError
+	if errors.As(err, &startErr) {
+		s.errorf(last.cmd.line, "%v", startErr)
+		s.status = startErr.ExitCode()
+		return nil
+	}
+
+	s.status = res.Code
+	if s.Pipefail && res.Code == 0 {
+		s.status = inputCode
+	}
+
+	return nil
+}
+
+func (st *stage) args0() string {
+	if len(st.args) == 0 {
+		return ""
+	}
+
+	return st.args[0]
+}
+
+// prepare expands the command and opens its redirects. The first
+// stage reads the shell's stdin and the last stage writes to the
+// shell's stdout; the other ends are connected by the pipeline
+// unless a redirect replaces them.
+func (s *Shell) prepare(ctx context.Context, cmd *command, first, last bool) (*stage, error) {
+	st := &stage{cmd: cmd, environ: map[string]string{}, stderr: s.Stderr}
+	if first {
+		st.stdin = s.Stdin
+	}
+	if last {
+		st.stdout = s.Stdout
+	}
+
+	for _, a := range cmd.assigns {
+		value, err := s.expandString(ctx, a.value)
+		if err != nil {
+			return st, err
+		}
+		st.environ[a.name] = value
+	}
+
+	for _, w := range cmd.args {
+		fields, err := s.expandWord(ctx, w)
+		if err != nil {
+			return st, err
+		}
+		st.args = append(st.args, fields...)
+	}
+
+	for _, r := range cmd.redirects {
+		switch r.op {
+		case "2>&1":
+			st.stderr = st.stdout
+			st.merged = st.stdout == nil
+			continue
+		case "1>&2", ">&2":
+			st.stdout = st.stderr
+			continue
+		}
+
+		target, err := s.expandString(ctx, r.target)
+		if err != nil {
+			return st, err
+		}
+
+		if target == "" {
+			return st, errors.New("ambiguous redirect")
+		}
+
+		if r.op == "<" || r.op == "0<" {
+			f, err := os.Open(s.abs(target))
+			if err != nil {
+				return st, fmt.Errorf("%s: %w", target, unwrapPathError(err))
+			}
+			st.files = append(st.files, f)
+			st.stdin = f
+			continue
+		}
+
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if strings.HasSuffix(r.op, ">>") {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+
+		f, err := os.OpenFile(s.abs(target), flags, 0o644)
+		if err != nil {
+			return st, fmt.Errorf("%s: %w", target, unwrapPathError(err))
+		}
+		st.files = append(st.files, f)
+
+		switch r.op {
+		case "2>", "2>>":
+			st.stderr = f
+			st.merged = false
+		case "&>", "&>>":
+			st.stdout = f
+			st.stderr = f
+			st.merged = false
+		default:
+			st.stdout = f
+		}
+	}
+
+	return st, nil
+}
+
+// environ returns the variables of the shell and the command's own
+// assignments in the KEY=value form.
+func (s *Shell) environ(extra map[string]string) []string {
+	environ := make([]string, 0, len(s.Env)+len(extra))
+	for k, v := range s.Env {
+		if _, ok := extra[k]; ok {
+			continue
+		}
+		environ = append(environ, k+"="+v)
+	}
+
+	for k, v := range extra {
+		environ = append(environ, k+"="+v)
+	}
+
+	return environ
+}
+
+func (s *Shell) errorf(line int, format string, args ...any) {
+	fmt.Fprintf(s.Stderr, "%s: line %d: %s\n", Name, line, fmt.Sprintf(format, args...))
+}
+
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+
+	return err
+}
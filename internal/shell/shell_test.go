@@ -0,0 +1,195 @@
+package shell_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/shell"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func newShell(t *testing.T, out io.Writer) *shell.Shell {
+	dir := t.TempDir()
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := shell.New()
+	s.Dir = dir
+	s.Env = map[string]string{
+		"PATH": os.Getenv("PATH"),
+		"HOME": dir,
+		"PWD":  dir,
+	}
+	s.Stdin = strings.NewReader("")
+	s.Stdout = out
+	s.Stderr = out
+	return s
+}
+
+// TestGolden runs each testdata/*.sh script in a temporary directory
+// and compares its output and exit status with the .golden file.
+func TestGolden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the golden scripts use unix tools")
+	}
+
+	scripts, err := filepath.Glob(filepath.Join("testdata", "*.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, script := range scripts {
+		name := strings.TrimSuffix(filepath.Base(script), ".sh")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := &lockedBuffer{}
+			s := newShell(t, out)
+			code, err := s.Run(context.Background(), string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := fmt.Sprintf("%s[exit %d]\n", out.buf.String(), code)
+			golden := strings.TrimSuffix(script, ".sh") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestRun_Args(t *testing.T) {
+	out := &lockedBuffer{}
+	s := newShell(t, out)
+	s.Args = []string{"one", "two words", "$HOME"}
+
+	code, err := s.Run(context.Background(), `echo $# $1 "$2" ${3} "$0"
+printf '<%s>' "$@"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "3 one two words $HOME run-shell\n<one><two words><$HOME>", out.buf.String())
+}
+
+func TestRun_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{"echo 'open", "syntax error on line 1: unterminated single quote"},
+		{"echo ok\necho \"open", "syntax error on line 2: unterminated double quote"},
+		{"sleep 1 &", "syntax error on line 1: background jobs ('&') are not supported"},
+		{"| cat", "syntax error on line 1: unexpected '|'"},
+		{"echo ok &&", "syntax error on line 1: unexpected 'end of script'"},
+		{"echo >", "syntax error on line 1: expected a file name after '>', found 'end of script'"},
+		{"echo ${HOME", "syntax error on line 1: missing '}'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			out := &lockedBuffer{}
+			s := newShell(t, out)
+			code, err := s.Run(context.Background(), tt.script)
+			assert.Equal(t, 2, code)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.want, err.Error())
+			}
+			assert.Empty(t, out.buf.String())
+		})
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	out := &lockedBuffer{}
+	s := newShell(t, out)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.Run(ctx, "sleep 10 | cat\necho unreachable")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.NotContains(t, out.buf.String(), "unreachable")
+}
+
+// TestRun_SharedWriter checks that a command whose stdout and stderr
+// end up in the same plain buffer, as with 2>&1, keeps both streams.
+// Run it with -race.
+func TestRun_SharedWriter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	tests := []string{
+		"sh -c 'for i in 1 2 3; do echo out$i; echo err$i >&2; done' 2>&1",
+		"sh -c 'for i in 1 2 3; do echo out$i; echo err$i >&2; done'",
+		"sh -c 'for i in 1 2 3; do echo err$i >&2; done' | sh -c 'cat; for i in 1 2 3; do echo out$i; done' 2>&1",
+	}
+
+	for _, script := range tests {
+		t.Run(script, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			s := newShell(t, out)
+
+			code, err := s.Run(context.Background(), script)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, code)
+			for i := 1; i <= 3; i++ {
+				assert.Contains(t, out.String(), fmt.Sprintf("out%d\n", i))
+				assert.Contains(t, out.String(), fmt.Sprintf("err%d\n", i))
+			}
+		})
+	}
+}
+
+func TestRun_StateCarriesOver(t *testing.T) {
+	out := &lockedBuffer{}
+	s := newShell(t, out)
+	dir := s.Dir
+
+	_, err := s.Run(context.Background(), "mkdir sub && cd sub\nexport ANSWER=42")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "sub"), s.Dir)
+	assert.Equal(t, "42", s.Env["ANSWER"])
+}
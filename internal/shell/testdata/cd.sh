@@ -0,0 +1,8 @@
+mkdir -p sub/dir
+cd sub
+echo ${PWD##*/}
+cd dir && touch made-here.txt
+cd -
+echo ${PWD##*/}
+ls dir
+cd missing || echo "cd failed"
@@ -0,0 +1,7 @@
+true && echo and
+false || echo or
+false && echo never || echo fallback
+echo a | tr a b > out.txt 2>&1 && cat out.txt || echo unreachable
+false | cat && echo "last stage decides"
+set -o pipefail
+false | cat || echo "pipefail"
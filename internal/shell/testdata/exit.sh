@@ -0,0 +1,7 @@
+set +e
+false
+echo "status $?"
+missing-command-xyz
+echo "status $?"
+exit 3
+echo unreachable
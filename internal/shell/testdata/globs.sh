@@ -0,0 +1,8 @@
+touch a.txt b.txt c.log
+echo *.txt
+echo "*.txt" '*.txt'
+echo *.none
+echo ?.log [ab].txt
+mkdir -p dir
+touch dir/x.go dir/y.go
+echo dir/*.go
@@ -0,0 +1,13 @@
+# stages run at once and stream into each other
+echo hello world | tr a-z A-Z
+printf 'b\na\nc\n' | sort | head -n 2
+echo counted | wc -l | tr -d ' '
+cat missing.txt 2>&1 | tr a-z A-Z
+# a redirect replaces the pipe and the next stage reads nothing
+printf 'to file\n' > mid.txt | wc -c > count.txt
+cat mid.txt count.txt
+echo from builtin > mid.txt | wc -c > count.txt
+cat mid.txt count.txt
+printf 'to stderr\n' >&2 | wc -c > count.txt
+cat count.txt
+printf 'ignored\n' | cat < mid.txt
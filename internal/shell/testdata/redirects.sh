@@ -0,0 +1,9 @@
+echo one > out.txt
+echo two >> out.txt
+cat < out.txt
+cat missing.txt > err.txt 2>&1 || echo "cat failed"
+cat err.txt
+cat missing.txt 2> /dev/null || echo "stderr discarded"
+echo both &> both.txt
+cat both.txt
+echo to stderr >&2
@@ -0,0 +1,15 @@
+NAME=world
+echo "hello $NAME"
+echo '$NAME stays' "\$NAME" \$NAME
+export GREETING=hi
+sh -c 'echo $GREETING from child'
+FOO=bar sh -c 'echo FOO is $FOO'
+echo "FOO=${FOO:-unset}"
+echo ${NAME^^} ${NAME:0:3} ${#NAME}
+unset NAME
+echo "NAME=${NAME-gone}"
+EMPTY=
+echo "[${EMPTY}]"
+COUNT=$(echo a b c | wc -w | tr -d ' ')
+echo "words: $COUNT"
+echo "root: $(cd / && pwd)"
@@ -12,8 +12,7 @@ import (
 
 func init() {
 	exec.Register("bash", &exec.Executable{
-		Name:     "bash",
-		Variable: "XTASK_BASH_EXE",
+		Name: "bash",
 		Linux: []string{
 			"/bin/bash",
 			"/usr/bin/bash",
@@ -21,8 +20,7 @@ func init() {
 	})
 
 	exec.Register("pwsh", &exec.Executable{
-		Name:     "pwsh",
-		Variable: "XTASK_PWSH_EXE",
+		Name: "pwsh",
 		Linux: []string{
 			"/usr/bin/pwsh",
 			"/usr/local/bin/pwsh",
@@ -30,8 +28,7 @@ func init() {
 	})
 
 	exec.Register("powershell", &exec.Executable{
-		Name:     "powershell",
-		Variable: "XTASK_POWERSHELL_EXE",
+		Name: "powershell",
 		Linux: []string{
 			"/usr/bin/pwsh",
 			"/usr/local/bin/pwsh",
@@ -39,8 +36,7 @@ func init() {
 	})
 
 	exec.Register("sh", &exec.Executable{
-		Name:     "sh",
-		Variable: "XTASK_SH_EXE",
+		Name: "sh",
 		Linux: []string{
 			"/bin/sh",
 			"/usr/bin/sh",
@@ -48,8 +44,7 @@ func init() {
 	})
 
 	exec.Register("deno", &exec.Executable{
-		Name:     "deno",
-		Variable: "XTASK_DENO_EXE",
+		Name: "deno",
 		Linux: []string{
 			"${HOME}/.local/bin/deno",
 			"${HOME}/.deno/bin/deno",
@@ -59,8 +54,7 @@ func init() {
 	})
 
 	exec.Register("node", &exec.Executable{
-		Name:     "node",
-		Variable: "XTASK_NODE_EXE",
+		Name: "node",
 		Linux: []string{
 			"/usr/bin/node",
 			"/usr/local/bin/node",
@@ -68,8 +62,7 @@ func init() {
 	})
 
 	exec.Register("bun", &exec.Executable{
-		Name:     "bun",
-		Variable: "XTASK_BUN_EXE",
+		Name: "bun",
 		Linux: []string{
 			"/usr/bin/bun",
 			"/usr/local/bin/bun",
@@ -77,8 +70,7 @@ func init() {
 	})
 
 	exec.Register("python", &exec.Executable{
-		Name:     "python",
-		Variable: "XTASK_PYTHON_EXE",
+		Name: "python",
 		Linux: []string{
 			"/usr/bin/python",
 			"/usr/bin/python3",
@@ -88,8 +80,7 @@ func init() {
 	})
 
 	exec.Register("ruby", &exec.Executable{
-		Name:     "ruby",
-		Variable: "XTASK_RUBY_EXE",
+		Name: "ruby",
 		Linux: []string{
 			"/usr/bin/ruby",
 			"/usr/local/bin/ruby",
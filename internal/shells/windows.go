@@ -13,8 +13,7 @@ import (
 
 func init() {
 	exec.Register("bash", &exec.Executable{
-		Name:     "bash",
-		Variable: "RUN_WIN_BASH_EXE",
+		Name: "bash",
 		Windows: []string{
 			"${ProgramFiles}\\Git\\bin\\bash.exe",
 			"%ProgramFiles(x86)%\\Git\\bin\\bash.exe",
@@ -23,8 +22,7 @@ func init() {
 	})
 
 	exec.Register("pwsh", &exec.Executable{
-		Name:     "pwsh",
-		Variable: "RUN_WIN_PWSH_EXE",
+		Name: "pwsh",
 		Windows: []string{
 			"${ProgramFiles}\\PowerShell\\7\\pwsh.exe",
 			"${ProgramFiles(x86)}\\PowerShell\\7\\pwsh.exe",
@@ -34,8 +32,7 @@ func init() {
 	})
 
 	exec.Register("powershell", &exec.Executable{
-		Name:     "powershell",
-		Variable: "RUN_WIN_POWERSHELL_EXE",
+		Name: "powershell",
 		Windows: []string{
 			"${SystemRoot}\\System32\\WindowsPowerShell\\v1.0\\powershell.exe",
 			"${SystemRoot}\\SysWOW64\\WindowsPowerShell\\v1.0\\powershell.exe",
@@ -43,8 +40,7 @@ func init() {
 	})
 
 	exec.Register("sh", &exec.Executable{
-		Name:     "sh",
-		Variable: "RUN_WIN_SH_EXE",
+		Name: "sh",
 		Windows: []string{
 			"${ProgramFiles}\\Git\\bin\\sh.exe",
 			"%ProgramFiles(x86)%\\Git\\bin\\sh.exe",
@@ -52,8 +48,7 @@ func init() {
 	})
 
 	exec.Register("deno", &exec.Executable{
-		Name:     "deno",
-		Variable: "RUN_WIN_DENO_EXE",
+		Name: "deno",
 		Windows: []string{
 			"${USERPROFILE}\\.deno\\bin\\deno.exe",
 			"${LOCALAPPDATA}\\Programs\\bin\\deno.exe",
@@ -63,8 +58,7 @@ func init() {
 	})
 
 	exec.Register("node", &exec.Executable{
-		Name:     "node",
-		Variable: "RUN_WIN_NODE_EXE",
+		Name: "node",
 		Windows: []string{
 			"${ProgramFiles}\\nodejs\\node.exe",
 			"${ProgramFiles(x86)}\\nodejs\\node.exe",
@@ -74,8 +68,7 @@ func init() {
 	})
 
 	exec.Register("bun", &exec.Executable{
-		Name:     "bun",
-		Variable: "RUN_WIN_BUN_EXE",
+		Name: "bun",
 		Windows: []string{
 			"${USERPROFILE}\\.bun\\bin\\bun.exe",
 			"${LOCALAPPDATA}\\Programs\\bin\\bun.exe",
@@ -86,8 +79,7 @@ func init() {
 	})
 
 	exec.Register("python", &exec.Executable{
-		Name:     "python",
-		Variable: "RUN_WIN_PYTHON_EXE",
+		Name: "python",
 		Windows: []string{
 			"${ProgramFiles}\\Python\\Python.exe",
 			"${ProgramFiles(x86)}\\Python\\Python.exe",
@@ -95,8 +87,7 @@ func init() {
 	})
 
 	exec.Register("ruby", &exec.Executable{
-		Name:     "ruby",
-		Variable: "RUN_WIN_RUBY_EXE",
+		Name: "ruby",
 		Windows: []string{
 			"${ProgramFiles}\\Ruby\\bin\\ruby.exe",
 			"${ProgramFiles(x86)}\\Ruby\\bin\\ruby.exe",
@@ -0,0 +1,160 @@
+package watch
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyprxlabs/run/internal/globs"
+)
+
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Ignore decides which paths below a root directory are ignored,
+// using the subset of .gitignore syntax that matters for watching:
+// comments, negation with "!", directory only patterns with a
+// trailing "/", anchored patterns and "**".
+type Ignore struct {
+	root  string
+	rules []ignoreRule
+}
+
+// NewIgnore creates an Ignore for root from the given patterns.
+func NewIgnore(root string, patterns ...string) *Ignore {
+	ig := &Ignore{root: root}
+	ig.Add(patterns...)
+	return ig
+}
+
+// ReadGitignore creates an Ignore for root from root/.gitignore.
+// A missing .gitignore yields an Ignore without any rules.
+func ReadGitignore(root string) (*Ignore, error) {
+	ig := NewIgnore(root)
+	f, err := os.Open(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ig, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ig.Add(scanner.Text())
+	}
+
+	return ig, scanner.Err()
+}
+
+// Clone returns a copy of the Ignore, so rules added to the copy
+// leave the original unchanged.
+func (ig *Ignore) Clone() *Ignore {
+	if ig == nil {
+		return nil
+	}
+
+	return &Ignore{root: ig.root, rules: append([]ignoreRule(nil), ig.rules...)}
+}
+
+// Add appends patterns to the rules. Later patterns take precedence.
+func (ig *Ignore) Add(patterns ...string) {
+	for _, line := range patterns {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		rule.pattern = line
+		ig.rules = append(ig.rules, rule)
+	}
+}
+
+// Match reports whether the path is ignored. The path may be
+// absolute or relative to the root.
+func (ig *Ignore) Match(path string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+
+	rel := path
+	if filepath.IsAbs(path) {
+		r, err := filepath.Rel(ig.root, path)
+		if err != nil || strings.HasPrefix(r, "..") {
+			return false
+		}
+		rel = r
+	}
+
+	rel = filepath.ToSlash(rel)
+	segments := strings.Split(rel, "/")
+
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.negate == !ignored {
+			// the rule cannot change the outcome.
+			continue
+		}
+
+		if ruleMatches(rule, segments, isDir) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+func ruleMatches(rule ignoreRule, segments []string, isDir bool) bool {
+	// a rule matches the path itself or any of its parent
+	// directories, since everything below an ignored directory
+	// is ignored too.
+	for i := len(segments); i > 0; i-- {
+		prefixIsDir := isDir || i < len(segments)
+		if rule.dirOnly && !prefixIsDir {
+			continue
+		}
+
+		if rule.anchored {
+			if globs.Match(rule.pattern, strings.Join(segments[:i], "/")) {
+				return true
+			}
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			if globs.Match(rule.pattern, strings.Join(segments[j:i], "/")) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
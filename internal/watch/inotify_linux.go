@@ -0,0 +1,158 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY |
+	unix.IN_DELETE | unix.IN_DELETE_SELF | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_ATTRIB
+
+type inotify struct {
+	w    *Watcher
+	file *os.File
+	fd   int
+
+	mu    sync.Mutex
+	paths map[int]string
+	wds   map[string]int
+}
+
+func newBackend(w *Watcher) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	// a non-blocking descriptor is handled by the runtime poller,
+	// which lets close interrupt a pending read.
+	in := &inotify{
+		w:     w,
+		file:  os.NewFile(uintptr(fd), "inotify"),
+		fd:    fd,
+		paths: map[int]string{},
+		wds:   map[string]int{},
+	}
+
+	go in.read()
+	return in, nil
+}
+
+func (in *inotify) add(dir string) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if _, ok := in.wds[dir]; ok {
+		return nil
+	}
+
+	wd, err := unix.InotifyAddWatch(in.fd, dir, inotifyMask)
+	if err != nil {
+		if err == unix.ENOENT {
+			return nil
+		}
+		return os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	in.paths[wd] = dir
+	in.wds[dir] = wd
+	return nil
+}
+
+func (in *inotify) remove(wd int) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	dir, ok := in.paths[wd]
+	if !ok {
+		return
+	}
+
+	delete(in.paths, wd)
+	delete(in.wds, dir)
+}
+
+func (in *inotify) close() error {
+	return in.file.Close()
+}
+
+func (in *inotify) read() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := in.file.Read(buf)
+		if err != nil {
+			select {
+			case <-in.w.done:
+			default:
+				in.w.error(err)
+			}
+			return
+		}
+
+		offset := 0
+		for offset+unix.SizeofInotifyEvent <= n {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameLen := int(event.Len)
+			name := ""
+			if nameLen > 0 {
+				bytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+nameLen]
+				end := 0
+				for end < len(bytes) && bytes[end] != 0 {
+					end++
+				}
+				name = string(bytes[:end])
+			}
+			offset += unix.SizeofInotifyEvent + nameLen
+
+			in.handle(int(event.Wd), event.Mask, name)
+		}
+	}
+}
+
+func (in *inotify) handle(wd int, mask uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// events were lost, report every root as changed.
+		for _, root := range in.w.roots {
+			in.w.notify(root, true)
+		}
+		return
+	}
+
+	if mask&unix.IN_IGNORED != 0 {
+		in.remove(wd)
+		return
+	}
+
+	in.mu.Lock()
+	dir, ok := in.paths[wd]
+	in.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+
+	isDir := mask&unix.IN_ISDIR != 0
+	if isDir && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+		// files created before the watch is added are reported
+		// by walking the new directory.
+		if !in.w.ignored(path, true) {
+			if err := in.w.addTree(path); err != nil {
+				in.w.error(err)
+			}
+			in.w.notifyTree(path)
+		}
+	}
+
+	in.w.notify(path, isDir)
+}
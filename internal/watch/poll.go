@@ -0,0 +1,150 @@
+//go:build !linux
+
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is how often the polling backend scans the
+// watched directories.
+const DefaultPollInterval = 500 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// poller is used on platforms without an inotify backend. It
+// rescans the watched directories and compares modification times
+// and sizes.
+type poller struct {
+	w     *Watcher
+	mu    sync.Mutex
+	dirs  map[string]bool
+	files map[string]fileState
+	stop  chan struct{}
+}
+
+func newBackend(w *Watcher) (backend, error) {
+	p := &poller{
+		w:     w,
+		dirs:  map[string]bool{},
+		files: map[string]fileState{},
+		stop:  make(chan struct{}),
+	}
+
+	interval := w.options.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	go p.loop(interval)
+	return p, nil
+}
+
+func (p *poller) add(dir string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dirs[dir] {
+		return nil
+	}
+
+	p.dirs[dir] = true
+	entries, err := readFiles(dir)
+	if err != nil {
+		return nil
+	}
+
+	for path, state := range entries {
+		p.files[path] = state
+	}
+
+	return nil
+}
+
+func (p *poller) close() error {
+	close(p.stop)
+	return nil
+}
+
+func (p *poller) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.scan()
+		}
+	}
+}
+
+func (p *poller) scan() {
+	current := map[string]fileState{}
+	for _, root := range p.w.roots {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if d.IsDir() {
+				if path != root && p.w.ignored(path, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			current[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+
+	p.mu.Lock()
+	previous := p.files
+	p.files = current
+	p.mu.Unlock()
+
+	for path, state := range current {
+		prev, ok := previous[path]
+		if !ok || !prev.modTime.Equal(state.modTime) || prev.size != state.size {
+			p.w.notify(path, false)
+		}
+	}
+
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			p.w.notify(path, false)
+		}
+	}
+}
+
+func readFiles(dir string) (map[string]fileState, error) {
+	entries, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]fileState{}
+	for _, path := range entries {
+		info, err := os.Lstat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,210 @@
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDebounce is the quiet period used to coalesce bursts of
+// file changes into a single batch.
+const DefaultDebounce = 200 * time.Millisecond
+
+type Options struct {
+	// Debounce is the quiet period after the last change before
+	// a batch of changes is delivered.
+	Debounce time.Duration
+
+	// Ignore filters out paths below the watched roots.
+	Ignore *Ignore
+
+	// PollInterval is how often the polling backend scans the
+	// roots. It is not used by the inotify backend.
+	PollInterval time.Duration
+}
+
+// Watcher watches directory trees and delivers debounced batches
+// of changed paths on Changes.
+type Watcher struct {
+	Changes chan []string
+	Errors  chan error
+
+	roots   []string
+	options Options
+	backend backend
+
+	mu      sync.Mutex
+	pending map[string]bool
+	timer   *time.Timer
+	done    chan struct{}
+	closed  bool
+}
+
+// backend is implemented by the platform specific file watchers.
+type backend interface {
+	add(dir string) error
+	close() error
+}
+
+// New starts watching the roots and everything below them.
+func New(roots []string, options *Options) (*Watcher, error) {
+	// the defaults are set on a copy, the caller's options are left
+	// unchanged.
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+
+	w := &Watcher{
+		Changes: make(chan []string, 1),
+		Errors:  make(chan error, 1),
+		options: opts,
+		pending: map[string]bool{},
+		done:    make(chan struct{}),
+	}
+
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		w.roots = append(w.roots, abs)
+	}
+
+	b, err := newBackend(w)
+	if err != nil {
+		return nil, err
+	}
+	w.backend = b
+
+	for _, root := range w.roots {
+		if err := w.addTree(root); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// Close stops the watcher. Pending changes are discarded.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	close(w.done)
+	w.mu.Unlock()
+
+	if w.backend != nil {
+		return w.backend.close()
+	}
+
+	return nil
+}
+
+// ignored reports whether the path should not trigger changes.
+func (w *Watcher) ignored(path string, isDir bool) bool {
+	if filepath.Base(path) == ".git" {
+		return true
+	}
+
+	return w.options.Ignore.Match(path, isDir)
+}
+
+// addTree adds dir and every directory below it that is not ignored.
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// directories can disappear while they are walked.
+			return nil
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != dir && w.ignored(path, true) {
+			return filepath.SkipDir
+		}
+
+		return w.backend.add(path)
+	})
+}
+
+// notifyTree records every file below dir as changed.
+func (w *Watcher) notifyTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if path != dir && w.ignored(path, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		w.notify(path, false)
+		return nil
+	})
+}
+
+// notify records a changed path and restarts the debounce timer.
+func (w *Watcher) notify(path string, isDir bool) {
+	if w.ignored(path, isDir) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	w.pending[path] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.options.Debounce, w.flush)
+}
+
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	if w.closed || len(w.pending) == 0 {
+		w.mu.Unlock()
+		return
+	}
+
+	batch := make([]string, 0, len(w.pending))
+	for p := range w.pending {
+		batch = append(batch, p)
+	}
+	w.pending = map[string]bool{}
+	w.mu.Unlock()
+
+	sort.Strings(batch)
+	select {
+	case w.Changes <- batch:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) error(err error) {
+	select {
+	case w.Errors <- err:
+	default:
+	}
+}
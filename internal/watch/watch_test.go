@@ -0,0 +1,137 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyprxlabs/run/internal/watch"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWatcher(t *testing.T, dir string, ignore *watch.Ignore) *watch.Watcher {
+	t.Helper()
+	w, err := watch.New([]string{dir}, &watch.Options{
+		Debounce:     50 * time.Millisecond,
+		PollInterval: 20 * time.Millisecond,
+		Ignore:       ignore,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func waitBatch(t *testing.T, w *watch.Watcher) []string {
+	t.Helper()
+	select {
+	case batch := <-w.Changes:
+		return batch
+	case err := <-w.Errors:
+		t.Fatalf("watcher error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+
+	return nil
+}
+
+func expectNoBatch(t *testing.T, w *watch.Watcher) {
+	t.Helper()
+	select {
+	case batch := <-w.Changes:
+		t.Fatalf("unexpected changes: %v", batch)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func write(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew_KeepsOptions(t *testing.T) {
+	options := &watch.Options{PollInterval: 20 * time.Millisecond}
+	w, err := watch.New([]string{t.TempDir()}, options)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	assert.Equal(t, watch.Options{PollInterval: 20 * time.Millisecond}, *options)
+}
+
+func TestWatcher_DebouncesBurst(t *testing.T) {
+	dir := t.TempDir()
+	w := newWatcher(t, dir, nil)
+
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	write(t, a, "1")
+	write(t, a, "2")
+	write(t, b, "1")
+
+	batch := waitBatch(t, w)
+	assert.Contains(t, batch, a)
+	assert.Contains(t, batch, b)
+	expectNoBatch(t, w)
+}
+
+func TestWatcher_NewDirectories(t *testing.T) {
+	dir := t.TempDir()
+	w := newWatcher(t, dir, nil)
+
+	nested := filepath.Join(dir, "sub", "deep", "c.txt")
+	write(t, nested, "1")
+
+	batch := waitBatch(t, w)
+	assert.Contains(t, batch, nested)
+
+	// the new directory is watched as well.
+	write(t, nested, "2")
+	batch = waitBatch(t, w)
+	assert.Contains(t, batch, nested)
+}
+
+func TestWatcher_Gitignore(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, ".gitignore"), "*.log\nbuild/\n!keep.log\n")
+
+	ignore, err := watch.ReadGitignore(dir)
+	assert.NoError(t, err)
+	w := newWatcher(t, dir, ignore)
+
+	write(t, filepath.Join(dir, "debug.log"), "1")
+	write(t, filepath.Join(dir, "build", "out.bin"), "1")
+	expectNoBatch(t, w)
+
+	keep := filepath.Join(dir, "keep.log")
+	write(t, keep, "1")
+	batch := waitBatch(t, w)
+	assert.Equal(t, []string{keep}, batch)
+}
+
+func TestIgnore_Match(t *testing.T) {
+	ig := watch.NewIgnore("/repo", "*.log", "/dist", "build/", "docs/**/*.tmp", "!important.log")
+
+	assert.True(t, ig.Match("/repo/a.log", false))
+	assert.True(t, ig.Match("sub/a.log", false))
+	assert.False(t, ig.Match("important.log", false))
+	assert.True(t, ig.Match("dist/app.js", false))
+	assert.False(t, ig.Match("src/dist/app.js", false))
+	assert.True(t, ig.Match("build", true))
+	assert.False(t, ig.Match("build", false))
+	assert.True(t, ig.Match("src/build/x.o", false))
+	assert.True(t, ig.Match("docs/a/b/c.tmp", false))
+	assert.False(t, ig.Match("main.go", false))
+	assert.False(t, ig.Match("/elsewhere/a.go", false))
+}